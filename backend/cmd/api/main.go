@@ -3,21 +3,30 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
+	// Embedded zoneinfo for digest schedules; the runtime image has none
+	_ "time/tzdata"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/apidoc"
 	"github.com/yourusername/todogo-backend/internal/config"
 	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/graph"
+	"github.com/yourusername/todogo-backend/internal/grpcserver"
 	"github.com/yourusername/todogo-backend/internal/handler"
+	"github.com/yourusername/todogo-backend/internal/mailer"
 	custommw "github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
 	"github.com/yourusername/todogo-backend/internal/repository"
 	"github.com/yourusername/todogo-backend/internal/service"
 )
@@ -43,16 +52,101 @@ func main() {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	todoRepo := repository.NewTodoRepository(db)
+	feedRepo := repository.NewFeedRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	statsRepo := repository.NewStatsRepository(db)
+	digestRepo := repository.NewDigestRepository(db)
+	viewRepo := repository.NewViewRepository(db)
+	archiveRepo := repository.NewArchiveRepository(db)
+	undoRepo := repository.NewUndoRepository(db)
+	inboxRepo := repository.NewInboxRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	revokedTokenRepo := repository.NewRevokedTokenRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	emailVerificationRepo := repository.NewEmailVerificationRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	oidcRepo := repository.NewOIDCRepository(db)
+
+	// Outgoing email
+	smtpMailer, err := mailer.NewSMTPMailer(cfg.SMTP)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to configure mailer")
+	}
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, cfg.JWT.Secret, cfg.JWT.Expiration)
-	todoService := service.NewTodoService(todoRepo)
+	policyService := service.NewPolicyService(userRepo, auditRepo, cfg.RBAC)
+	if err := policyService.Bootstrap(context.Background()); err != nil {
+		log.Fatal().Err(err).Msg("Failed to assign admin roles")
+	}
+	sessionCache := service.NewSessionCache(userRepo, revokedTokenRepo, cfg.JWT.SessionCacheTTL)
+	emailVerificationService := service.NewEmailVerificationService(userRepo, emailVerificationRepo, sessionCache, policyService, smtpMailer, cfg.Auth, cfg.Server.AppURL)
+	mfaService := service.NewMFAService(userRepo, mfaRepo, policyService, cfg.Auth)
+	authService := service.NewAuthService(userRepo, refreshTokenRepo, sessionCache, emailVerificationService, mfaService, policyService, cfg.JWT)
+	oidcService := service.NewOIDCService(oidcRepo, userRepo, authService, emailVerificationService, policyService, cfg.OIDC)
+	todoService := service.NewTodoService(todoRepo, userRepo, workspaceRepo, policyService)
+	calendarService := service.NewCalendarService(todoService)
+	feedService := service.NewFeedService(feedRepo, todoRepo, userRepo, workspaceRepo, cfg.Server.PublicURL)
+	webhookService := service.NewWebhookService(webhookRepo, cfg.Webhook)
+	eventService := service.NewEventService(eventRepo)
+	syncService := service.NewSyncService(todoRepo, todoService)
+	statsService := service.NewStatsService(statsRepo)
+	viewService := service.NewViewService(viewRepo, todoRepo)
+	archiveService := service.NewArchiveService(archiveRepo, todoService, cfg.Archive)
+	undoService := service.NewUndoService(undoRepo, todoService, cfg.Undo)
+	inboxService := service.NewInboxService(inboxRepo)
+	adminService := service.NewAdminService(userRepo, sessionCache, policyService)
+	workspaceService := service.NewWorkspaceService(workspaceRepo, userRepo, smtpMailer, cfg.Server.AppURL)
+	digestService := service.NewDigestService(digestRepo, todoRepo, userRepo, smtpMailer, cfg.Digest, cfg.Server.AppURL)
+	passwordResetService := service.NewPasswordResetService(userRepo, passwordResetRepo, authService, smtpMailer, cfg.Auth, cfg.Server.AppURL)
+	graphServer, err := graph.NewServer(todoService, authService, eventService)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize GraphQL")
+	}
+	grpcServer := grpcserver.NewServer(todoService, authService, eventService, workspaceService)
+	todoService.AddListener(webhookService)
+	todoService.AddListener(eventService)
+	todoService.AddListener(inboxService)
+
+	// Background workers stop when the server shuts down
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go webhookService.Run(workerCtx)
+	go eventService.Run(workerCtx)
+	if cfg.Digest.Enabled {
+		go digestService.Run(workerCtx)
+	}
+	if cfg.Archive.Enabled {
+		go archiveService.Run(workerCtx)
+	}
 
 	// Initialize handlers
-	authHandler := handler.NewAuthHandler(authService)
-	todoHandler := handler.NewTodoHandler(todoService)
+	authHandler := handler.NewAuthHandler(authService, passwordResetService, emailVerificationService)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+	oidcHandler := handler.NewOIDCHandler(oidcService, strings.HasPrefix(cfg.Server.PublicURL, "https://"))
+	todoHandler := handler.NewTodoHandler(todoService, undoService)
+	calendarHandler := handler.NewCalendarHandler(calendarService, undoService)
+	feedHandler := handler.NewFeedHandler(feedService)
+	caldavHandler := handler.NewCalDAVHandler(todoService, calendarService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	eventHandler := handler.NewEventHandler(eventService)
+	syncHandler := handler.NewSyncHandler(syncService)
+	statsHandler := handler.NewStatsHandler(statsService)
+	digestHandler := handler.NewDigestHandler(digestService)
+	viewHandler := handler.NewViewHandler(viewService)
+	archiveHandler := handler.NewArchiveHandler(archiveService)
+	undoHandler := handler.NewUndoHandler(undoService)
+	inboxHandler := handler.NewInboxHandler(inboxService)
+	workspaceHandler := handler.NewWorkspaceHandler(workspaceService, authService)
+	roleHandler := handler.NewRoleHandler(policyService)
+	adminHandler := handler.NewAdminHandler(adminService)
+	graphqlHandler := handler.NewGraphQLHandler(graphServer, cfg.CORS.AllowedOrigins)
 
 	// Setup router
+	chi.RegisterMethod("PROPFIND")
+	chi.RegisterMethod("REPORT")
 	r := chi.NewRouter()
 
 	// Middleware
@@ -66,17 +160,17 @@ func main() {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   cfg.CORS.AllowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Last-Event-ID", custommw.WorkspaceHeader},
+		ExposedHeaders:   []string{"Link", "X-Undo-Token", "X-Undo-Expires"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
-	// Root handler - API documentation
+	// Root handler - points to the API documentation
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"message":"Todogo API Server","version":"1.0.0","status":"running","endpoints":{"/health":"GET - Health check","/api/v1/auth/register":"POST - Register user","/api/v1/auth/login":"POST - Login user","/api/v1/todos":"GET/POST - Todo operations (requires auth)"}}`))
+		w.Write([]byte(`{"message":"Todogo API Server","version":"` + apidoc.Version + `","status":"running","docs":"` + handler.DocsPath + `","openapi":"/openapi.json"}`))
 	})
 
 	// Health check
@@ -85,33 +179,204 @@ func main() {
 		w.Write([]byte("OK"))
 	})
 
+	// CalDAV for native task apps, authenticated with HTTP Basic credentials
+	r.Get("/.well-known/caldav", caldavHandler.WellKnown)
+	r.Route(handler.CalDAVPrefix, func(r chi.Router) {
+		r.Use(custommw.BasicAuthMiddleware(authService, "Todogo CalDAV"), custommw.WorkspaceMiddleware(workspaceService))
+		r.Handle("/*", caldavHandler)
+	})
+
+	// GraphQL over HTTP and WebSocket; browsers pass the token in the query
+	// when opening the WebSocket
+	r.Group(func(r chi.Router) {
+		r.Use(custommw.TokenFromQuery, custommw.AuthMiddleware(authService), custommw.WorkspaceMiddleware(workspaceService))
+		r.Get("/graphql", graphqlHandler.ServeHTTP)
+		r.Post("/graphql", graphqlHandler.ServeHTTP)
+	})
+
 	// API Routes
 	r.Route("/api/v1", func(r chi.Router) {
 		// Public routes
 		r.Post("/auth/register", authHandler.Register)
 		r.Post("/auth/login", authHandler.Login)
+		r.Post("/auth/password", authHandler.ChangePassword)
+		r.Post("/auth/refresh", authHandler.Refresh)
+		r.Post("/auth/forgot-password", authHandler.ForgotPassword)
+		r.Post("/auth/reset-password", authHandler.ResetPassword)
+		r.Post("/auth/verify-email", authHandler.VerifyEmail)
+		r.Post("/auth/verify-email/resend", authHandler.ResendVerification)
+		r.Post("/auth/mfa/verify", authHandler.VerifyMFA)
+		r.Post("/auth/oidc/authorize", oidcHandler.Authorize)
+		r.Post("/auth/oidc/callback", oidcHandler.Callback)
+
+		// Calendar subscriptions authenticate with the secret token in the URL
+		r.Get("/feeds/ical/{token}.ics", feedHandler.Serve)
+
+		// Live todo events; browsers' EventSource passes the token in the query
+		r.With(custommw.TokenFromQuery, custommw.AuthMiddleware(authService), custommw.WorkspaceMiddleware(workspaceService)).Get("/events", eventHandler.Stream)
 
 		// Protected routes
 		r.Group(func(r chi.Router) {
 			r.Use(custommw.AuthMiddleware(authService))
 
-			// Todo routes
-			r.Route("/todos", func(r chi.Router) {
-				r.Get("/", todoHandler.GetAll)
-				r.Post("/", todoHandler.Create)
-				r.Get("/{id}", todoHandler.GetByID)
-				r.Put("/{id}", todoHandler.Update)
-				r.Delete("/{id}", todoHandler.Delete)
-				r.Patch("/{id}/complete", todoHandler.MarkAsCompleted)
-				r.Patch("/{id}/incomplete", todoHandler.MarkAsIncomplete)
+			r.Post("/auth/logout", authHandler.Logout)
+			r.Post("/auth/logout/all", authHandler.LogoutEverywhere)
+			r.Put("/auth/email", authHandler.ChangeEmail)
+			r.Route("/auth/mfa", func(r chi.Router) {
+				r.Post("/totp", mfaHandler.Enroll)
+				r.Post("/totp/confirm", mfaHandler.Confirm)
+				r.Post("/totp/disable", mfaHandler.Disable)
+				r.Post("/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+			})
+
+			// Workspaces work without a resolved workspace, so that a token
+			// for a workspace the user has left can still switch away from it
+			r.Route("/workspaces", func(r chi.Router) {
+				r.Get("/", workspaceHandler.GetAll)
+				r.With(custommw.RequirePermission(policyService, models.PermWorkspaceManage)).Post("/", workspaceHandler.Create)
+				r.Get("/{id}", workspaceHandler.GetByID)
+				r.Put("/{id}", workspaceHandler.Update)
+				r.Delete("/{id}", workspaceHandler.Delete)
+				r.Post("/{id}/switch", workspaceHandler.Switch)
+				r.Get("/{id}/members", workspaceHandler.Members)
+				r.Delete("/{id}/members/{userID}", workspaceHandler.RemoveMember)
+				r.Get("/{id}/invitations", workspaceHandler.Invitations)
+				r.With(custommw.RequirePermission(policyService, models.PermWorkspaceManage)).Post("/{id}/invitations", workspaceHandler.Invite)
+				r.Delete("/{id}/invitations/{invitationID}", workspaceHandler.RevokeInvitation)
+			})
+			r.Post("/invitations/{token}/accept", workspaceHandler.Accept)
+
+			// Roles and their permissions; managing users and reading the
+			// audit log is for admins
+			r.Get("/roles", roleHandler.GetAll)
+			r.Route("/admin", func(r chi.Router) {
+				r.Route("/users", func(r chi.Router) {
+					r.Use(custommw.RequirePermission(policyService, models.PermUserManage))
+					r.Get("/", adminHandler.ListUsers)
+					r.Get("/{id}", adminHandler.GetUser)
+					r.Delete("/{id}", adminHandler.DeleteUser)
+					r.Put("/{id}/role", roleHandler.SetRole)
+					r.Post("/{id}/disable", adminHandler.DisableUser)
+					r.Post("/{id}/enable", adminHandler.EnableUser)
+					r.Post("/{id}/password-reset", adminHandler.ForcePasswordReset)
+				})
+				r.With(custommw.RequirePermission(policyService, models.PermAuditRead)).Get("/audit", roleHandler.Audit)
+			})
+
+			// Email digest of due and overdue todos, across all workspaces
+			r.Route("/digest", func(r chi.Router) {
+				r.Get("/", digestHandler.Get)
+				r.Put("/", digestHandler.Update)
+				r.Delete("/", digestHandler.Delete)
+				r.Post("/send", digestHandler.Send)
+			})
+
+			// Everything else works in the workspace from X-Workspace-ID or
+			// the token
+			r.Group(func(r chi.Router) {
+				r.Use(custommw.WorkspaceMiddleware(workspaceService))
+
+				// Todo routes
+				r.Route("/todos", func(r chi.Router) {
+					r.Get("/", todoHandler.GetAll)
+					r.Post("/", todoHandler.Create)
+					r.Get("/export.ics", calendarHandler.Export)
+					r.Post("/import", calendarHandler.Import)
+					r.Get("/{id}", todoHandler.GetByID)
+					r.Put("/{id}", todoHandler.Update)
+					r.Delete("/{id}", todoHandler.Delete)
+					r.Patch("/{id}/complete", todoHandler.MarkAsCompleted)
+					r.Patch("/{id}/incomplete", todoHandler.MarkAsIncomplete)
+					r.Patch("/{id}/archive", todoHandler.Archive)
+					r.Patch("/{id}/unarchive", todoHandler.Unarchive)
+					r.Post("/{id}/duplicate", todoHandler.Duplicate)
+					r.Put("/{id}/assignee", todoHandler.Assign)
+				})
+
+				// Saved views
+				r.Route("/views", func(r chi.Router) {
+					r.Get("/", viewHandler.GetAll)
+					r.Post("/", viewHandler.Create)
+					r.Get("/{id}", viewHandler.GetByID)
+					r.Put("/{id}", viewHandler.Update)
+					r.Delete("/{id}", viewHandler.Delete)
+					r.Get("/{id}/todos", viewHandler.Todos)
+				})
+
+				// Assignment notifications
+				r.Route("/inbox", func(r chi.Router) {
+					r.Get("/", inboxHandler.GetAll)
+					r.Post("/read", inboxHandler.MarkAllRead)
+					r.Post("/{id}/read", inboxHandler.MarkRead)
+				})
+
+				// Reverts the change behind a token from X-Undo-Token
+				r.Post("/undo/{token}", undoHandler.Undo)
+
+				// Rule that archives old completed todos
+				r.Route("/archive-rule", func(r chi.Router) {
+					r.Get("/", archiveHandler.GetRule)
+					r.Put("/", archiveHandler.UpdateRule)
+					r.Delete("/", archiveHandler.DeleteRule)
+				})
+
+				// Calendar feed routes
+				r.Route("/feeds", func(r chi.Router) {
+					r.Use(custommw.RequirePermission(policyService, models.PermFeedManage))
+					r.Get("/", feedHandler.GetAll)
+					r.Post("/", feedHandler.Create)
+					r.Post("/{id}/regenerate", feedHandler.Regenerate)
+					r.Delete("/{id}", feedHandler.Revoke)
+				})
+
+				// Webhook routes
+				r.Route("/webhooks", func(r chi.Router) {
+					r.Use(custommw.RequirePermission(policyService, models.PermWebhookManage))
+					r.Get("/", webhookHandler.GetAll)
+					r.Post("/", webhookHandler.Create)
+					r.Get("/{id}", webhookHandler.GetByID)
+					r.Patch("/{id}", webhookHandler.Update)
+					r.Delete("/{id}", webhookHandler.Delete)
+					r.Post("/{id}/ping", webhookHandler.Ping)
+					r.Get("/{id}/deliveries", webhookHandler.Deliveries)
+					r.Post("/{id}/deliveries/{deliveryID}/replay", webhookHandler.Replay)
+				})
+
+				// Delta sync for offline clients
+				r.Get("/sync", syncHandler.Pull)
+				r.Post("/sync", syncHandler.Push)
+
+				// Productivity statistics
+				r.Get("/stats", statsHandler.Get)
 			})
 		})
 	})
 
+	// OpenAPI document generated from the routes above, with Swagger UI
+	spec, err := apidoc.Build(r, cfg.Server.PublicURL)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to build OpenAPI document")
+	}
+	docsHandler, err := handler.NewDocsHandler(spec)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize API docs")
+	}
+	r.Get("/openapi.json", docsHandler.Spec)
+	r.Get(handler.DocsPath+"*", docsHandler.SwaggerUI)
+	r.Get(strings.TrimSuffix(handler.DocsPath, "/"), func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, handler.DocsPath, http.StatusMovedPermanently)
+	})
+
+	var rootHandler http.Handler = r
+	if cfg.Server.ValidateRequests {
+		rootHandler = custommw.ValidateRequests(spec)(r)
+		log.Info().Msg("Validating requests against the OpenAPI document")
+	}
+
 	// Start server
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%s", cfg.Server.Port),
-		Handler:      r,
+		Handler:      rootHandler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -124,6 +389,8 @@ func main() {
 		<-sigint
 
 		log.Info().Msg("Shutting down server...")
+		stopWorkers()
+		grpcServer.GracefulStop()
 
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
@@ -133,6 +400,18 @@ func main() {
 		}
 	}()
 
+	// gRPC API for internal consumers on its own port
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.Server.GRPCPort))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to listen for gRPC")
+	}
+	go func() {
+		log.Info().Str("port", cfg.Server.GRPCPort).Msg("Starting gRPC server")
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatal().Err(err).Msg("gRPC server failed")
+		}
+	}()
+
 	log.Info().Str("port", cfg.Server.Port).Msg("Starting server")
 	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatal().Err(err).Msg("Server failed to start")
@@ -0,0 +1,281 @@
+// Package apidoc describes the HTTP API as an OpenAPI document. Paths and
+// methods come from the router, schemas from the models types, and the rest
+// from the operations table.
+package apidoc
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/openapi"
+)
+
+const (
+	Title   = "Todogo API"
+	Version = "1.0.0"
+
+	bearerAuth = "bearerAuth"
+)
+
+// operation documents one route. Unless raw is set, responses use the
+// standard JSON envelope with data as the payload.
+type operation struct {
+	tag     string
+	summary string
+	public  bool
+	// unscoped operations do not run in a workspace and take no
+	// X-Workspace-ID header.
+	unscoped bool
+	params   []*openapi.Parameter
+	body     interface{}
+	status   int
+	data     interface{}
+	errors   []int
+
+	// requestContent and responseContent replace the JSON request body and
+	// success response for routes that speak other formats.
+	requestContent  map[string]*openapi.MediaType
+	responseContent map[string]*openapi.MediaType
+}
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Build documents every route of the router. It fails when a route has no
+// entry in the operations table or an entry no longer matches a route, so
+// the document cannot silently drift from the code.
+func Build(routes chi.Routes, serverURL string) (*openapi.Document, error) {
+	gen := openapi.NewGenerator()
+	gen.Enum(models.StatusPending, models.StatusCompleted)
+	gen.Enum(models.PriorityLow, models.PriorityMedium, models.PriorityHigh)
+	gen.Enum(models.DeliveryPending, models.DeliverySucceeded, models.DeliveryFailed)
+	gen.Enum(models.SyncLastWriterWins, models.SyncFieldMerge)
+	gen.Enum(models.SyncOpUpsert, models.SyncOpDelete)
+	gen.Enum(models.SyncApplied, models.SyncMerged, models.SyncConflict, models.SyncError)
+	gen.Enum(models.DigestDaily, models.DigestWeekly)
+	gen.Enum(models.SortCreatedAt, models.SortUpdatedAt, models.SortDueDate, models.SortPriority, models.SortTitle)
+	gen.Enum(models.SortAsc, models.SortDesc)
+	gen.Enum(models.GroupNone, models.GroupStatus, models.GroupPriority, models.GroupDueDate, models.GroupTag)
+	gen.Enum(models.InboxAssigned, models.InboxUnassigned)
+	gen.Enum(models.WorkspaceRoleOwner, models.WorkspaceRoleMember)
+	gen.Enum(models.RoleAdmin, models.RoleMember, models.RoleGuest)
+	gen.Enum(models.PermTodoRead, models.PermTodoCreate, models.PermTodoUpdate, models.PermTodoDelete, models.PermTodoAssign,
+		models.PermTagManage, models.PermFeedManage, models.PermWebhookManage, models.PermWorkspaceManage,
+		models.PermUserManage, models.PermAuditRead)
+	gen.Enum(models.AuditAccessDenied, models.AuditRoleChanged, models.AuditUserDisabled, models.AuditUserEnabled,
+		models.AuditPasswordReset, models.AuditUserDeleted, models.AuditTokenReused,
+		models.AuditMFAEnabled, models.AuditMFADisabled, models.AuditSSOLinked)
+	gen.Enum(service.EventTodoCreated, service.EventTodoUpdated, service.EventTodoCompleted, service.EventTodoDeleted, service.EventTodoAssigned)
+
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       Title,
+			Description: "REST API of the Todogo todo manager.",
+			Version:     Version,
+		},
+		Servers: []openapi.Server{{URL: serverURL}},
+		Paths:   make(map[string]*openapi.PathItem),
+		Components: openapi.Components{
+			SecuritySchemes: map[string]*openapi.SecurityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	seen := make(map[string]bool)
+	var missing []string
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if strings.Contains(route, "*") || !isOpenAPIMethod(method) {
+			// Wildcard mounts such as CalDAV speak their own protocol.
+			return nil
+		}
+		path := route
+		if len(path) > 1 {
+			path = strings.TrimSuffix(path, "/")
+		}
+
+		key := method + " " + path
+		op, ok := operations[key]
+		if !ok {
+			missing = append(missing, key)
+			return nil
+		}
+		seen[key] = true
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &openapi.PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(method)] = op.build(gen, method, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for key := range operations {
+		if !seen[key] {
+			missing = append(missing, key+" (no such route)")
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("undocumented routes: %s", strings.Join(missing, ", "))
+	}
+
+	tags := make(map[string]bool)
+	for _, op := range operations {
+		if !tags[op.tag] {
+			tags[op.tag] = true
+			doc.Tags = append(doc.Tags, openapi.Tag{Name: op.tag})
+		}
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	doc.Components.Schemas = gen.Schemas()
+	doc.Components.Schemas["ErrorResponse"] = errorResponseSchema()
+	return doc, nil
+}
+
+func isOpenAPIMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+		http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func (o operation) build(gen *openapi.Generator, method, path string) *openapi.Operation {
+	op := &openapi.Operation{
+		OperationID: operationID(method, path),
+		Summary:     o.summary,
+		Tags:        []string{o.tag},
+		Responses:   make(map[string]*openapi.Response),
+	}
+	if !o.public {
+		op.Security = []map[string][]string{{bearerAuth: {}}}
+	}
+
+	// Path parameters listed in params replace the defaults
+	declared := map[string]bool{}
+	for _, p := range o.params {
+		if p.In == "path" {
+			declared[p.Name] = true
+		}
+	}
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		if !declared[match[1]] {
+			op.Parameters = append(op.Parameters, pathParameter(match[1]))
+		}
+	}
+	op.Parameters = append(op.Parameters, o.params...)
+	if !o.public && !o.unscoped {
+		op.Parameters = append(op.Parameters, workspaceHeaderParam)
+	}
+
+	switch {
+	case o.requestContent != nil:
+		op.RequestBody = &openapi.RequestBody{Required: true, Content: o.requestContent}
+	case o.body != nil:
+		op.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  jsonContent(gen.Schema(o.body)),
+		}
+	}
+
+	status := o.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &openapi.Response{Description: http.StatusText(status)}
+	if o.responseContent != nil {
+		success.Content = o.responseContent
+	} else {
+		success.Content = jsonContent(envelope(gen, o.data))
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	errors := o.errors
+	if !o.public {
+		errors = append([]int{http.StatusUnauthorized}, errors...)
+	}
+	if !o.public && !o.unscoped {
+		errors = append(errors, http.StatusForbidden)
+	}
+	if o.body != nil || o.requestContent != nil || len(op.Parameters) > 0 {
+		errors = append([]int{http.StatusBadRequest}, errors...)
+	}
+	errors = append(errors, http.StatusInternalServerError)
+	for _, code := range errors {
+		op.Responses[strconv.Itoa(code)] = &openapi.Response{
+			Description: http.StatusText(code),
+			Content:     jsonContent(openapi.Ref("ErrorResponse")),
+		}
+	}
+	return op
+}
+
+// operationID turns "GET /api/v1/todos/{id}" into "getTodosById".
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(strings.TrimPrefix(path, "/api/v1"), func(r rune) bool {
+		return r == '/' || r == '.' || r == '-' || r == '_'
+	}) {
+		if strings.HasPrefix(part, "{") {
+			part = "By" + strings.Trim(part, "{}")
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+func pathParameter(name string) *openapi.Parameter {
+	schema := &openapi.Schema{Type: "string"}
+	if name == "id" || strings.HasSuffix(name, "ID") {
+		schema.Format = "uuid"
+	}
+	return &openapi.Parameter{Name: name, In: "path", Required: true, Schema: schema}
+}
+
+func jsonContent(schema *openapi.Schema) map[string]*openapi.MediaType {
+	return map[string]*openapi.MediaType{"application/json": {Schema: schema}}
+}
+
+// envelope describes the response.Success body around data.
+func envelope(gen *openapi.Generator, data interface{}) *openapi.Schema {
+	s := &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"success": {Type: "boolean"},
+			"message": {Type: "string"},
+		},
+		Required: []string{"success"},
+	}
+	if data != nil {
+		s.Properties["data"] = gen.Schema(data)
+		s.Required = append(s.Required, "data")
+	}
+	return s
+}
+
+func errorResponseSchema() *openapi.Schema {
+	return &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"success": {Type: "boolean"},
+			"message": {Type: "string"},
+			"errors":  {Type: "array", Items: &openapi.Schema{Type: "string"}},
+		},
+		Required: []string{"success", "message"},
+	}
+}
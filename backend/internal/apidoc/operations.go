@@ -0,0 +1,502 @@
+package apidoc
+
+import (
+	"net/http"
+
+	"github.com/yourusername/todogo-backend/internal/graph"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/openapi"
+)
+
+var (
+	todoFilterParams = []*openapi.Parameter{
+		{Name: "status", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"pending", "completed"}}},
+		{Name: "priority", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"low", "medium", "high"}}},
+		{Name: "search", In: "query", Description: "Matches title and description.", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "tags", In: "query", Description: "Comma-separated; matches todos with any of the tags.", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "archived", In: "query", Description: "List archived todos instead of active ones.", Schema: &openapi.Schema{Type: "boolean"}},
+		{Name: "assigned", In: "query", Description: "With me, list other users' todos assigned to the caller instead of the caller's own.", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"me"}}},
+	}
+
+	viewIDParam = &openapi.Parameter{
+		Name:        "id",
+		In:          "path",
+		Required:    true,
+		Description: "UUID of a saved view or the slug of a built-in view (today, upcoming, overdue, no-due-date).",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+
+	workspaceHeaderParam = &openapi.Parameter{
+		Name:        "X-Workspace-ID",
+		In:          "header",
+		Description: "Workspace to work in. Defaults to the token's workspace, then the personal workspace.",
+		Schema:      &openapi.Schema{Type: "string", Format: "uuid"},
+	}
+
+	accessTokenParam = &openapi.Parameter{
+		Name:        "access_token",
+		In:          "query",
+		Description: "JWT for clients that cannot set the Authorization header.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+
+	calendarContent = map[string]*openapi.MediaType{
+		"text/calendar": {Schema: &openapi.Schema{Type: "string"}},
+	}
+
+	graphQLResponse = map[string]*openapi.MediaType{
+		"application/json": {Schema: &openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"data":   {},
+				"errors": {Type: "array", Items: &openapi.Schema{Type: "object"}},
+			},
+		}},
+	}
+)
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+// operations documents every route, keyed by method and path as registered
+// on the router.
+var operations = map[string]operation{
+	"GET /": {
+		tag: "Meta", summary: "Describe the server", public: true,
+		responseContent: jsonContent(&openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"message": {Type: "string"},
+				"version": {Type: "string"},
+				"status":  {Type: "string"},
+				"docs":    {Type: "string"},
+				"openapi": {Type: "string"},
+			},
+		}),
+	},
+	"GET /health": {
+		tag: "Meta", summary: "Check API health", public: true,
+		responseContent: map[string]*openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: "string"}}},
+	},
+	"GET /.well-known/caldav": {
+		tag: "Calendar", summary: "Discover the CalDAV endpoint", public: true,
+		status: http.StatusMovedPermanently, responseContent: map[string]*openapi.MediaType{},
+	},
+	"GET /graphql": {
+		tag: "GraphQL", summary: "Run a GraphQL query or open a subscription WebSocket",
+		params: []*openapi.Parameter{
+			{Name: "query", In: "query", Schema: &openapi.Schema{Type: "string"}},
+			{Name: "operationName", In: "query", Schema: &openapi.Schema{Type: "string"}},
+			{Name: "variables", In: "query", Description: "JSON-encoded variables.", Schema: &openapi.Schema{Type: "string"}},
+			accessTokenParam,
+		},
+		responseContent: graphQLResponse, errors: []int{http.StatusMethodNotAllowed},
+	},
+	"POST /graphql": {
+		tag: "GraphQL", summary: "Run a GraphQL operation",
+		body: graph.Request{}, responseContent: graphQLResponse,
+	},
+
+	"POST /api/v1/auth/register": {
+		tag: "Auth", summary: "Register a user", public: true,
+		body: models.RegisterRequest{}, status: http.StatusCreated, data: models.LoginResponse{},
+		errors: []int{http.StatusConflict},
+	},
+	"POST /api/v1/auth/login": {
+		tag: "Auth", summary: "Log in", public: true,
+		body: models.LoginRequest{}, data: models.LoginResponse{},
+		errors: []int{http.StatusUnauthorized, http.StatusForbidden},
+	},
+	"POST /api/v1/auth/password": {
+		tag: "Auth", summary: "Change the password with the current one", public: true,
+		body: models.ChangePasswordRequest{}, data: models.LoginResponse{},
+		errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests},
+	},
+	"POST /api/v1/auth/refresh": {
+		tag: "Auth", summary: "Exchange a refresh token for new tokens", public: true,
+		body: models.RefreshRequest{}, data: models.LoginResponse{},
+		errors: []int{http.StatusUnauthorized, http.StatusForbidden},
+	},
+	"POST /api/v1/auth/forgot-password": {
+		tag: "Auth", summary: "Email a password reset link", public: true,
+		body: models.ForgotPasswordRequest{},
+	},
+	"POST /api/v1/auth/reset-password": {
+		tag: "Auth", summary: "Set a new password with a reset token", public: true,
+		body: models.ResetPasswordRequest{}, errors: []int{http.StatusForbidden},
+	},
+	"POST /api/v1/auth/verify-email": {
+		tag: "Auth", summary: "Verify the email address with a token", public: true,
+		body: models.VerifyEmailRequest{}, data: models.User{},
+	},
+	"POST /api/v1/auth/verify-email/resend": {
+		tag: "Auth", summary: "Email a new verification link", public: true,
+		body: models.ResendVerificationRequest{},
+	},
+	"PUT /api/v1/auth/email": {
+		tag: "Auth", summary: "Change the email address", unscoped: true,
+		body: models.ChangeEmailRequest{}, data: models.User{}, errors: []int{http.StatusConflict},
+	},
+	"POST /api/v1/auth/mfa/verify": {
+		tag: "Auth", summary: "Complete a sign-in with a two-factor code", public: true,
+		body: models.MFAVerifyRequest{}, data: models.LoginResponse{},
+		errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests},
+	},
+	"POST /api/v1/auth/oidc/authorize": {
+		tag: "Auth", summary: "Start a single sign-on at the OpenID provider", public: true,
+		data: models.OIDCAuthorization{}, errors: []int{http.StatusNotFound, http.StatusBadGateway},
+	},
+	"POST /api/v1/auth/oidc/callback": {
+		tag: "Auth", summary: "Complete a single sign-on", public: true,
+		body: models.OIDCCallbackRequest{}, data: models.LoginResponse{},
+		errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+	},
+	"POST /api/v1/auth/mfa/totp": {
+		tag: "Auth", summary: "Start TOTP enrollment", unscoped: true,
+		data: models.TOTPEnrollment{}, errors: []int{http.StatusConflict},
+	},
+	"POST /api/v1/auth/mfa/totp/confirm": {
+		tag: "Auth", summary: "Enable TOTP with a code and get recovery codes", unscoped: true,
+		body: models.MFACodeRequest{}, data: models.RecoveryCodes{}, errors: []int{http.StatusConflict},
+	},
+	"POST /api/v1/auth/mfa/totp/disable": {
+		tag: "Auth", summary: "Disable TOTP", unscoped: true,
+		body: models.DisableTOTPRequest{}, errors: []int{http.StatusConflict, http.StatusTooManyRequests},
+	},
+	"POST /api/v1/auth/mfa/recovery-codes": {
+		tag: "Auth", summary: "Replace the recovery codes", unscoped: true,
+		body: models.MFACodeRequest{}, data: models.RecoveryCodes{}, errors: []int{http.StatusConflict, http.StatusTooManyRequests},
+	},
+	"POST /api/v1/auth/logout": {
+		tag: "Auth", summary: "Sign out the current token", unscoped: true,
+		body: models.LogoutRequest{},
+	},
+	"POST /api/v1/auth/logout/all": {
+		tag: "Auth", summary: "Sign out every token of the user", unscoped: true,
+	},
+
+	"GET /api/v1/todos": {
+		tag: "Todos", summary: "List todos",
+		params: todoFilterParams, data: []models.Todo{},
+	},
+	"POST /api/v1/todos": {
+		tag: "Todos", summary: "Create a todo",
+		body: models.CreateTodoRequest{}, status: http.StatusCreated, data: models.Todo{},
+	},
+	"GET /api/v1/todos/{id}": {
+		tag: "Todos", summary: "Get a todo",
+		data: models.Todo{}, errors: []int{http.StatusNotFound},
+	},
+	"PUT /api/v1/todos/{id}": {
+		tag: "Todos", summary: "Update a todo",
+		body: models.UpdateTodoRequest{}, data: models.Todo{}, errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"DELETE /api/v1/todos/{id}": {
+		tag: "Todos", summary: "Delete a todo",
+		errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"PATCH /api/v1/todos/{id}/complete": {
+		tag: "Todos", summary: "Mark a todo as completed",
+		data: models.Todo{},
+	},
+	"PATCH /api/v1/todos/{id}/incomplete": {
+		tag: "Todos", summary: "Mark a todo as incomplete",
+		data: models.Todo{},
+	},
+	"PATCH /api/v1/todos/{id}/archive": {
+		tag: "Todos", summary: "Archive a todo",
+		data: models.Todo{}, errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"PATCH /api/v1/todos/{id}/unarchive": {
+		tag: "Todos", summary: "Restore an archived todo",
+		data: models.Todo{}, errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"PUT /api/v1/todos/{id}/assignee": {
+		tag: "Todos", summary: "Assign a todo to another user",
+		body: models.AssignTodoRequest{}, data: models.Todo{},
+		errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity},
+	},
+	"POST /api/v1/todos/{id}/duplicate": {
+		tag: "Todos", summary: "Create a copy of a todo",
+		body: models.DuplicateTodoRequest{}, status: http.StatusCreated,
+		data: models.Todo{}, errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/v1/todos/export.ics": {
+		tag: "Calendar", summary: "Export todos as iCalendar",
+		params: todoFilterParams, responseContent: calendarContent,
+	},
+	"POST /api/v1/todos/import": {
+		tag: "Calendar", summary: "Import todos from iCalendar",
+		requestContent: map[string]*openapi.MediaType{
+			"text/calendar": {Schema: &openapi.Schema{Type: "string"}},
+			"multipart/form-data": {Schema: &openapi.Schema{
+				Type:       "object",
+				Properties: map[string]*openapi.Schema{"file": {Type: "string", Format: "binary"}},
+				Required:   []string{"file"},
+			}},
+		},
+		data: service.ImportResult{},
+	},
+
+	"GET /api/v1/feeds": {
+		tag: "Calendar", summary: "List calendar feeds",
+		data: []models.CalendarFeed{},
+	},
+	"POST /api/v1/feeds": {
+		tag: "Calendar", summary: "Create a calendar feed",
+		body: models.CreateFeedRequest{}, status: http.StatusCreated, data: models.CalendarFeed{},
+	},
+	"POST /api/v1/feeds/{id}/regenerate": {
+		tag: "Calendar", summary: "Issue a new feed URL",
+		data: models.CalendarFeed{}, errors: []int{http.StatusNotFound},
+	},
+	"DELETE /api/v1/feeds/{id}": {
+		tag: "Calendar", summary: "Revoke a calendar feed",
+		errors: []int{http.StatusNotFound},
+	},
+	"GET /api/v1/feeds/ical/{token}.ics": {
+		tag: "Calendar", summary: "Subscribe to a calendar feed", public: true,
+		responseContent: calendarContent, errors: []int{http.StatusNotFound},
+	},
+
+	"GET /api/v1/webhooks": {
+		tag: "Webhooks", summary: "List webhooks",
+		data: []models.Webhook{},
+	},
+	"POST /api/v1/webhooks": {
+		tag: "Webhooks", summary: "Create a webhook",
+		body: models.CreateWebhookRequest{}, status: http.StatusCreated, data: models.Webhook{},
+	},
+	"GET /api/v1/webhooks/{id}": {
+		tag: "Webhooks", summary: "Get a webhook",
+		data: models.Webhook{}, errors: []int{http.StatusNotFound},
+	},
+	"PATCH /api/v1/webhooks/{id}": {
+		tag: "Webhooks", summary: "Update a webhook",
+		body: models.UpdateWebhookRequest{}, data: models.Webhook{}, errors: []int{http.StatusNotFound},
+	},
+	"DELETE /api/v1/webhooks/{id}": {
+		tag: "Webhooks", summary: "Delete a webhook",
+		errors: []int{http.StatusNotFound},
+	},
+	"POST /api/v1/webhooks/{id}/ping": {
+		tag: "Webhooks", summary: "Send a test delivery",
+		status: http.StatusAccepted, data: models.WebhookDelivery{}, errors: []int{http.StatusNotFound},
+	},
+	"GET /api/v1/webhooks/{id}/deliveries": {
+		tag: "Webhooks", summary: "List recent deliveries",
+		data: []models.WebhookDelivery{}, errors: []int{http.StatusNotFound},
+	},
+	"POST /api/v1/webhooks/{id}/deliveries/{deliveryID}/replay": {
+		tag: "Webhooks", summary: "Replay a delivery",
+		status: http.StatusAccepted, data: models.WebhookDelivery{}, errors: []int{http.StatusNotFound},
+	},
+
+	"GET /api/v1/events": {
+		tag: "Events", summary: "Stream todo changes as Server-Sent Events", unscoped: true,
+		params: []*openapi.Parameter{
+			{Name: "Last-Event-ID", In: "header", Description: "Resume after this event.", Schema: &openapi.Schema{Type: "string"}},
+			{Name: "last_event_id", In: "query", Description: "Resume after this event.", Schema: &openapi.Schema{Type: "string"}},
+			accessTokenParam,
+		},
+		responseContent: map[string]*openapi.MediaType{"text/event-stream": {Schema: &openapi.Schema{Type: "string"}}},
+	},
+
+	"GET /api/v1/sync": {
+		tag: "Sync", summary: "Pull changes since a sync token",
+		params: []*openapi.Parameter{
+			{Name: "sync_token", In: "query", Description: "Omit for a full sync.", Schema: &openapi.Schema{Type: "string"}},
+		},
+		data: models.SyncResponse{},
+	},
+	"GET /api/v1/stats": {
+		tag: "Stats", summary: "Get productivity statistics",
+		params: []*openapi.Parameter{
+			{Name: "days", In: "query", Description: "Window length in days, ending today.", Schema: &openapi.Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(365)}},
+			{Name: "tz", In: "query", Description: "IANA timezone for local dates, e.g. Europe/Berlin. Defaults to UTC.", Schema: &openapi.Schema{Type: "string"}},
+		},
+		data: models.TodoStats{},
+	},
+	"GET /api/v1/digest": {
+		tag: "Digest", summary: "Get the email digest schedule", unscoped: true,
+		data: models.DigestSchedule{}, errors: []int{http.StatusNotFound},
+	},
+	"PUT /api/v1/digest": {
+		tag: "Digest", summary: "Create or replace the email digest schedule", unscoped: true,
+		body: models.UpdateDigestRequest{}, data: models.DigestSchedule{},
+	},
+	"DELETE /api/v1/digest": {
+		tag: "Digest", summary: "Stop the email digest", unscoped: true,
+		errors: []int{http.StatusNotFound},
+	},
+	"POST /api/v1/digest/send": {
+		tag: "Digest", summary: "Send the digest now", unscoped: true,
+		errors: []int{http.StatusNotFound, http.StatusBadGateway},
+	},
+	"GET /api/v1/views": {
+		tag: "Views", summary: "List built-in and saved views",
+		data: []models.View{},
+	},
+	"POST /api/v1/views": {
+		tag: "Views", summary: "Save a view",
+		body: models.CreateViewRequest{}, status: http.StatusCreated, data: models.View{},
+	},
+	"GET /api/v1/views/{id}": {
+		tag: "Views", summary: "Get a view",
+		params: []*openapi.Parameter{viewIDParam}, data: models.View{}, errors: []int{http.StatusNotFound},
+	},
+	"PUT /api/v1/views/{id}": {
+		tag: "Views", summary: "Update a saved view",
+		params: []*openapi.Parameter{viewIDParam}, body: models.UpdateViewRequest{}, data: models.View{},
+		errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"DELETE /api/v1/views/{id}": {
+		tag: "Views", summary: "Delete a saved view",
+		params: []*openapi.Parameter{viewIDParam}, errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/v1/views/{id}/todos": {
+		tag: "Views", summary: "List the todos of a view",
+		params: []*openapi.Parameter{
+			viewIDParam,
+			{Name: "tz", In: "query", Description: "IANA timezone for relative due ranges and date groups. Defaults to UTC.", Schema: &openapi.Schema{Type: "string"}},
+		},
+		data: models.ViewResult{}, errors: []int{http.StatusNotFound},
+	},
+	"GET /api/v1/archive-rule": {
+		tag: "Archive", summary: "Get the automatic archive rule",
+		data: models.ArchiveRule{}, errors: []int{http.StatusNotFound},
+	},
+	"PUT /api/v1/archive-rule": {
+		tag: "Archive", summary: "Create or replace the automatic archive rule",
+		body: models.UpdateArchiveRuleRequest{}, data: models.ArchiveRule{},
+	},
+	"DELETE /api/v1/archive-rule": {
+		tag: "Archive", summary: "Stop archiving automatically",
+		errors: []int{http.StatusNotFound},
+	},
+	"GET /api/v1/inbox": {
+		tag: "Inbox", summary: "List assignment notifications",
+		params: []*openapi.Parameter{
+			{Name: "unread", In: "query", Description: "Only list unread items.", Schema: &openapi.Schema{Type: "boolean"}},
+		},
+		data: models.InboxResult{},
+	},
+	"POST /api/v1/inbox/read": {
+		tag: "Inbox", summary: "Mark all notifications as read",
+	},
+	"POST /api/v1/inbox/{id}/read": {
+		tag: "Inbox", summary: "Mark a notification as read",
+		errors: []int{http.StatusNotFound},
+	},
+	"GET /api/v1/workspaces": {
+		tag: "Workspaces", summary: "List the caller's workspaces", unscoped: true,
+		data: []models.Workspace{},
+	},
+	"POST /api/v1/workspaces": {
+		tag: "Workspaces", summary: "Create a shared workspace", unscoped: true,
+		body: models.CreateWorkspaceRequest{}, status: http.StatusCreated, data: models.Workspace{},
+		errors: []int{http.StatusForbidden},
+	},
+	"GET /api/v1/workspaces/{id}": {
+		tag: "Workspaces", summary: "Get a workspace", unscoped: true,
+		data: models.Workspace{}, errors: []int{http.StatusNotFound},
+	},
+	"PUT /api/v1/workspaces/{id}": {
+		tag: "Workspaces", summary: "Rename a workspace", unscoped: true,
+		body: models.UpdateWorkspaceRequest{}, data: models.Workspace{}, errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"DELETE /api/v1/workspaces/{id}": {
+		tag: "Workspaces", summary: "Delete a workspace with its todos", unscoped: true,
+		errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"POST /api/v1/workspaces/{id}/switch": {
+		tag: "Workspaces", summary: "Issue a token that defaults to the workspace", unscoped: true,
+		data: models.LoginResponse{}, errors: []int{http.StatusNotFound},
+	},
+	"GET /api/v1/workspaces/{id}/members": {
+		tag: "Workspaces", summary: "List workspace members", unscoped: true,
+		data: []models.WorkspaceMember{}, errors: []int{http.StatusNotFound},
+	},
+	"DELETE /api/v1/workspaces/{id}/members/{userID}": {
+		tag: "Workspaces", summary: "Remove a member or leave the workspace", unscoped: true,
+		errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/v1/workspaces/{id}/invitations": {
+		tag: "Workspaces", summary: "List pending invitations", unscoped: true,
+		data: []models.WorkspaceInvitation{}, errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"POST /api/v1/workspaces/{id}/invitations": {
+		tag: "Workspaces", summary: "Invite someone by email", unscoped: true,
+		body: models.CreateInvitationRequest{}, status: http.StatusCreated, data: models.WorkspaceInvitation{},
+		errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"DELETE /api/v1/workspaces/{id}/invitations/{invitationID}": {
+		tag: "Workspaces", summary: "Revoke an invitation", unscoped: true,
+		errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"POST /api/v1/invitations/{token}/accept": {
+		tag: "Workspaces", summary: "Join a workspace with an invitation token", unscoped: true,
+		data: models.Workspace{}, errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"GET /api/v1/roles": {
+		tag: "Roles", summary: "List roles and their permissions", unscoped: true,
+		data: []models.RoleInfo{},
+	},
+	"GET /api/v1/admin/users": {
+		tag: "Admin", summary: "List and search users with their todo counts", unscoped: true,
+		params: []*openapi.Parameter{
+			{Name: "search", In: "query", Description: "Matches name and email.", Schema: &openapi.Schema{Type: "string"}},
+			{Name: "role", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"admin", "member", "guest"}}},
+			{Name: "status", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"active", "disabled"}}},
+			{Name: "limit", In: "query", Description: "Defaults to 50.", Schema: &openapi.Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(200)}},
+			{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: floatPtr(0)}},
+		},
+		data: []models.AdminUser{}, errors: []int{http.StatusForbidden},
+	},
+	"GET /api/v1/admin/users/{id}": {
+		tag: "Admin", summary: "Get a user with their todo counts", unscoped: true,
+		data: models.AdminUser{}, errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"DELETE /api/v1/admin/users/{id}": {
+		tag: "Admin", summary: "Delete a user and everything they own", unscoped: true,
+		errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+	},
+	"POST /api/v1/admin/users/{id}/disable": {
+		tag: "Admin", summary: "Disable a user's account", unscoped: true,
+		data: models.User{}, errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+	},
+	"POST /api/v1/admin/users/{id}/enable": {
+		tag: "Admin", summary: "Enable a disabled account", unscoped: true,
+		data: models.User{}, errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"POST /api/v1/admin/users/{id}/password-reset": {
+		tag: "Admin", summary: "Require a user to choose a new password", unscoped: true,
+		data: models.User{}, errors: []int{http.StatusForbidden, http.StatusNotFound},
+	},
+	"PUT /api/v1/admin/users/{id}/role": {
+		tag: "Roles", summary: "Assign a role to a user", unscoped: true,
+		body: models.UpdateRoleRequest{}, data: models.User{},
+		errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+	},
+	"GET /api/v1/admin/audit": {
+		tag: "Roles", summary: "List recent audit log entries", unscoped: true,
+		params: []*openapi.Parameter{
+			{Name: "action", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{
+				string(models.AuditAccessDenied), string(models.AuditRoleChanged), string(models.AuditUserDisabled),
+				string(models.AuditUserEnabled), string(models.AuditPasswordReset), string(models.AuditUserDeleted), string(models.AuditTokenReused),
+				string(models.AuditMFAEnabled), string(models.AuditMFADisabled), string(models.AuditSSOLinked),
+			}}},
+		},
+		data: []models.AuditEntry{}, errors: []int{http.StatusForbidden},
+	},
+	"POST /api/v1/undo/{token}": {
+		tag: "Undo", summary: "Revert a change using its X-Undo-Token",
+		data: models.UndoResult{}, errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+	},
+	"POST /api/v1/sync": {
+		tag: "Sync", summary: "Push offline mutations and pull changes",
+		body: models.SyncRequest{}, data: models.SyncResponse{},
+	},
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -13,6 +15,14 @@ type Config struct {
 	JWT      JWTConfig
 	Server   ServerConfig
 	CORS     CORSConfig
+	Webhook  WebhookConfig
+	SMTP     SMTPConfig
+	Digest   DigestConfig
+	Archive  ArchiveConfig
+	Undo     UndoConfig
+	RBAC     RBACConfig
+	Auth     AuthConfig
+	OIDC     OIDCConfig
 }
 
 type DatabaseConfig struct {
@@ -25,28 +35,212 @@ type DatabaseConfig struct {
 }
 
 type JWTConfig struct {
-	Secret     string
-	Expiration time.Duration
+	Secret string
+	// Expiration is the lifetime of access tokens; refresh tokens, which
+	// renew them, live for RefreshExpiration.
+	Expiration        time.Duration
+	RefreshExpiration time.Duration
+	// SessionCacheTTL is how long account state and token revocations are
+	// cached per instance; changes made on other instances take up to this
+	// long to apply.
+	SessionCacheTTL time.Duration
 }
 
 type ServerConfig struct {
 	Port string
-	Env  string
+	// GRPCPort serves the gRPC API for internal consumers.
+	GRPCPort string
+	Env      string
+	// PublicURL is the externally reachable base URL used in links handed to
+	// third-party clients such as calendar feed subscriptions.
+	PublicURL string
+	// AppURL is the web app's base URL, used for links in emails.
+	AppURL string
+	// ValidateRequests checks every request against the OpenAPI document.
+	// It defaults to on in development.
+	ValidateRequests bool
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From is the sender, e.g. "Todogo <no-reply@example.com>".
+	From string
+	// TLS is "auto" (STARTTLS when offered), "starttls" (required), "tls"
+	// (implicit TLS, usually port 465) or "none".
+	TLS     string
+	Timeout time.Duration
+}
+
+type DigestConfig struct {
+	// Enabled runs the digest job; schedules can be edited either way.
+	Enabled      bool
+	PollInterval time.Duration
+	// UpcomingDays is how far ahead the "upcoming" section looks.
+	UpcomingDays int
+}
+
+type ArchiveConfig struct {
+	// Enabled runs the job that applies archive rules.
+	Enabled  bool
+	Interval time.Duration
+}
+
+type UndoConfig struct {
+	// Window is how long an undo token stays valid.
+	Window time.Duration
+}
+
+type RBACConfig struct {
+	// AdminEmails are made admins at startup and on registration.
+	AdminEmails []string
+	// DefaultRole is given to new users: "member" or "guest".
+	DefaultRole string
+}
+
+type AuthConfig struct {
+	// PasswordResetExpiration is how long a password reset link works.
+	PasswordResetExpiration time.Duration
+	// PasswordResetInterval and PasswordResetDailyLimit throttle how often
+	// reset emails are sent to an account.
+	PasswordResetInterval   time.Duration
+	PasswordResetDailyLimit int
+	// RequireVerifiedEmail keeps users from signing in until they verified
+	// their email address.
+	RequireVerifiedEmail bool
+	// VerificationExpiration is how long an email verification link works.
+	VerificationExpiration time.Duration
+	// VerificationResendInterval and VerificationDailyLimit throttle how
+	// often verification emails are resent to an account.
+	VerificationResendInterval time.Duration
+	VerificationDailyLimit     int
+	// TOTPIssuer names the service in authenticator apps.
+	TOTPIssuer string
+	// MFAChallengeExpiration is how long a sign-in waits for the second
+	// factor.
+	MFAChallengeExpiration time.Duration
+}
+
+// OIDCConfig configures single sign-on with an OpenID Connect provider. It is
+// off unless IssuerURL is set.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the web app page the provider sends users back to; it
+	// posts the code and state to the API.
+	RedirectURL string
+	Scopes      []string
+	// AutoProvision creates accounts for unknown users. Otherwise only users
+	// whose verified email matches an account can sign in.
+	AutoProvision bool
+}
+
+func (c *OIDCConfig) Enabled() bool {
+	return c.IssuerURL != ""
 }
 
 type CORSConfig struct {
 	AllowedOrigins []string
 }
 
+type WebhookConfig struct {
+	// MaxAttempts is how often a delivery is tried before it is marked failed.
+	MaxAttempts int
+	// DisableAfter consecutive failed attempts switches an endpoint off.
+	DisableAfter int
+	Timeout      time.Duration
+	PollInterval time.Duration
+	// AllowedHosts exempts host names, IP addresses and CIDR networks from
+	// the block on loopback and private addresses, for local receivers.
+	AllowedHosts []string
+}
+
 func Load() (*Config, error) {
 	// Load .env file if exists
 	_ = godotenv.Load()
 
-	jwtExpiration, err := time.ParseDuration(getEnv("JWT_EXPIRATION", "24h"))
+	jwtExpiration, err := time.ParseDuration(getEnv("JWT_EXPIRATION", "15m"))
+	if err != nil {
+		jwtExpiration = 15 * time.Minute
+	}
+
+	refreshExpiration, err := time.ParseDuration(getEnv("JWT_REFRESH_EXPIRATION", "720h"))
+	if err != nil {
+		refreshExpiration = 30 * 24 * time.Hour
+	}
+
+	sessionCacheTTL, err := time.ParseDuration(getEnv("JWT_SESSION_CACHE_TTL", "30s"))
+	if err != nil {
+		sessionCacheTTL = 30 * time.Second
+	}
+
+	webhookTimeout, err := time.ParseDuration(getEnv("WEBHOOK_TIMEOUT", "10s"))
+	if err != nil {
+		webhookTimeout = 10 * time.Second
+	}
+
+	webhookPollInterval, err := time.ParseDuration(getEnv("WEBHOOK_POLL_INTERVAL", "5s"))
+	if err != nil {
+		webhookPollInterval = 5 * time.Second
+	}
+
+	smtpTimeout, err := time.ParseDuration(getEnv("SMTP_TIMEOUT", "10s"))
+	if err != nil {
+		smtpTimeout = 10 * time.Second
+	}
+
+	digestPollInterval, err := time.ParseDuration(getEnv("DIGEST_POLL_INTERVAL", "1m"))
+	if err != nil {
+		digestPollInterval = time.Minute
+	}
+
+	archiveInterval, err := time.ParseDuration(getEnv("ARCHIVE_INTERVAL", "1h"))
+	if err != nil {
+		archiveInterval = time.Hour
+	}
+
+	undoWindow, err := time.ParseDuration(getEnv("UNDO_WINDOW", "60s"))
+	if err != nil {
+		undoWindow = time.Minute
+	}
+
+	passwordResetExpiration, err := time.ParseDuration(getEnv("PASSWORD_RESET_EXPIRATION", "1h"))
+	if err != nil {
+		passwordResetExpiration = time.Hour
+	}
+
+	passwordResetInterval, err := time.ParseDuration(getEnv("PASSWORD_RESET_INTERVAL", "1m"))
+	if err != nil {
+		passwordResetInterval = time.Minute
+	}
+
+	verificationExpiration, err := time.ParseDuration(getEnv("EMAIL_VERIFICATION_EXPIRATION", "48h"))
 	if err != nil {
-		jwtExpiration = 24 * time.Hour
+		verificationExpiration = 48 * time.Hour
 	}
 
+	verificationResendInterval, err := time.ParseDuration(getEnv("EMAIL_VERIFICATION_RESEND_INTERVAL", "1m"))
+	if err != nil {
+		verificationResendInterval = time.Minute
+	}
+
+	mfaChallengeExpiration, err := time.ParseDuration(getEnv("MFA_CHALLENGE_EXPIRATION", "5m"))
+	if err != nil {
+		mfaChallengeExpiration = 5 * time.Minute
+	}
+
+	oidcScopes := getEnvList("OIDC_SCOPES")
+	if len(oidcScopes) == 0 {
+		oidcScopes = []string{"openid", "email", "profile"}
+	}
+
+	appURL := getEnv("APP_URL", "http://localhost:3000")
+
+	env := getEnv("ENV", "development")
+
 	config := &Config{
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -57,18 +251,75 @@ func Load() (*Config, error) {
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "your-secret-key"),
-			Expiration: jwtExpiration,
+			Secret:            getEnv("JWT_SECRET", "your-secret-key"),
+			Expiration:        jwtExpiration,
+			RefreshExpiration: refreshExpiration,
+			SessionCacheTTL:   sessionCacheTTL,
 		},
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
-			Env:  getEnv("ENV", "development"),
+			Port:             getEnv("PORT", "8080"),
+			GRPCPort:         getEnv("GRPC_PORT", "9090"),
+			Env:              env,
+			PublicURL:        getEnv("PUBLIC_URL", "http://localhost:8080"),
+			AppURL:           appURL,
+			ValidateRequests: getEnvBool("OPENAPI_VALIDATION", env == "development"),
 		},
 		CORS: CORSConfig{
 			AllowedOrigins: []string{
 				getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000"),
 			},
 		},
+		Webhook: WebhookConfig{
+			MaxAttempts:  getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			DisableAfter: getEnvInt("WEBHOOK_DISABLE_AFTER", 20),
+			Timeout:      webhookTimeout,
+			PollInterval: webhookPollInterval,
+			AllowedHosts: getEnvList("WEBHOOK_ALLOWED_HOSTS"),
+		},
+		SMTP: SMTPConfig{
+			Host:     getEnv("SMTP_HOST", "localhost"),
+			Port:     getEnvInt("SMTP_PORT", 1025),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", "Todogo <no-reply@todogo.local>"),
+			TLS:      getEnv("SMTP_TLS", "auto"),
+			Timeout:  smtpTimeout,
+		},
+		Digest: DigestConfig{
+			Enabled:      getEnvBool("DIGEST_ENABLED", true),
+			PollInterval: digestPollInterval,
+			UpcomingDays: getEnvInt("DIGEST_UPCOMING_DAYS", 7),
+		},
+		Archive: ArchiveConfig{
+			Enabled:  getEnvBool("ARCHIVE_ENABLED", true),
+			Interval: archiveInterval,
+		},
+		Undo: UndoConfig{
+			Window: undoWindow,
+		},
+		RBAC: RBACConfig{
+			AdminEmails: getEnvList("ADMIN_EMAILS"),
+			DefaultRole: getEnv("DEFAULT_ROLE", "member"),
+		},
+		OIDC: OIDCConfig{
+			IssuerURL:     getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:      getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret:  getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:   getEnv("OIDC_REDIRECT_URL", strings.TrimRight(appURL, "/")+"/auth/oidc/callback"),
+			Scopes:        oidcScopes,
+			AutoProvision: getEnvBool("OIDC_AUTO_PROVISION", true),
+		},
+		Auth: AuthConfig{
+			PasswordResetExpiration:    passwordResetExpiration,
+			PasswordResetInterval:      passwordResetInterval,
+			PasswordResetDailyLimit:    getEnvInt("PASSWORD_RESET_DAILY_LIMIT", 5),
+			RequireVerifiedEmail:       getEnvBool("REQUIRE_VERIFIED_EMAIL", false),
+			VerificationExpiration:     verificationExpiration,
+			VerificationResendInterval: verificationResendInterval,
+			VerificationDailyLimit:     getEnvInt("EMAIL_VERIFICATION_DAILY_LIMIT", 5),
+			TOTPIssuer:                 getEnv("TOTP_ISSUER", "Todogo"),
+			MFAChallengeExpiration:     mfaChallengeExpiration,
+		},
 	}
 
 	return config, nil
@@ -87,3 +338,28 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvList splits a comma-separated variable, dropping empty items.
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
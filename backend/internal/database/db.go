@@ -5,12 +5,13 @@ import (
 	"fmt"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 )
 
 type DB struct {
 	*sql.DB
+	dsn string
 }
 
 func NewDB(dsn string) (*DB, error) {
@@ -39,7 +40,7 @@ func NewDB(dsn string) (*DB, error) {
 		log.Info().Msg("Database migrations completed successfully")
 	}
 
-	return &DB{db}, nil
+	return &DB{DB: db, dsn: dsn}, nil
 }
 
 func runMigrations(db *sql.DB) error {
@@ -86,6 +87,596 @@ func runMigrations(db *sql.DB) error {
 		return fmt.Errorf("failed to create todos table: %w", err)
 	}
 
+	// iCalendar UID of todos imported from other calendars
+	_, err = db.Exec(`
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS ical_uid VARCHAR(255);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_todos_user_ical_uid ON todos(user_id, ical_uid) WHERE ical_uid IS NOT NULL;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add todos ical_uid column: %w", err)
+	}
+
+	// Create calendar feeds table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS calendar_feeds (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			tag VARCHAR(100),
+			token_hash VARCHAR(64) NOT NULL UNIQUE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_calendar_feeds_user_id ON calendar_feeds(user_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create calendar_feeds table: %w", err)
+	}
+
+	// Change tracking for sync clients: a global sequence stamped on every
+	// todo write, plus tombstones for deleted todos
+	_, err = db.Exec(`
+		CREATE SEQUENCE IF NOT EXISTS todo_sync_seq;
+
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS sync_seq BIGINT NOT NULL DEFAULT nextval('todo_sync_seq');
+		CREATE INDEX IF NOT EXISTS idx_todos_user_sync_seq ON todos(user_id, sync_seq);
+
+		CREATE TABLE IF NOT EXISTS todo_tombstones (
+			todo_id UUID PRIMARY KEY,
+			user_id UUID NOT NULL,
+			ical_uid VARCHAR(255),
+			deleted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			sync_seq BIGINT NOT NULL DEFAULT nextval('todo_sync_seq')
+		);
+		CREATE INDEX IF NOT EXISTS idx_todo_tombstones_user_sync_seq ON todo_tombstones(user_id, sync_seq);
+
+		CREATE OR REPLACE FUNCTION todos_bump_sync_seq() RETURNS trigger AS $$
+		BEGIN
+			NEW.sync_seq := nextval('todo_sync_seq');
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS todos_bump_sync_seq ON todos;
+		CREATE TRIGGER todos_bump_sync_seq
+			BEFORE UPDATE ON todos
+			FOR EACH ROW EXECUTE FUNCTION todos_bump_sync_seq();
+
+		CREATE OR REPLACE FUNCTION todos_record_tombstone() RETURNS trigger AS $$
+		BEGIN
+			INSERT INTO todo_tombstones (todo_id, user_id, ical_uid)
+			VALUES (OLD.id, OLD.user_id, OLD.ical_uid)
+			ON CONFLICT (todo_id) DO UPDATE
+				SET deleted_at = NOW(), sync_seq = nextval('todo_sync_seq');
+			RETURN OLD;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS todos_record_tombstone ON todos;
+		CREATE TRIGGER todos_record_tombstone
+			AFTER DELETE ON todos
+			FOR EACH ROW EXECUTE FUNCTION todos_record_tombstone();
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to set up todo change tracking: %w", err)
+	}
+
+	// Create webhook tables
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS webhooks (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			url TEXT NOT NULL,
+			events TEXT[] NOT NULL,
+			secret VARCHAR(64) NOT NULL,
+			active BOOLEAN DEFAULT TRUE,
+			failure_count INTEGER DEFAULT 0,
+			disabled_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks(user_id);
+
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+			event_id UUID NOT NULL,
+			event_type VARCHAR(50) NOT NULL,
+			payload JSONB NOT NULL,
+			status VARCHAR(20) DEFAULT 'pending',
+			attempts INTEGER DEFAULT 0,
+			next_attempt_at TIMESTAMP,
+			last_status_code INTEGER,
+			last_error TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			delivered_at TIMESTAMP
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, created_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook tables: %w", err)
+	}
+
+	// Create todo event log used for SSE resume and cross-instance fan-out
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS todo_events (
+			id BIGSERIAL PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			event_type VARCHAR(50) NOT NULL,
+			payload JSONB NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_todo_events_user_id ON todo_events(user_id, id);
+		CREATE INDEX IF NOT EXISTS idx_todo_events_created_at ON todo_events(created_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create todo_events table: %w", err)
+	}
+
+	// Track per-todo and per-field versions for offline sync
+	_, err = db.Exec(`
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS field_versions JSONB NOT NULL DEFAULT '{}';
+
+		CREATE OR REPLACE FUNCTION todos_track_versions() RETURNS trigger AS $$
+		BEGIN
+			NEW.version := OLD.version + 1;
+			NEW.field_versions := OLD.field_versions || jsonb_strip_nulls(jsonb_build_object(
+				'title', CASE WHEN NEW.title IS DISTINCT FROM OLD.title THEN NEW.version END,
+				'description', CASE WHEN NEW.description IS DISTINCT FROM OLD.description THEN NEW.version END,
+				'priority', CASE WHEN NEW.priority IS DISTINCT FROM OLD.priority THEN NEW.version END,
+				'due_date', CASE WHEN NEW.due_date IS DISTINCT FROM OLD.due_date THEN NEW.version END,
+				'tags', CASE WHEN NEW.tags IS DISTINCT FROM OLD.tags THEN NEW.version END,
+				'completed', CASE WHEN NEW.completed IS DISTINCT FROM OLD.completed THEN NEW.version END
+			));
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS todos_track_versions ON todos;
+		CREATE TRIGGER todos_track_versions
+			BEFORE UPDATE ON todos
+			FOR EACH ROW EXECUTE FUNCTION todos_track_versions();
+
+		CREATE OR REPLACE FUNCTION todos_clear_tombstone() RETURNS trigger AS $$
+		BEGIN
+			DELETE FROM todo_tombstones WHERE todo_id = NEW.id AND user_id = NEW.user_id;
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS todos_clear_tombstone ON todos;
+		CREATE TRIGGER todos_clear_tombstone
+			AFTER INSERT ON todos
+			FOR EACH ROW EXECUTE FUNCTION todos_clear_tombstone();
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to set up todo versioning: %w", err)
+	}
+
+	// Create email digest schedules
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS digest_schedules (
+			user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+			frequency VARCHAR(10) NOT NULL DEFAULT 'daily',
+			weekday SMALLINT,
+			send_time TIME NOT NULL DEFAULT '08:00',
+			timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+			enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			next_run_at TIMESTAMP NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_sent_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_digest_schedules_due ON digest_schedules(next_run_at) WHERE enabled;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create digest schedules table: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS views (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			filter JSONB NOT NULL DEFAULT '{}',
+			sort VARCHAR(20) NOT NULL DEFAULT 'created_at',
+			direction VARCHAR(4) NOT NULL DEFAULT 'desc',
+			group_by VARCHAR(20) NOT NULL DEFAULT 'none',
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_views_user_id ON views(user_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create views table: %w", err)
+	}
+
+	_, err = db.Exec(`
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+		CREATE INDEX IF NOT EXISTS idx_todos_user_active ON todos(user_id, created_at DESC) WHERE archived_at IS NULL;
+		CREATE INDEX IF NOT EXISTS idx_todos_user_archived ON todos(user_id, archived_at DESC) WHERE archived_at IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_todos_archivable ON todos(user_id, completed_at) WHERE completed AND archived_at IS NULL;
+
+		CREATE TABLE IF NOT EXISTS archive_rules (
+			user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+			completed_after_days INTEGER NOT NULL,
+			enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add todo archiving: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS undo_actions (
+			token_hash VARCHAR(64) PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			action VARCHAR(50) NOT NULL,
+			entries JSONB NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_undo_actions_expires_at ON undo_actions(expires_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create undo actions table: %w", err)
+	}
+
+	// Assign todos to other users, with an inbox of assignment notifications
+	_, err = db.Exec(`
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS assignee_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+		CREATE INDEX IF NOT EXISTS idx_todos_assignee ON todos(assignee_id, created_at DESC) WHERE assignee_id IS NOT NULL;
+
+		CREATE TABLE IF NOT EXISTS inbox_items (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			type VARCHAR(20) NOT NULL,
+			todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+			actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+			read_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_inbox_items_user_created ON inbox_items(user_id, created_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_inbox_items_user_unread ON inbox_items(user_id) WHERE read_at IS NULL;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add todo assignees: %w", err)
+	}
+
+	// Workspaces: every user gets a personal one, and existing todos, sync
+	// tombstones and calendar feeds move into it
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS workspaces (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			name VARCHAR(100) NOT NULL,
+			personal_user_id UUID UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+			created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS workspace_members (
+			workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			role VARCHAR(20) NOT NULL DEFAULT 'member',
+			joined_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (workspace_id, user_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_workspace_members_user ON workspace_members(user_id);
+
+		CREATE TABLE IF NOT EXISTS workspace_invitations (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+			email VARCHAR(255) NOT NULL,
+			token_hash VARCHAR(64) NOT NULL UNIQUE,
+			invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+			expires_at TIMESTAMP NOT NULL,
+			accepted_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_workspace_invitations_workspace ON workspace_invitations(workspace_id);
+
+		INSERT INTO workspaces (name, personal_user_id, created_by)
+		SELECT 'Personal', id, id FROM users
+		ON CONFLICT (personal_user_id) DO NOTHING;
+
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		SELECT id, personal_user_id, 'owner' FROM workspaces WHERE personal_user_id IS NOT NULL
+		ON CONFLICT DO NOTHING;
+
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+		UPDATE todos t SET workspace_id = w.id
+		FROM workspaces w
+		WHERE w.personal_user_id = t.user_id AND t.workspace_id IS NULL;
+		ALTER TABLE todos ALTER COLUMN workspace_id SET NOT NULL;
+
+		CREATE INDEX IF NOT EXISTS idx_todos_workspace_user ON todos(workspace_id, user_id, created_at DESC);
+
+		ALTER TABLE todo_tombstones ADD COLUMN IF NOT EXISTS workspace_id UUID;
+		UPDATE todo_tombstones tt SET workspace_id = w.id
+		FROM workspaces w
+		WHERE w.personal_user_id = tt.user_id AND tt.workspace_id IS NULL;
+
+		CREATE OR REPLACE FUNCTION todos_record_tombstone() RETURNS trigger AS $$
+		BEGIN
+			INSERT INTO todo_tombstones (todo_id, user_id, ical_uid, workspace_id)
+			VALUES (OLD.id, OLD.user_id, OLD.ical_uid, OLD.workspace_id)
+			ON CONFLICT (todo_id) DO UPDATE
+				SET deleted_at = NOW(), sync_seq = nextval('todo_sync_seq'), workspace_id = EXCLUDED.workspace_id;
+			RETURN OLD;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		ALTER TABLE calendar_feeds ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+		UPDATE calendar_feeds f SET workspace_id = w.id
+		FROM workspaces w
+		WHERE w.personal_user_id = f.user_id AND f.workspace_id IS NULL;
+		ALTER TABLE calendar_feeds ALTER COLUMN workspace_id SET NOT NULL;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create workspaces: %w", err)
+	}
+
+	// Account-wide roles and the audit log
+	_, err = db.Exec(`
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'member'
+			CHECK (role IN ('admin', 'member', 'guest'));
+
+		CREATE TABLE IF NOT EXISTS audit_log (
+			id BIGSERIAL PRIMARY KEY,
+			actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+			action VARCHAR(50) NOT NULL,
+			target VARCHAR(255) NOT NULL,
+			details JSONB NOT NULL DEFAULT '{}',
+			request_id VARCHAR(100) NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add roles: %w", err)
+	}
+
+	// Account state managed by admins
+	_, err = db.Exec(`
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMP;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add user account state: %w", err)
+	}
+
+	// Refresh tokens with rotation families
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS refresh_tokens (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			family_id UUID NOT NULL,
+			token_hash VARCHAR(64) NOT NULL UNIQUE,
+			workspace_id UUID REFERENCES workspaces(id) ON DELETE SET NULL,
+			expires_at TIMESTAMP NOT NULL,
+			used_at TIMESTAMP,
+			revoked_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens(user_id);
+		CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens(family_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh tokens: %w", err)
+	}
+
+	// Access token revocation
+	_, err = db.Exec(`
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS token_generation INTEGER NOT NULL DEFAULT 0;
+
+		CREATE TABLE IF NOT EXISTS revoked_tokens (
+			jti UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			expires_at TIMESTAMP NOT NULL,
+			revoked_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires ON revoked_tokens(expires_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create revoked tokens: %w", err)
+	}
+
+	// Password reset tokens
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS password_reset_tokens (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			token_hash VARCHAR(64) NOT NULL UNIQUE,
+			expires_at TIMESTAMP NOT NULL,
+			used_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user ON password_reset_tokens(user_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create password reset tokens: %w", err)
+	}
+
+	// Email verification; existing users count as verified
+	_, err = db.Exec(`
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP DEFAULT NOW();
+		ALTER TABLE users ALTER COLUMN email_verified_at DROP DEFAULT;
+
+		CREATE TABLE IF NOT EXISTS email_verification_tokens (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			email VARCHAR(255) NOT NULL,
+			token_hash VARCHAR(64) NOT NULL UNIQUE,
+			expires_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user ON email_verification_tokens(user_id, created_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add email verification: %w", err)
+	}
+
+	// TOTP two-factor authentication
+	_, err = db.Exec(`
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64);
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMP;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT;
+
+		CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			code_hash VARCHAR(64) NOT NULL,
+			used_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user ON mfa_recovery_codes(user_id);
+
+		CREATE TABLE IF NOT EXISTS mfa_challenges (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			token_hash VARCHAR(64) NOT NULL UNIQUE,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			expires_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_mfa_challenges_user ON mfa_challenges(user_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add two-factor authentication: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS oidc_states (
+			state_hash VARCHAR(64) PRIMARY KEY,
+			nonce VARCHAR(64) NOT NULL,
+			code_verifier VARCHAR(128) NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_oidc_states_expires ON oidc_states(expires_at);
+
+		CREATE TABLE IF NOT EXISTS user_identities (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			issuer VARCHAR(255) NOT NULL,
+			subject VARCHAR(255) NOT NULL,
+			email VARCHAR(255),
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			UNIQUE (issuer, subject)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_user_identities_user ON user_identities(user_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add single sign-on: %w", err)
+	}
+
+	// Saved views and undo tokens belong to a workspace
+	_, err = db.Exec(`
+		ALTER TABLE views ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+		UPDATE views v SET workspace_id = w.id
+		FROM workspaces w
+		WHERE w.personal_user_id = v.user_id AND v.workspace_id IS NULL;
+		ALTER TABLE views ALTER COLUMN workspace_id SET NOT NULL;
+
+		CREATE INDEX IF NOT EXISTS idx_views_workspace_user ON views(workspace_id, user_id);
+
+		ALTER TABLE undo_actions ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+		DELETE FROM undo_actions WHERE workspace_id IS NULL;
+		ALTER TABLE undo_actions ALTER COLUMN workspace_id SET NOT NULL;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to scope views and undo tokens: %w", err)
+	}
+
+	// Wrong two-factor codes counted per user across challenges
+	_, err = db.Exec(`
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_failures INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_locked_until TIMESTAMP;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add two-factor lockout: %w", err)
+	}
+
+	// Sync positions are transaction IDs below which every writer has
+	// finished, since sequence numbers are drawn before commit
+	_, err = db.Exec(`
+		ALTER TABLE todos ADD COLUMN IF NOT EXISTS sync_xid xid8 NOT NULL DEFAULT pg_current_xact_id();
+		CREATE INDEX IF NOT EXISTS idx_todos_user_sync_xid ON todos(user_id, sync_xid);
+
+		ALTER TABLE todo_tombstones ADD COLUMN IF NOT EXISTS sync_xid xid8 NOT NULL DEFAULT pg_current_xact_id();
+		CREATE INDEX IF NOT EXISTS idx_todo_tombstones_user_sync_xid ON todo_tombstones(user_id, sync_xid);
+
+		CREATE OR REPLACE FUNCTION todos_bump_sync_seq() RETURNS trigger AS $$
+		BEGIN
+			NEW.sync_seq := nextval('todo_sync_seq');
+			NEW.sync_xid := pg_current_xact_id();
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS todos_bump_sync_seq ON todos;
+		CREATE TRIGGER todos_bump_sync_seq
+			BEFORE INSERT OR UPDATE ON todos
+			FOR EACH ROW EXECUTE FUNCTION todos_bump_sync_seq();
+
+		CREATE OR REPLACE FUNCTION todos_record_tombstone() RETURNS trigger AS $$
+		BEGIN
+			INSERT INTO todo_tombstones (todo_id, user_id, ical_uid, workspace_id)
+			VALUES (OLD.id, OLD.user_id, OLD.ical_uid, OLD.workspace_id)
+			ON CONFLICT (todo_id) DO UPDATE
+				SET deleted_at = NOW(), sync_seq = nextval('todo_sync_seq'), sync_xid = pg_current_xact_id(),
+					workspace_id = EXCLUDED.workspace_id;
+			RETURN OLD;
+		END;
+		$$ LANGUAGE plpgsql;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add sync transaction ids: %w", err)
+	}
+
+	// Todo events belong to the workspace of their todo, so that streams
+	// only replay the workspace they were opened in
+	_, err = db.Exec(`
+		ALTER TABLE todo_events ADD COLUMN IF NOT EXISTS workspace_id UUID;
+		UPDATE todo_events SET workspace_id = (payload->'todo'->>'workspace_id')::uuid
+		WHERE workspace_id IS NULL AND payload->'todo'->>'workspace_id' IS NOT NULL;
+		DELETE FROM todo_events WHERE workspace_id IS NULL;
+		ALTER TABLE todo_events ALTER COLUMN workspace_id SET NOT NULL;
+
+		CREATE INDEX IF NOT EXISTS idx_todo_events_user_workspace ON todo_events(user_id, workspace_id, id);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to scope todo events: %w", err)
+	}
+
 	return nil
 }
 
@@ -93,3 +684,20 @@ func (db *DB) Close() error {
 	log.Info().Msg("Closing database connection")
 	return db.DB.Close()
 }
+
+// NewListener opens a dedicated connection that receives NOTIFY messages on
+// the given channel, reconnecting automatically when the connection drops.
+func (db *DB) NewListener(channel string) (*pq.Listener, error) {
+	listener := pq.NewListener(db.dsn, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Warn().Err(err).Str("channel", channel).Msg("Database listener error")
+		}
+	})
+
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", channel, err)
+	}
+
+	return listener, nil
+}
@@ -0,0 +1,154 @@
+package graph
+
+import (
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// defaultListSize is the assumed length of list fields without a "first"
+// argument.
+const defaultListSize = 10
+
+// complexity estimates the cost of an operation: every field costs one, and
+// the selections under a list field count once per expected element.
+func complexity(schema *graphql.Schema, doc *ast.Document, op *ast.OperationDefinition, vars map[string]interface{}) int {
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok {
+			fragments[frag.Name.Value] = frag
+		}
+	}
+
+	c := &complexityCounter{schema: schema, fragments: fragments, vars: vars}
+	return c.selectionSet(rootType(schema, op), op.SelectionSet)
+}
+
+func rootType(schema *graphql.Schema, op *ast.OperationDefinition) *graphql.Object {
+	switch op.Operation {
+	case ast.OperationTypeMutation:
+		return schema.MutationType()
+	case ast.OperationTypeSubscription:
+		return schema.SubscriptionType()
+	default:
+		return schema.QueryType()
+	}
+}
+
+type complexityCounter struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	vars      map[string]interface{}
+}
+
+func (c *complexityCounter) selectionSet(parent graphql.Type, set *ast.SelectionSet) int {
+	if set == nil {
+		return 0
+	}
+
+	var fields graphql.FieldDefinitionMap
+	switch t := parent.(type) {
+	case *graphql.Object:
+		fields = t.Fields()
+	case *graphql.Interface:
+		fields = t.Fields()
+	}
+
+	total := 0
+	for _, sel := range set.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			total += c.field(fields, sel)
+		case *ast.InlineFragment:
+			total += c.selectionSet(c.typeCondition(parent, sel.TypeCondition), sel.SelectionSet)
+		case *ast.FragmentSpread:
+			if frag, ok := c.fragments[sel.Name.Value]; ok {
+				total += c.selectionSet(c.typeCondition(parent, frag.TypeCondition), frag.SelectionSet)
+			}
+		}
+	}
+	return total
+}
+
+func (c *complexityCounter) field(fields graphql.FieldDefinitionMap, field *ast.Field) int {
+	def, ok := fields[field.Name.Value]
+	if !ok {
+		// __typename and introspection fields.
+		return 1
+	}
+
+	multiplier := 1
+	fieldType := def.Type
+	if nonNull, ok := fieldType.(*graphql.NonNull); ok {
+		fieldType = nonNull.OfType
+	}
+	if _, ok := fieldType.(*graphql.List); ok {
+		multiplier = c.listSize(def, field)
+	}
+
+	return 1 + multiplier*c.selectionSet(namedType(def.Type), field.SelectionSet)
+}
+
+// listSize returns the "first" argument of a list field, falling back to its
+// default and then to defaultListSize. It is clamped to [0, maxPageSize] so
+// that out-of-range values, which the resolvers reject anyway, cannot lower
+// the total of their sibling fields.
+func (c *complexityCounter) listSize(def *graphql.FieldDefinition, field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.ParseFloat(v.Value, 64); err == nil {
+				return clampListSize(n)
+			}
+		case *ast.Variable:
+			switch n := c.vars[v.Name.Value].(type) {
+			case float64:
+				return clampListSize(n)
+			case int:
+				return clampListSize(float64(n))
+			}
+		}
+	}
+	for _, arg := range def.Args {
+		if arg.Name() == "first" {
+			if n, ok := arg.DefaultValue.(int); ok {
+				return clampListSize(float64(n))
+			}
+		}
+	}
+	return defaultListSize
+}
+
+func clampListSize(n float64) int {
+	switch {
+	case n < 0:
+		return 0
+	case n > maxPageSize:
+		return maxPageSize
+	}
+	return int(n)
+}
+
+func (c *complexityCounter) typeCondition(parent graphql.Type, cond *ast.Named) graphql.Type {
+	if cond == nil {
+		return parent
+	}
+	return c.schema.Type(cond.Name.Value)
+}
+
+func namedType(t graphql.Type) graphql.Type {
+	for {
+		switch wrapped := t.(type) {
+		case *graphql.NonNull:
+			t = wrapped.OfType
+		case *graphql.List:
+			t = wrapped.OfType
+		default:
+			return t
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	s, err := NewServer(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestComplexity(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		name  string
+		query string
+		vars  map[string]interface{}
+		want  int
+	}{
+		{name: "scalar fields", query: `{ me { id name } }`, want: 3},
+		{name: "list with default page size", query: `{ todos { id } }`, want: 1 + defaultPageSize},
+		{name: "list with first", query: `{ todos(first: 5) { id title } }`, want: 11},
+		{name: "first of zero", query: `{ todos(first: 0) { id title } }`, want: 1},
+		{name: "negative first is clamped", query: `{ todos(first: -100) { id } me { id } }`, want: 3},
+		{name: "huge first is clamped", query: `{ todos(first: 100000) { id } }`, want: 1 + maxPageSize},
+		{name: "first from a variable", query: `query($n: Int) { todos(first: $n) { id } }`, vars: map[string]interface{}{"n": float64(20)}, want: 21},
+		{name: "negative variable is clamped", query: `query($n: Int) { todos(first: $n) { id } }`, vars: map[string]interface{}{"n": float64(-5000)}, want: 1},
+		{name: "missing variable", query: `query($n: Int) { todos(first: $n) { id } }`, want: 1 + defaultPageSize},
+		{name: "nested objects", query: `{ todos(first: 10) { id tags owner { id name } } }`, want: 51},
+		{name: "fragment spread", query: `query { todos(first: 2) { ...fields } } fragment fields on Todo { id title }`, want: 5},
+		{name: "inline fragment and typename", query: `{ todos(first: 3) { __typename ... on Todo { id } } }`, want: 7},
+		{name: "mutation", query: `mutation { deleteTodo(id: "1") }`, want: 1},
+		{name: "subscription", query: `subscription { todoChanged { id todo { id } } }`, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(tt.query)})})
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			op := findOperation(doc, "")
+			if op == nil {
+				t.Fatal("no operation")
+			}
+			if got := complexity(&s.schema, doc, op, tt.vars); got != tt.want {
+				t.Errorf("complexity = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareComplexityLimit(t *testing.T) {
+	s := newTestServer(t)
+
+	// 1 + 100 * 24 per list
+	const todoFields = `id workspaceId title description completed status priority tags dueDate completedAt
+		archivedAt createdAt updatedAt version owner { id name email createdAt } assignee { id name email createdAt }`
+	list := func(alias, first string) string {
+		return alias + ": todos(first: " + first + ") { " + todoFields + " }"
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "one full page", query: "{ " + list("a", "100") + " }"},
+		{name: "two full pages", query: "{ " + list("a", "100") + " " + list("b", "100") + " }", wantErr: true},
+		{name: "negative sibling does not offset", query: "{ " + list("a", "100") + " " + list("b", "100") + " " + list("c", "-1000000") + " }", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, result := s.Prepare(Request{Query: tt.query})
+			if !tt.wantErr {
+				if result != nil {
+					t.Errorf("Prepare failed: %v", result.Errors)
+				}
+				return
+			}
+			if result == nil || len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Message, "exceeds the limit") {
+				t.Errorf("Prepare = %v, want a complexity error", result)
+			}
+		})
+	}
+}
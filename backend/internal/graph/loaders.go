@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/pkg/dataloader"
+)
+
+type loadersKey struct{}
+
+// loaders batch the lookups of one operation.
+type loaders struct {
+	users *dataloader.Loader[uuid.UUID, *models.User]
+	todos *dataloader.Loader[uuid.UUID, *models.Todo]
+}
+
+func (r *resolver) newLoaders(userID uuid.UUID) *loaders {
+	return &loaders{
+		users: dataloader.New(func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.User, error) {
+			users, err := r.authService.GetUsersByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[uuid.UUID]*models.User, len(users))
+			for _, u := range users {
+				byID[u.ID] = u
+			}
+			return byID, nil
+		}),
+		todos: dataloader.New(func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Todo, error) {
+			todos, err := r.todoService.GetByIDs(ctx, ids, userID)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[uuid.UUID]*models.Todo, len(todos))
+			for _, t := range todos {
+				byID[t.ID] = t
+			}
+			return byID, nil
+		}),
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,378 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+var errInternal = errors.New("internal server error")
+
+type resolver struct {
+	todoService  *service.TodoService
+	authService  *service.AuthService
+	eventService *service.EventService
+	validator    *validator.Validate
+}
+
+func userIDFrom(ctx context.Context) uuid.UUID {
+	return ctx.Value(middleware.UserIDKey).(uuid.UUID)
+}
+
+// publicError hides unexpected errors from clients.
+func publicError(err error) error {
+	if err.Error() == "todo not found" || errors.Is(err, service.ErrNotTodoOwner) || errors.Is(err, service.ErrPermissionDenied) {
+		return err
+	}
+	log.Error().Err(err).Msg("GraphQL resolver failed")
+	return errInternal
+}
+
+func parseID(p graphql.ResolveParams) (uuid.UUID, error) {
+	id, err := uuid.Parse(fmt.Sprint(p.Args["id"]))
+	if err != nil {
+		return uuid.Nil, errors.New("invalid todo id")
+	}
+	return id, nil
+}
+
+func stringList(v interface{}) []string {
+	items, _ := v.([]interface{})
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		list = append(list, item.(string))
+	}
+	return list
+}
+
+func (r *resolver) me(p graphql.ResolveParams) (interface{}, error) {
+	thunk := loadersFrom(p.Context).users.Load(p.Context, userIDFrom(p.Context))
+	return func() (interface{}, error) {
+		user, err := thunk()
+		if err != nil {
+			return nil, publicError(err)
+		}
+		return user, nil
+	}, nil
+}
+
+func (r *resolver) todo(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p)
+	if err != nil {
+		return nil, err
+	}
+	thunk := loadersFrom(p.Context).todos.Load(p.Context, id)
+	return func() (interface{}, error) {
+		todo, err := thunk()
+		if err != nil {
+			return nil, publicError(err)
+		}
+		if todo == nil {
+			return nil, nil
+		}
+		return todo, nil
+	}, nil
+}
+
+func (r *resolver) todos(p graphql.ResolveParams) (interface{}, error) {
+	first, _ := p.Args["first"].(int)
+	offset, _ := p.Args["offset"].(int)
+	if first < 0 || first > maxPageSize {
+		return nil, fmt.Errorf("first must be between 0 and %d", maxPageSize)
+	}
+	if offset < 0 {
+		return nil, errors.New("offset must not be negative")
+	}
+
+	var filters models.TodoFilters
+	if status, ok := p.Args["status"].(models.TodoStatus); ok {
+		filters.Status = &status
+	}
+	if priority, ok := p.Args["priority"].(models.TodoPriority); ok {
+		filters.Priority = &priority
+	}
+	if search, ok := p.Args["search"].(string); ok {
+		filters.Search = &search
+	}
+	if tags, ok := p.Args["tags"]; ok {
+		filters.Tags = stringList(tags)
+	}
+
+	todos, err := r.todoService.GetAll(p.Context, userIDFrom(p.Context), filters)
+	if err != nil {
+		return nil, publicError(err)
+	}
+
+	if offset >= len(todos) {
+		return []*models.Todo{}, nil
+	}
+	todos = todos[offset:]
+	if len(todos) > first {
+		todos = todos[:first]
+	}
+
+	// Prime the loader so that todo(id:) in the same operation is free.
+	loaders := loadersFrom(p.Context)
+	for _, todo := range todos {
+		loaders.todos.Prime(todo.ID, todo)
+	}
+	return todos, nil
+}
+
+type tagCount struct {
+	Name      string `json:"name"`
+	TodoCount int    `json:"todoCount"`
+}
+
+func (r *resolver) tags(p graphql.ResolveParams) (interface{}, error) {
+	todos, err := r.todoService.GetAll(p.Context, userIDFrom(p.Context), models.TodoFilters{})
+	if err != nil {
+		return nil, publicError(err)
+	}
+
+	counts := map[string]int{}
+	for _, todo := range todos {
+		for _, tag := range todo.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]tagCount, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, tagCount{Name: name, TodoCount: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].TodoCount != tags[j].TodoCount {
+			return tags[i].TodoCount > tags[j].TodoCount
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+type todoStats struct {
+	Total          int     `json:"total"`
+	Completed      int     `json:"completed"`
+	Pending        int     `json:"pending"`
+	Overdue        int     `json:"overdue"`
+	CompletionRate float64 `json:"completionRate"`
+}
+
+func (r *resolver) stats(p graphql.ResolveParams) (interface{}, error) {
+	todos, err := r.todoService.GetAll(p.Context, userIDFrom(p.Context), models.TodoFilters{})
+	if err != nil {
+		return nil, publicError(err)
+	}
+
+	now := time.Now()
+	stats := todoStats{Total: len(todos)}
+	for _, todo := range todos {
+		if todo.Completed {
+			stats.Completed++
+			continue
+		}
+		stats.Pending++
+		if todo.DueDate != nil && todo.DueDate.Before(now) {
+			stats.Overdue++
+		}
+	}
+	if stats.Total > 0 {
+		stats.CompletionRate = float64(stats.Completed) / float64(stats.Total)
+	}
+	return stats, nil
+}
+
+func (r *resolver) todoTags(p graphql.ResolveParams) (interface{}, error) {
+	todo := p.Source.(*models.Todo)
+	if todo.Tags == nil {
+		return []string{}, nil
+	}
+	return []string(todo.Tags), nil
+}
+
+func (r *resolver) todoOwner(p graphql.ResolveParams) (interface{}, error) {
+	todo := p.Source.(*models.Todo)
+	thunk := loadersFrom(p.Context).users.Load(p.Context, todo.UserID)
+	return func() (interface{}, error) {
+		user, err := thunk()
+		if err != nil {
+			return nil, publicError(err)
+		}
+		return user, nil
+	}, nil
+}
+
+func (r *resolver) todoAssignee(p graphql.ResolveParams) (interface{}, error) {
+	todo := p.Source.(*models.Todo)
+	if todo.AssigneeID == nil {
+		return nil, nil
+	}
+	thunk := loadersFrom(p.Context).users.Load(p.Context, *todo.AssigneeID)
+	return func() (interface{}, error) {
+		user, err := thunk()
+		if err != nil {
+			return nil, publicError(err)
+		}
+		return user, nil
+	}, nil
+}
+
+func (r *resolver) createTodo(p graphql.ResolveParams) (interface{}, error) {
+	input := p.Args["input"].(map[string]interface{})
+
+	req := models.CreateTodoRequest{}
+	req.Title, _ = input["title"].(string)
+	if description, ok := input["description"].(string); ok {
+		req.Description = &description
+	}
+	if priority, ok := input["priority"].(models.TodoPriority); ok {
+		req.Priority = &priority
+	}
+	if dueDate, ok := input["dueDate"].(time.Time); ok {
+		req.DueDate = &dueDate
+	}
+	if tags, ok := input["tags"]; ok {
+		req.Tags = stringList(tags)
+	}
+
+	if err := r.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+
+	todo, err := r.todoService.Create(p.Context, req, userIDFrom(p.Context))
+	if err != nil {
+		return nil, publicError(err)
+	}
+	return todo, nil
+}
+
+func (r *resolver) updateTodo(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p)
+	if err != nil {
+		return nil, err
+	}
+	input := p.Args["input"].(map[string]interface{})
+
+	req := models.UpdateTodoRequest{}
+	if title, ok := input["title"].(string); ok {
+		req.Title = &title
+	}
+	if description, ok := input["description"].(string); ok {
+		req.Description = &description
+	}
+	if priority, ok := input["priority"].(models.TodoPriority); ok {
+		req.Priority = &priority
+	}
+	if dueDate, ok := input["dueDate"].(time.Time); ok {
+		req.DueDate = &dueDate
+	}
+	if tags, ok := input["tags"]; ok {
+		req.Tags = stringList(tags)
+	}
+
+	if err := r.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+
+	todo, err := r.todoService.Update(p.Context, id, req, userIDFrom(p.Context))
+	if err != nil {
+		return nil, publicError(err)
+	}
+	return todo, nil
+}
+
+func (r *resolver) completeTodo(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := r.todoService.MarkAsCompleted(p.Context, id, userIDFrom(p.Context))
+	if err != nil {
+		return nil, publicError(err)
+	}
+	return todo, nil
+}
+
+func (r *resolver) uncompleteTodo(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := r.todoService.MarkAsIncomplete(p.Context, id, userIDFrom(p.Context))
+	if err != nil {
+		return nil, publicError(err)
+	}
+	return todo, nil
+}
+
+func (r *resolver) deleteTodo(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.todoService.Delete(p.Context, id, userIDFrom(p.Context)); err != nil {
+		return nil, publicError(err)
+	}
+	return id.String(), nil
+}
+
+// subscribeTodoChanged streams the user's todo events in the request's
+// workspace until the operation's context is cancelled or the event stream
+// falls behind.
+func (r *resolver) subscribeTodoChanged(p graphql.ResolveParams) (interface{}, error) {
+	wanted := map[service.TodoEventType]bool{}
+	if types, ok := p.Args["types"].([]interface{}); ok {
+		for _, t := range types {
+			wanted[t.(service.TodoEventType)] = true
+		}
+	}
+
+	sub := r.eventService.Subscribe(p.Context, userIDFrom(p.Context))
+	events := make(chan interface{})
+
+	go func() {
+		defer close(events)
+		defer r.eventService.Unsubscribe(sub)
+
+		for {
+			select {
+			case <-p.Context.Done():
+				return
+			case stored, ok := <-sub.Events:
+				if !ok {
+					return
+				}
+				var event service.TodoEvent
+				if err := json.Unmarshal(stored.Payload, &event); err != nil {
+					continue
+				}
+				if len(wanted) > 0 && !wanted[event.Type] {
+					continue
+				}
+				select {
+				case events <- &event:
+				case <-p.Context.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
@@ -0,0 +1,228 @@
+package graph
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+)
+
+var todoStatusEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "TodoStatus",
+	Values: graphql.EnumValueConfigMap{
+		"PENDING":   &graphql.EnumValueConfig{Value: models.StatusPending},
+		"COMPLETED": &graphql.EnumValueConfig{Value: models.StatusCompleted},
+	},
+})
+
+var todoPriorityEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "TodoPriority",
+	Values: graphql.EnumValueConfigMap{
+		"LOW":    &graphql.EnumValueConfig{Value: models.PriorityLow},
+		"MEDIUM": &graphql.EnumValueConfig{Value: models.PriorityMedium},
+		"HIGH":   &graphql.EnumValueConfig{Value: models.PriorityHigh},
+	},
+})
+
+var todoEventTypeEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "TodoEventType",
+	Values: graphql.EnumValueConfigMap{
+		"CREATED":   &graphql.EnumValueConfig{Value: service.EventTodoCreated},
+		"UPDATED":   &graphql.EnumValueConfig{Value: service.EventTodoUpdated},
+		"COMPLETED": &graphql.EnumValueConfig{Value: service.EventTodoCompleted},
+		"DELETED":   &graphql.EnumValueConfig{Value: service.EventTodoDeleted},
+		"ASSIGNED":  &graphql.EnumValueConfig{Value: service.EventTodoAssigned},
+	},
+})
+
+// newSchema builds the schema. Struct fields are resolved by graphql-go's
+// default resolver, which matches field names case-insensitively.
+func newSchema(r *resolver) (graphql.Schema, error) {
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"email":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	todoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Todo",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"workspaceId": &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"title":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.Field{Type: graphql.String},
+			"completed":   &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"status":      &graphql.Field{Type: graphql.NewNonNull(todoStatusEnum)},
+			"priority":    &graphql.Field{Type: graphql.NewNonNull(todoPriorityEnum)},
+			"tags": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Resolve: r.todoTags,
+			},
+			"dueDate":     &graphql.Field{Type: graphql.DateTime},
+			"completedAt": &graphql.Field{Type: graphql.DateTime},
+			"archivedAt":  &graphql.Field{Type: graphql.DateTime},
+			"createdAt":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"updatedAt":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"version":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"owner": &graphql.Field{
+				Type:    graphql.NewNonNull(userType),
+				Resolve: r.todoOwner,
+			},
+			"assignee": &graphql.Field{
+				Type:    userType,
+				Resolve: r.todoAssignee,
+			},
+		},
+	})
+
+	tagType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Tag",
+		Fields: graphql.Fields{
+			"name":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"todoCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	statsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoStats",
+		Fields: graphql.Fields{
+			"total":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"completed":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"pending":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"overdue":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"completionRate": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		},
+	})
+
+	todoEventType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoEvent",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"type":       &graphql.Field{Type: graphql.NewNonNull(todoEventTypeEnum)},
+			"todo":       &graphql.Field{Type: graphql.NewNonNull(todoType)},
+			"occurredAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	createTodoInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CreateTodoInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"priority":    &graphql.InputObjectFieldConfig{Type: todoPriorityEnum},
+			"dueDate":     &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"tags":        &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+		},
+	})
+
+	updateTodoInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "UpdateTodoInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":       &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"description": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"priority":    &graphql.InputObjectFieldConfig{Type: todoPriorityEnum},
+			"dueDate":     &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"tags":        &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+		},
+	})
+
+	idArg := graphql.FieldConfigArgument{
+		"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"me": &graphql.Field{
+				Type:    graphql.NewNonNull(userType),
+				Resolve: r.me,
+			},
+			"todo": &graphql.Field{
+				Type:    todoType,
+				Args:    idArg,
+				Resolve: r.todo,
+			},
+			"todos": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(todoType))),
+				Args: graphql.FieldConfigArgument{
+					"status":   &graphql.ArgumentConfig{Type: todoStatusEnum},
+					"priority": &graphql.ArgumentConfig{Type: todoPriorityEnum},
+					"search":   &graphql.ArgumentConfig{Type: graphql.String},
+					"tags":     &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+					"first":    &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+					"offset":   &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: r.todos,
+			},
+			"tags": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(tagType))),
+				Resolve: r.tags,
+			},
+			"stats": &graphql.Field{
+				Type:    graphql.NewNonNull(statsType),
+				Resolve: r.stats,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(createTodoInput)},
+				},
+				Resolve: r.createTodo,
+			},
+			"updateTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(updateTodoInput)},
+				},
+				Resolve: r.updateTodo,
+			},
+			"completeTodo": &graphql.Field{
+				Type:    graphql.NewNonNull(todoType),
+				Args:    idArg,
+				Resolve: r.completeTodo,
+			},
+			"uncompleteTodo": &graphql.Field{
+				Type:    graphql.NewNonNull(todoType),
+				Args:    idArg,
+				Resolve: r.uncompleteTodo,
+			},
+			"deleteTodo": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.ID),
+				Args:    idArg,
+				Resolve: r.deleteTodo,
+			},
+		},
+	})
+
+	subscription := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"todoChanged": &graphql.Field{
+				Type: graphql.NewNonNull(todoEventType),
+				Args: graphql.FieldConfigArgument{
+					"types": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(todoEventTypeEnum))},
+				},
+				Subscribe: r.subscribeTodoChanged,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:        query,
+		Mutation:     mutation,
+		Subscription: subscription,
+	})
+}
@@ -0,0 +1,152 @@
+// Package graph serves the GraphQL API on top of the application services.
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/yourusername/todogo-backend/internal/service"
+)
+
+// MaxComplexity is the highest complexity an operation may have.
+const MaxComplexity = 3000
+
+// Request is a GraphQL request as sent over HTTP or WebSocket.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Operation is a parsed and validated request.
+type Operation struct {
+	req  Request
+	doc  *ast.Document
+	kind string
+}
+
+// Kind returns "query", "mutation" or "subscription".
+func (o *Operation) Kind() string {
+	return o.kind
+}
+
+type Server struct {
+	schema   graphql.Schema
+	resolver *resolver
+}
+
+func NewServer(todoService *service.TodoService, authService *service.AuthService, eventService *service.EventService) (*Server, error) {
+	r := &resolver{
+		todoService:  todoService,
+		authService:  authService,
+		eventService: eventService,
+		validator:    validator.New(),
+	}
+
+	schema, err := newSchema(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build GraphQL schema: %w", err)
+	}
+
+	return &Server{schema: schema, resolver: r}, nil
+}
+
+// Prepare parses and validates a request and enforces the complexity limit.
+// On failure it returns a result carrying the errors.
+func (s *Server) Prepare(req Request) (*Operation, *graphql.Result) {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return nil, &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	validation := graphql.ValidateDocument(&s.schema, doc, nil)
+	if !validation.IsValid {
+		return nil, &graphql.Result{Errors: validation.Errors}
+	}
+
+	op := findOperation(doc, req.OperationName)
+	if op == nil {
+		return nil, errorResult(fmt.Errorf("unknown operation %q", req.OperationName))
+	}
+
+	if cost := complexity(&s.schema, doc, op, req.Variables); cost > MaxComplexity {
+		return nil, errorResult(fmt.Errorf("operation complexity %d exceeds the limit of %d", cost, MaxComplexity))
+	}
+
+	return &Operation{req: req, doc: doc, kind: op.Operation}, nil
+}
+
+// Execute runs a query or mutation.
+func (s *Server) Execute(ctx context.Context, op *Operation) *graphql.Result {
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           op.doc,
+		OperationName: op.req.OperationName,
+		Args:          op.req.Variables,
+		Context:       withLoaders(ctx, s.resolver.newLoaders(userIDFrom(ctx))),
+	})
+}
+
+// Subscribe runs a subscription. The returned channel is closed when the
+// subscription ends or ctx is cancelled.
+func (s *Server) Subscribe(ctx context.Context, op *Operation) <-chan *graphql.Result {
+	results := graphql.ExecuteSubscription(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           op.doc,
+		OperationName: op.req.OperationName,
+		Args:          op.req.Variables,
+		Context:       withLoaders(ctx, s.resolver.newLoaders(userIDFrom(ctx))),
+	})
+
+	out := make(chan *graphql.Result)
+	go func() {
+		defer close(out)
+		// graphql-go blocks on an unbuffered channel, so keep draining it
+		// after the consumer has gone away.
+		defer func() {
+			for range results {
+			}
+		}()
+
+		for result := range results {
+			select {
+			case out <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func findOperation(doc *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if found != nil {
+				// Several operations require a name.
+				return nil
+			}
+			found = op
+		} else if op.Name != nil && op.Name.Value == name {
+			return op
+		}
+	}
+	return found
+}
+
+func errorResult(err error) *graphql.Result {
+	return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+}
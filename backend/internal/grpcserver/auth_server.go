@@ -0,0 +1,79 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	todogov1 "github.com/yourusername/todogo-backend/pkg/pb/todogo/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type authServer struct {
+	todogov1.UnimplementedAuthServiceServer
+
+	authService *service.AuthService
+	validator   *validator.Validate
+}
+
+func (s *authServer) Register(ctx context.Context, in *todogov1.RegisterRequest) (*todogov1.AuthResponse, error) {
+	req := models.RegisterRequest{
+		Name:     in.GetName(),
+		Email:    in.GetEmail(),
+		Password: in.GetPassword(),
+	}
+	if err := s.validator.Struct(req); err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp, err := s.authService.Register(ctx, req)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return authResponseToProto(resp), nil
+}
+
+func (s *authServer) Login(ctx context.Context, in *todogov1.LoginRequest) (*todogov1.AuthResponse, error) {
+	req := models.LoginRequest{
+		Email:    in.GetEmail(),
+		Password: in.GetPassword(),
+	}
+	if err := s.validator.Struct(req); err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp, err := s.authService.Login(ctx, req)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if resp.MFARequired {
+		// The response has no field for the challenge; sign in over HTTP and
+		// use the token here.
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication required, sign in over the HTTP API")
+	}
+	return authResponseToProto(resp), nil
+}
+
+func (s *authServer) Refresh(ctx context.Context, in *todogov1.RefreshRequest) (*todogov1.AuthResponse, error) {
+	req := models.RefreshRequest{RefreshToken: in.GetRefreshToken()}
+	if err := s.validator.Struct(req); err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp, err := s.authService.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return authResponseToProto(resp), nil
+}
+
+func (s *authServer) GetCurrentUser(ctx context.Context, _ *emptypb.Empty) (*todogov1.User, error) {
+	user, err := s.authService.GetUser(ctx, userIDFrom(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return userToProto(user), nil
+}
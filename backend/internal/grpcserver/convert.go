@@ -0,0 +1,125 @@
+package grpcserver
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	todogov1 "github.com/yourusername/todogo-backend/pkg/pb/todogo/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	statusToProto = map[models.TodoStatus]todogov1.TodoStatus{
+		models.StatusPending:   todogov1.TodoStatus_TODO_STATUS_PENDING,
+		models.StatusCompleted: todogov1.TodoStatus_TODO_STATUS_COMPLETED,
+	}
+	priorityToProto = map[models.TodoPriority]todogov1.TodoPriority{
+		models.PriorityLow:    todogov1.TodoPriority_TODO_PRIORITY_LOW,
+		models.PriorityMedium: todogov1.TodoPriority_TODO_PRIORITY_MEDIUM,
+		models.PriorityHigh:   todogov1.TodoPriority_TODO_PRIORITY_HIGH,
+	}
+	eventTypeToProto = map[service.TodoEventType]todogov1.TodoEventType{
+		service.EventTodoCreated:   todogov1.TodoEventType_TODO_EVENT_TYPE_CREATED,
+		service.EventTodoUpdated:   todogov1.TodoEventType_TODO_EVENT_TYPE_UPDATED,
+		service.EventTodoCompleted: todogov1.TodoEventType_TODO_EVENT_TYPE_COMPLETED,
+		service.EventTodoDeleted:   todogov1.TodoEventType_TODO_EVENT_TYPE_DELETED,
+		// The proto has no assignment type; the todo's assignee changed
+		service.EventTodoAssigned: todogov1.TodoEventType_TODO_EVENT_TYPE_UPDATED,
+	}
+)
+
+func parseID(id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "invalid todo id")
+	}
+	return parsed, nil
+}
+
+// priorityFromProto returns nil for the unspecified value so the service
+// applies its default or leaves the field unchanged.
+func priorityFromProto(p todogov1.TodoPriority) (*models.TodoPriority, error) {
+	if p == todogov1.TodoPriority_TODO_PRIORITY_UNSPECIFIED {
+		return nil, nil
+	}
+	for priority, value := range priorityToProto {
+		if value == p {
+			return &priority, nil
+		}
+	}
+	return nil, status.Error(codes.InvalidArgument, "invalid priority")
+}
+
+func statusFromProto(s todogov1.TodoStatus) (*models.TodoStatus, error) {
+	if s == todogov1.TodoStatus_TODO_STATUS_UNSPECIFIED {
+		return nil, nil
+	}
+	for todoStatus, value := range statusToProto {
+		if value == s {
+			return &todoStatus, nil
+		}
+	}
+	return nil, status.Error(codes.InvalidArgument, "invalid status")
+}
+
+func timeFromProto(ts *timestamppb.Timestamp) (*time.Time, error) {
+	if ts == nil {
+		return nil, nil
+	}
+	if err := ts.CheckValid(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid timestamp")
+	}
+	t := ts.AsTime()
+	return &t, nil
+}
+
+func timeToProto(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func todoToProto(todo *models.Todo) *todogov1.Todo {
+	tags := todo.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return &todogov1.Todo{
+		Id:          todo.ID.String(),
+		Title:       todo.Title,
+		Description: todo.Description,
+		Completed:   todo.Completed,
+		Status:      statusToProto[todo.Status],
+		Priority:    priorityToProto[todo.Priority],
+		UserId:      todo.UserID.String(),
+		CreatedAt:   timestamppb.New(todo.CreatedAt),
+		UpdatedAt:   timestamppb.New(todo.UpdatedAt),
+		CompletedAt: timeToProto(todo.CompletedAt),
+		DueDate:     timeToProto(todo.DueDate),
+		Tags:        tags,
+		Version:     int32(todo.Version),
+	}
+}
+
+func authResponseToProto(resp *models.LoginResponse) *todogov1.AuthResponse {
+	return &todogov1.AuthResponse{
+		Token:        resp.Token,
+		User:         userToProto(&resp.User),
+		RefreshToken: resp.RefreshToken,
+		ExpiresAt:    timeToProto(resp.ExpiresAt),
+	}
+}
+
+func userToProto(user *models.User) *todogov1.User {
+	return &todogov1.User{
+		Id:        user.ID.String(),
+		Name:      user.Name,
+		Email:     user.Email,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+	}
+}
@@ -0,0 +1,191 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/internal/tenant"
+	todogov1 "github.com/yourusername/todogo-backend/pkg/pb/todogo/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods can be called without a token.
+var publicMethods = map[string]bool{
+	todogov1.AuthService_Register_FullMethodName: true,
+	todogov1.AuthService_Login_FullMethodName:    true,
+	todogov1.AuthService_Refresh_FullMethodName:  true,
+}
+
+// NewServer exposes the todo and auth services over gRPC, together with the
+// standard health and reflection services.
+func NewServer(todoService *service.TodoService, authService *service.AuthService, eventService *service.EventService, workspaceService *service.WorkspaceService) *grpc.Server {
+	a := &authenticator{authService: authService, workspaceService: workspaceService}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(recoverUnary, a.unary),
+		grpc.ChainStreamInterceptor(recoverStream, a.stream),
+	)
+
+	v := validator.New()
+	todogov1.RegisterTodoServiceServer(server, &todoServer{todoService: todoService, eventService: eventService, validator: v})
+	todogov1.RegisterAuthServiceServer(server, &authServer{authService: authService, validator: v})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(todogov1.TodoService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(todogov1.AuthService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	return server
+}
+
+type authenticator struct {
+	authService      *service.AuthService
+	workspaceService *service.WorkspaceService
+}
+
+func isPublic(method string) bool {
+	return publicMethods[method] ||
+		strings.HasPrefix(method, "/grpc.health.v1.") ||
+		strings.HasPrefix(method, "/grpc.reflection.")
+}
+
+// authenticate validates the bearer token in the call metadata and stores
+// the user and workspace in the context the same way the HTTP middleware
+// does. x-workspace-id metadata takes the place of the X-Workspace-ID header.
+func (a *authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata format")
+	}
+
+	claims, err := a.authService.ValidateSession(ctx, token)
+	if err != nil {
+		if errors.Is(err, service.ErrAccountDisabled) || errors.Is(err, service.ErrPasswordResetRequired) ||
+			errors.Is(err, service.ErrEmailNotVerified) || errors.Is(err, service.ErrSessionExpired) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+
+	ctx = context.WithValue(ctx, middleware.UserIDKey, claims.UserID)
+	ctx = context.WithValue(ctx, middleware.EmailKey, claims.Email)
+	ctx = context.WithValue(ctx, middleware.RoleKey, claims.Role)
+
+	requested := claims.WorkspaceID
+	if values := md.Get("x-workspace-id"); len(values) > 0 {
+		id, err := uuid.Parse(values[0])
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid x-workspace-id metadata")
+		}
+		requested = &id
+	}
+
+	workspaceID, err := a.workspaceService.Resolve(ctx, claims.UserID, requested)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidWorkspaceScope) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		log.Error().Err(err).Msg("Failed to resolve gRPC workspace")
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+	return tenant.WithWorkspace(ctx, workspaceID), nil
+}
+
+func (a *authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if isPublic(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if isPublic(info.FullMethod) {
+		return handler(srv, ss)
+	}
+	ctx, err := a.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func recoverUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Error().Interface("panic", rec).Str("method", info.FullMethod).Msg("Panic recovered")
+			err = status.Error(codes.Internal, "internal server error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func recoverStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Error().Interface("panic", rec).Str("method", info.FullMethod).Msg("Panic recovered")
+			err = status.Error(codes.Internal, "internal server error")
+		}
+	}()
+	return handler(srv, ss)
+}
+
+// toStatus maps service errors to gRPC codes and hides unexpected ones.
+func toStatus(err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+	}
+	if errors.Is(err, service.ErrPermissionDenied) || errors.Is(err, service.ErrAccountDisabled) ||
+		errors.Is(err, service.ErrPasswordResetRequired) || errors.Is(err, service.ErrEmailNotVerified) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if errors.Is(err, service.ErrInvalidRefreshToken) {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	switch err.Error() {
+	case "todo not found", "user not found":
+		return status.Error(codes.NotFound, err.Error())
+	case "only the todo's owner can do this":
+		return status.Error(codes.PermissionDenied, err.Error())
+	case "user already exists":
+		return status.Error(codes.AlreadyExists, err.Error())
+	case "invalid credentials":
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	log.Error().Err(err).Msg("gRPC call failed")
+	return status.Error(codes.Internal, "internal server error")
+}
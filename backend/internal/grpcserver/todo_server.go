@@ -0,0 +1,214 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	todogov1 "github.com/yourusername/todogo-backend/pkg/pb/todogo/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type todoServer struct {
+	todogov1.UnimplementedTodoServiceServer
+
+	todoService  *service.TodoService
+	eventService *service.EventService
+	validator    *validator.Validate
+}
+
+func userIDFrom(ctx context.Context) uuid.UUID {
+	return ctx.Value(middleware.UserIDKey).(uuid.UUID)
+}
+
+func (s *todoServer) CreateTodo(ctx context.Context, in *todogov1.CreateTodoRequest) (*todogov1.Todo, error) {
+	priority, err := priorityFromProto(in.GetPriority())
+	if err != nil {
+		return nil, err
+	}
+	dueDate, err := timeFromProto(in.GetDueDate())
+	if err != nil {
+		return nil, err
+	}
+
+	req := models.CreateTodoRequest{
+		Title:       in.GetTitle(),
+		Description: in.Description,
+		Priority:    priority,
+		DueDate:     dueDate,
+		Tags:        in.GetTags(),
+	}
+	if err := s.validator.Struct(req); err != nil {
+		return nil, toStatus(err)
+	}
+
+	todo, err := s.todoService.Create(ctx, req, userIDFrom(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return todoToProto(todo), nil
+}
+
+func (s *todoServer) GetTodo(ctx context.Context, in *todogov1.GetTodoRequest) (*todogov1.Todo, error) {
+	id, err := parseID(in.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.todoService.GetByID(ctx, id, userIDFrom(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return todoToProto(todo), nil
+}
+
+func (s *todoServer) ListTodos(ctx context.Context, in *todogov1.ListTodosRequest) (*todogov1.ListTodosResponse, error) {
+	todoStatus, err := statusFromProto(in.GetStatus())
+	if err != nil {
+		return nil, err
+	}
+	priority, err := priorityFromProto(in.GetPriority())
+	if err != nil {
+		return nil, err
+	}
+
+	filters := models.TodoFilters{
+		Status:   todoStatus,
+		Priority: priority,
+		Search:   in.Search,
+		Tags:     in.GetTags(),
+	}
+
+	todos, err := s.todoService.GetAll(ctx, userIDFrom(ctx), filters)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &todogov1.ListTodosResponse{Todos: make([]*todogov1.Todo, 0, len(todos))}
+	for _, todo := range todos {
+		resp.Todos = append(resp.Todos, todoToProto(todo))
+	}
+	return resp, nil
+}
+
+func (s *todoServer) UpdateTodo(ctx context.Context, in *todogov1.UpdateTodoRequest) (*todogov1.Todo, error) {
+	id, err := parseID(in.GetId())
+	if err != nil {
+		return nil, err
+	}
+	priority, err := priorityFromProto(in.GetPriority())
+	if err != nil {
+		return nil, err
+	}
+	dueDate, err := timeFromProto(in.GetDueDate())
+	if err != nil {
+		return nil, err
+	}
+
+	req := models.UpdateTodoRequest{
+		Title:       in.Title,
+		Description: in.Description,
+		Priority:    priority,
+		DueDate:     dueDate,
+	}
+	if in.GetTags() != nil {
+		req.Tags = in.GetTags().GetTags()
+		if req.Tags == nil {
+			req.Tags = []string{}
+		}
+	}
+	if err := s.validator.Struct(req); err != nil {
+		return nil, toStatus(err)
+	}
+
+	todo, err := s.todoService.Update(ctx, id, req, userIDFrom(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return todoToProto(todo), nil
+}
+
+func (s *todoServer) SetTodoCompleted(ctx context.Context, in *todogov1.SetTodoCompletedRequest) (*todogov1.Todo, error) {
+	id, err := parseID(in.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	var todo *models.Todo
+	if in.GetCompleted() {
+		todo, err = s.todoService.MarkAsCompleted(ctx, id, userIDFrom(ctx))
+	} else {
+		todo, err = s.todoService.MarkAsIncomplete(ctx, id, userIDFrom(ctx))
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return todoToProto(todo), nil
+}
+
+func (s *todoServer) DeleteTodo(ctx context.Context, in *todogov1.DeleteTodoRequest) (*emptypb.Empty, error) {
+	id, err := parseID(in.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.todoService.Delete(ctx, id, userIDFrom(ctx)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// WatchTodos forwards the user's todo events in the call's workspace until
+// the client cancels. When the subscriber falls behind the stream ends with
+// Unavailable and the client should reload and watch again.
+func (s *todoServer) WatchTodos(in *todogov1.WatchTodosRequest, stream grpc.ServerStreamingServer[todogov1.TodoEvent]) error {
+	ctx := stream.Context()
+
+	wanted := map[todogov1.TodoEventType]bool{}
+	for _, t := range in.GetTypes() {
+		wanted[t] = true
+	}
+
+	sub := s.eventService.Subscribe(ctx, userIDFrom(ctx))
+	defer s.eventService.Unsubscribe(sub)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case stored, ok := <-sub.Events:
+			if !ok {
+				return status.Error(codes.Unavailable, "event stream interrupted, watch again")
+			}
+
+			var event service.TodoEvent
+			if err := json.Unmarshal(stored.Payload, &event); err != nil {
+				continue
+			}
+			eventType := eventTypeToProto[event.Type]
+			if len(wanted) > 0 && !wanted[eventType] {
+				continue
+			}
+
+			msg := &todogov1.TodoEvent{
+				Id:         event.ID.String(),
+				Type:       eventType,
+				OccurredAt: timestamppb.New(event.OccurredAt),
+			}
+			if event.Todo != nil {
+				msg.Todo = todoToProto(event.Todo)
+			}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
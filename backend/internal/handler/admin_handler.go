@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+type AdminHandler struct {
+	adminService *service.AdminService
+}
+
+func NewAdminHandler(adminService *service.AdminService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+// adminError writes the response for errors shared by the admin endpoints
+// and reports whether it did.
+func adminError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		response.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrCannotTargetSelf):
+		response.Error(w, http.StatusConflict, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	filters, err := parseUserFilters(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users, err := h.adminService.List(r.Context(), filters)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to fetch users")
+		return
+	}
+
+	response.Success(w, http.StatusOK, users, "users fetched successfully")
+}
+
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	user, err := h.adminService.Get(r.Context(), id)
+	if err != nil {
+		if adminError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to fetch user")
+		return
+	}
+
+	response.Success(w, http.StatusOK, user, "user fetched successfully")
+}
+
+func (h *AdminHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	h.changeUser(w, r, h.adminService.Disable, "failed to disable user", "user disabled")
+}
+
+func (h *AdminHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	h.changeUser(w, r, h.adminService.Enable, "failed to enable user", "user enabled")
+}
+
+func (h *AdminHandler) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	h.changeUser(w, r, h.adminService.ForcePasswordReset, "failed to require password reset", "password reset required")
+}
+
+func (h *AdminHandler) changeUser(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, id, actorID uuid.UUID) (*models.User, error), failure, success string) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	user, err := change(r.Context(), id, userID)
+	if err != nil {
+		if adminError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, failure)
+		return
+	}
+
+	response.Success(w, http.StatusOK, user, success)
+}
+
+func (h *AdminHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	if err := h.adminService.Delete(r.Context(), id, userID); err != nil {
+		if adminError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to delete user")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "user deleted successfully")
+}
+
+// parseUserFilters reads the admin user listing filters from the query
+// string.
+func parseUserFilters(r *http.Request) (models.UserFilters, error) {
+	q := r.URL.Query()
+	filters := models.UserFilters{}
+
+	if search := q.Get("search"); search != "" {
+		filters.Search = &search
+	}
+
+	if role := q.Get("role"); role != "" {
+		rl := models.Role(role)
+		filters.Role = &rl
+	}
+
+	switch q.Get("status") {
+	case "":
+	case "active":
+		disabled := false
+		filters.Disabled = &disabled
+	case "disabled":
+		disabled := true
+		filters.Disabled = &disabled
+	default:
+		return filters, errors.New("status must be active or disabled")
+	}
+
+	for name, dest := range map[string]*int{"limit": &filters.Limit, "offset": &filters.Offset} {
+		if v := q.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return filters, errors.New(name + " must be a non-negative integer")
+			}
+			*dest = n
+		}
+	}
+
+	return filters, nil
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+type ArchiveHandler struct {
+	archiveService *service.ArchiveService
+	validator      *validator.Validate
+}
+
+func NewArchiveHandler(archiveService *service.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiveService: archiveService,
+		validator:      validator.New(),
+	}
+}
+
+func (h *ArchiveHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	rule, err := h.archiveService.GetRule(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, err, "failed to fetch archive rule")
+		return
+	}
+
+	response.Success(w, http.StatusOK, rule, "archive rule fetched successfully")
+}
+
+func (h *ArchiveHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	var req models.UpdateArchiveRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	rule, err := h.archiveService.UpdateRule(r.Context(), req, userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to update archive rule")
+		return
+	}
+
+	response.Success(w, http.StatusOK, rule, "archive rule updated successfully")
+}
+
+func (h *ArchiveHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	if err := h.archiveService.DeleteRule(r.Context(), userID); err != nil {
+		h.writeError(w, err, "failed to delete archive rule")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "archive rule deleted successfully")
+}
+
+func (h *ArchiveHandler) writeError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, service.ErrArchiveRuleNotFound) {
+		response.Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+	response.Error(w, http.StatusInternalServerError, message)
+}
@@ -2,23 +2,30 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
 	"github.com/yourusername/todogo-backend/internal/models"
 	"github.com/yourusername/todogo-backend/internal/service"
 	"github.com/yourusername/todogo-backend/pkg/response"
 )
 
 type AuthHandler struct {
-	authService *service.AuthService
-	validator   *validator.Validate
+	authService              *service.AuthService
+	passwordResetService     *service.PasswordResetService
+	emailVerificationService *service.EmailVerificationService
+	validator                *validator.Validate
 }
 
-func NewAuthHandler(authService *service.AuthService) *AuthHandler {
+func NewAuthHandler(authService *service.AuthService, passwordResetService *service.PasswordResetService, emailVerificationService *service.EmailVerificationService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		validator:   validator.New(),
+		authService:              authService,
+		passwordResetService:     passwordResetService,
+		emailVerificationService: emailVerificationService,
+		validator:                validator.New(),
 	}
 }
 
@@ -45,6 +52,11 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if result.Token == "" {
+		response.Success(w, http.StatusCreated, result, "user registered, verify the email address to sign in")
+		return
+	}
+
 	response.Success(w, http.StatusCreated, result, "user registered successfully")
 }
 
@@ -67,9 +79,279 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 			response.Error(w, http.StatusUnauthorized, err.Error())
 			return
 		}
+		if errors.Is(err, service.ErrAccountDisabled) || errors.Is(err, service.ErrPasswordResetRequired) ||
+			errors.Is(err, service.ErrEmailNotVerified) {
+			response.Error(w, http.StatusForbidden, err.Error())
+			return
+		}
 		response.Error(w, http.StatusInternalServerError, "failed to login")
 		return
 	}
 
+	if result.MFARequired {
+		response.Success(w, http.StatusOK, result, "enter the code from your authenticator app")
+		return
+	}
+
+	response.Success(w, http.StatusOK, result, "login successful")
+}
+
+// ChangePassword sets a new password given the current one. Users whose
+// password an admin reset use it to sign in again.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ChangePasswordRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	result, err := h.authService.ChangePassword(r.Context(), req)
+	if err != nil {
+		if err.Error() == "invalid credentials" {
+			response.Error(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrMFACodeRequired) || errors.Is(err, service.ErrInvalidMFACode) {
+			response.Error(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrMFALocked) {
+			response.Error(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrAccountDisabled) {
+			response.Error(w, http.StatusForbidden, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to change password")
+		return
+	}
+
+	response.Success(w, http.StatusOK, result, "password changed successfully")
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh token.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	result, err := h.authService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRefreshToken):
+			response.Error(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, service.ErrAccountDisabled), errors.Is(err, service.ErrPasswordResetRequired),
+			errors.Is(err, service.ErrEmailNotVerified):
+			response.Error(w, http.StatusForbidden, err.Error())
+		default:
+			response.Error(w, http.StatusInternalServerError, "failed to refresh token")
+		}
+		return
+	}
+
+	response.Success(w, http.StatusOK, result, "token refreshed successfully")
+}
+
+// Logout signs out the token the request was made with.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.ClaimsKey).(*service.Claims)
+
+	var req models.LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.authService.Logout(r.Context(), claims, req.RefreshToken); err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to log out")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "logged out successfully")
+}
+
+// LogoutEverywhere signs out every token of the user, on all devices.
+func (h *AuthHandler) LogoutEverywhere(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	if err := h.authService.LogoutEverywhere(r.Context(), userID); err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to log out")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "logged out everywhere")
+}
+
+// ForgotPassword emails a reset link. It responds the same whether or not an
+// account has the address.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ForgotPasswordRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	h.passwordResetService.Request(r.Context(), req.Email)
+
+	response.Success(w, http.StatusOK, nil, "if an account exists for this email, a reset link has been sent")
+}
+
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ResetPasswordRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	if err := h.passwordResetService.Reset(r.Context(), req); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidResetToken):
+			response.Error(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrAccountDisabled):
+			response.Error(w, http.StatusForbidden, err.Error())
+		default:
+			response.Error(w, http.StatusInternalServerError, "failed to reset password")
+		}
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "password reset successfully, sign in with the new password")
+}
+
+// ChangeEmail moves the account to a new email address, which is sent a
+// verification link.
+func (h *AuthHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	var req models.ChangeEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	user, err := h.authService.ChangeEmail(r.Context(), userID, req)
+	if err != nil {
+		switch {
+		case err.Error() == "invalid credentials":
+			response.Error(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, service.ErrEmailTaken):
+			response.Error(w, http.StatusConflict, err.Error())
+		default:
+			response.Error(w, http.StatusInternalServerError, "failed to change email address")
+		}
+		return
+	}
+
+	response.Success(w, http.StatusOK, user, "email address changed, check it for a verification link")
+}
+
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req models.VerifyEmailRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	user, err := h.emailVerificationService.Verify(r.Context(), req.Token)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidVerificationToken) {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to verify email address")
+		return
+	}
+
+	response.Success(w, http.StatusOK, user, "email address verified")
+}
+
+// ResendVerification emails a new verification link. It responds the same
+// whether or not the link is sent.
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req models.ResendVerificationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	h.emailVerificationService.Resend(r.Context(), req.Email)
+
+	response.Success(w, http.StatusOK, nil, "if an unverified account exists for this email, a verification link has been sent")
+}
+
+// VerifyMFA completes a sign-in that asked for a second factor.
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req models.MFAVerifyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	result, err := h.authService.VerifyMFA(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidMFACode), errors.Is(err, service.ErrInvalidMFAToken):
+			response.Error(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, service.ErrMFALocked):
+			response.Error(w, http.StatusTooManyRequests, err.Error())
+		case errors.Is(err, service.ErrAccountDisabled), errors.Is(err, service.ErrPasswordResetRequired),
+			errors.Is(err, service.ErrEmailNotVerified):
+			response.Error(w, http.StatusForbidden, err.Error())
+		default:
+			response.Error(w, http.StatusInternalServerError, "failed to verify two-factor code")
+		}
+		return
+	}
+
 	response.Success(w, http.StatusOK, result, "login successful")
 }
@@ -0,0 +1,605 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/dav"
+	"github.com/yourusername/todogo-backend/pkg/ical"
+)
+
+// CalDAV URL layout. Every user sees the same paths; the authenticated user
+// decides whose todos they resolve to.
+const (
+	CalDAVPrefix        = "/dav"
+	caldavPrincipalPath = CalDAVPrefix + "/principals/me/"
+	caldavHomePath      = CalDAVPrefix + "/calendars/"
+	caldavTodosPath     = caldavHomePath + "todos/"
+
+	// syncTokenPrefix marks sync positions; tokens from when they were
+	// sequence numbers ("urn:todogo:sync:") are refused.
+	syncTokenPrefix = "urn:todogo:pos:"
+)
+
+type davResource int
+
+const (
+	davNotFound davResource = iota
+	davRoot
+	davPrincipal
+	davHome
+	davCollection
+	davObject
+)
+
+// CalDAVHandler exposes the user's todos as a single VTODO calendar
+// collection (RFC 4791) with sync-collection support (RFC 6578).
+type CalDAVHandler struct {
+	todoService     *service.TodoService
+	calendarService *service.CalendarService
+}
+
+func NewCalDAVHandler(todoService *service.TodoService, calendarService *service.CalendarService) *CalDAVHandler {
+	return &CalDAVHandler{
+		todoService:     todoService,
+		calendarService: calendarService,
+	}
+}
+
+// WellKnown redirects /.well-known/caldav to the DAV root (RFC 6764).
+func (h *CalDAVHandler) WellKnown(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, CalDAVPrefix+"/", http.StatusMovedPermanently)
+}
+
+func (h *CalDAVHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodOptions:
+		h.options(w)
+	case "PROPFIND":
+		h.propfind(w, r)
+	case "REPORT":
+		h.report(w, r)
+	case http.MethodGet, http.MethodHead:
+		h.get(w, r)
+	case http.MethodPut:
+		h.put(w, r)
+	case http.MethodDelete:
+		h.delete(w, r)
+	default:
+		w.Header().Set("Allow", caldavAllow)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+const caldavAllow = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT"
+
+func (h *CalDAVHandler) options(w http.ResponseWriter) {
+	w.Header().Set("DAV", "1, 3, calendar-access")
+	w.Header().Set("Allow", caldavAllow)
+	w.WriteHeader(http.StatusOK)
+}
+
+// classify maps a request path onto a resource, returning the todo UID for
+// calendar object resources.
+func classify(path string) (davResource, string) {
+	if !strings.HasSuffix(path, "/") && !strings.HasSuffix(path, ".ics") {
+		path += "/"
+	}
+	switch path {
+	case CalDAVPrefix + "/":
+		return davRoot, ""
+	case caldavPrincipalPath:
+		return davPrincipal, ""
+	case caldavHomePath:
+		return davHome, ""
+	case caldavTodosPath:
+		return davCollection, ""
+	}
+
+	if name, ok := strings.CutPrefix(path, caldavTodosPath); ok && !strings.Contains(name, "/") && strings.HasSuffix(name, ".ics") {
+		return davObject, strings.TrimSuffix(name, ".ics")
+	}
+
+	return davNotFound, ""
+}
+
+func objectHref(todo *models.Todo) string {
+	return caldavTodosPath + url.PathEscape(service.TodoUID(todo)) + ".ics"
+}
+
+func etag(todo *models.Todo) string {
+	return `"` + strconv.FormatInt(todo.SyncSeq, 10) + `"`
+}
+
+func syncToken(pos int64) string {
+	return syncTokenPrefix + strconv.FormatInt(pos, 10)
+}
+
+func parseSyncToken(token string) (int64, bool) {
+	if token == "" {
+		return 0, true
+	}
+	raw, ok := strings.CutPrefix(token, syncTokenPrefix)
+	if !ok {
+		return 0, false
+	}
+	pos, err := strconv.ParseInt(raw, 10, 64)
+	return pos, err == nil && pos >= 0
+}
+
+// davBodyError answers a request whose XML body could not be read: 413 when
+// it was larger than maxCalendarUpload, 400 with msg otherwise.
+func davBodyError(w http.ResponseWriter, err error, msg string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, msg, http.StatusBadRequest)
+}
+
+func (h *CalDAVHandler) propfind(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	root, err := dav.Decode(http.MaxBytesReader(w, r.Body, maxCalendarUpload))
+	if err != nil {
+		davBodyError(w, err, "invalid PROPFIND body")
+		return
+	}
+	req := dav.ParsePropRequest(root)
+	depth := r.Header.Get("Depth")
+
+	kind, uid := classify(r.URL.Path)
+	ms := &dav.Multistatus{}
+
+	switch kind {
+	case davRoot, davPrincipal, davHome:
+		ms.Responses = append(ms.Responses, dav.NewResponse(r.URL.Path, req, h.containerProps(r, kind)))
+		if kind == davHome && depth == "1" {
+			props, err := h.collectionProps(r, userID)
+			if err != nil {
+				h.serverError(w, err)
+				return
+			}
+			ms.Responses = append(ms.Responses, dav.NewResponse(caldavTodosPath, req, props))
+		}
+	case davCollection:
+		props, err := h.collectionProps(r, userID)
+		if err != nil {
+			h.serverError(w, err)
+			return
+		}
+		ms.Responses = append(ms.Responses, dav.NewResponse(caldavTodosPath, req, props))
+		if depth == "1" || depth == "infinity" {
+			todos, err := h.todoService.GetAll(r.Context(), userID, models.TodoFilters{})
+			if err != nil {
+				h.serverError(w, err)
+				return
+			}
+			for _, todo := range todos {
+				ms.Responses = append(ms.Responses, dav.NewResponse(objectHref(todo), req, objectProps(todo, false)))
+			}
+		}
+	case davObject:
+		todo, err := h.todoService.GetByICalUID(r.Context(), uid, userID)
+		if err != nil {
+			h.serverError(w, err)
+			return
+		}
+		if todo == nil {
+			http.NotFound(w, r)
+			return
+		}
+		ms.Responses = append(ms.Responses, dav.NewResponse(objectHref(todo), req, objectProps(todo, false)))
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	ms.WriteTo(w)
+}
+
+func (h *CalDAVHandler) containerProps(r *http.Request, kind davResource) map[xml.Name]string {
+	email, _ := r.Context().Value(middleware.EmailKey).(string)
+
+	props := map[xml.Name]string{
+		dav.Name(dav.NSDAV, "resourcetype"):           "<d:collection/>",
+		dav.Name(dav.NSDAV, "current-user-principal"): dav.Href(caldavPrincipalPath),
+		dav.Name(dav.NSDAV, "displayname"):            "Todogo",
+	}
+
+	switch kind {
+	case davPrincipal:
+		props[dav.Name(dav.NSDAV, "resourcetype")] = "<d:collection/><d:principal/>"
+		props[dav.Name(dav.NSDAV, "displayname")] = dav.Escape(email)
+		props[dav.Name(dav.NSDAV, "principal-URL")] = dav.Href(caldavPrincipalPath)
+		props[dav.Name(dav.NSCalDAV, "calendar-home-set")] = dav.Href(caldavHomePath)
+		props[dav.Name(dav.NSCalDAV, "calendar-user-address-set")] = dav.Href("mailto:" + email)
+	case davRoot:
+		props[dav.Name(dav.NSCalDAV, "calendar-home-set")] = dav.Href(caldavHomePath)
+	}
+
+	return props
+}
+
+func (h *CalDAVHandler) collectionProps(r *http.Request, userID uuid.UUID) (map[xml.Name]string, error) {
+	pos, err := h.todoService.SyncPosition(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	token := dav.Escape(syncToken(pos))
+
+	return map[xml.Name]string{
+		dav.Name(dav.NSDAV, "resourcetype"):                        "<d:collection/><c:calendar/>",
+		dav.Name(dav.NSDAV, "displayname"):                         "Todos",
+		dav.Name(dav.NSDAV, "current-user-principal"):              dav.Href(caldavPrincipalPath),
+		dav.Name(dav.NSDAV, "owner"):                               dav.Href(caldavPrincipalPath),
+		dav.Name(dav.NSDAV, "current-user-privilege-set"):          privileges,
+		dav.Name(dav.NSDAV, "supported-report-set"):                supportedReports,
+		dav.Name(dav.NSDAV, "sync-token"):                          token,
+		dav.Name(dav.NSCalendarServer, "getctag"):                  token,
+		dav.Name(dav.NSCalDAV, "calendar-description"):             "Todogo todos",
+		dav.Name(dav.NSCalDAV, "supported-calendar-component-set"): `<c:comp name="VTODO"/>`,
+		dav.Name(dav.NSCalDAV, "max-resource-size"):                strconv.Itoa(maxCalendarUpload),
+	}, nil
+}
+
+const (
+	privileges = "<d:privilege><d:read/></d:privilege>" +
+		"<d:privilege><d:write/></d:privilege>" +
+		"<d:privilege><d:write-content/></d:privilege>" +
+		"<d:privilege><d:bind/></d:privilege>" +
+		"<d:privilege><d:unbind/></d:privilege>" +
+		"<d:privilege><d:read-current-user-privilege-set/></d:privilege>"
+
+	supportedReports = "<d:supported-report><d:report><c:calendar-query/></d:report></d:supported-report>" +
+		"<d:supported-report><d:report><c:calendar-multiget/></d:report></d:supported-report>" +
+		"<d:supported-report><d:report><d:sync-collection/></d:report></d:supported-report>"
+)
+
+// objectProps lists the properties of a calendar object resource.
+// calendar-data is only included when asked for by name.
+func objectProps(todo *models.Todo, withData bool) map[xml.Name]string {
+	props := map[xml.Name]string{
+		dav.Name(dav.NSDAV, "resourcetype"):    "",
+		dav.Name(dav.NSDAV, "getetag"):         dav.Escape(etag(todo)),
+		dav.Name(dav.NSDAV, "getcontenttype"):  "text/calendar; charset=utf-8; component=VTODO",
+		dav.Name(dav.NSDAV, "getlastmodified"): todo.UpdatedAt.UTC().Format(http.TimeFormat),
+		dav.Name(dav.NSDAV, "displayname"):     dav.Escape(todo.Title),
+	}
+	if withData {
+		if data, err := service.EncodeVTodo(todo); err == nil {
+			props[dav.Name(dav.NSCalDAV, "calendar-data")] = dav.Escape(string(data))
+		}
+	}
+	return props
+}
+
+// wantsData reports whether calendar-data was requested, which avoids
+// rendering every todo for the common etag-only queries.
+func wantsData(req dav.PropRequest) bool {
+	for _, name := range req.Names {
+		if name.Space == dav.NSCalDAV && name.Local == "calendar-data" {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *CalDAVHandler) report(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	if kind, _ := classify(r.URL.Path); kind != davCollection {
+		dav.WriteError(w, http.StatusForbidden, dav.NSDAV, "supported-report")
+		return
+	}
+
+	root, err := dav.Decode(http.MaxBytesReader(w, r.Body, maxCalendarUpload))
+	if err != nil || root == nil {
+		davBodyError(w, err, "invalid REPORT body")
+		return
+	}
+	req := dav.ParsePropRequest(root)
+	withData := wantsData(req)
+
+	switch {
+	case root.XMLName.Space == dav.NSCalDAV && root.XMLName.Local == "calendar-query":
+		h.calendarQuery(w, r, userID, root, req, withData)
+	case root.XMLName.Space == dav.NSCalDAV && root.XMLName.Local == "calendar-multiget":
+		h.calendarMultiget(w, r, userID, root, req, withData)
+	case root.XMLName.Space == dav.NSDAV && root.XMLName.Local == "sync-collection":
+		h.syncCollection(w, r, userID, root, req, withData)
+	default:
+		dav.WriteError(w, http.StatusForbidden, dav.NSDAV, "supported-report")
+	}
+}
+
+// calendarQuery supports the filters task clients actually send: a VTODO
+// comp-filter, optionally with a COMPLETED is-not-defined prop-filter.
+// Other filter elements are ignored, which returns a superset of matches.
+func (h *CalDAVHandler) calendarQuery(w http.ResponseWriter, r *http.Request, userID uuid.UUID, root *dav.Node, req dav.PropRequest, withData bool) {
+	ms := &dav.Multistatus{}
+
+	vcal := root.Child(dav.NSCalDAV, "filter").Child(dav.NSCalDAV, "comp-filter")
+	var compFilter *dav.Node
+	if vcal != nil {
+		compFilter = vcal.Child(dav.NSCalDAV, "comp-filter")
+	}
+	if compFilter != nil && !strings.EqualFold(compFilter.Attr("name"), "VTODO") {
+		// Only VTODOs live in this collection.
+		ms.WriteTo(w)
+		return
+	}
+
+	onlyIncomplete := false
+	for _, pf := range compFilter.ChildrenNamed(dav.NSCalDAV, "prop-filter") {
+		if strings.EqualFold(pf.Attr("name"), "COMPLETED") && pf.Child(dav.NSCalDAV, "is-not-defined") != nil {
+			onlyIncomplete = true
+		}
+	}
+
+	filters := models.TodoFilters{}
+	if onlyIncomplete {
+		status := models.StatusPending
+		filters.Status = &status
+	}
+
+	todos, err := h.todoService.GetAll(r.Context(), userID, filters)
+	if err != nil {
+		h.serverError(w, err)
+		return
+	}
+
+	for _, todo := range todos {
+		ms.Responses = append(ms.Responses, dav.NewResponse(objectHref(todo), req, objectProps(todo, withData)))
+	}
+	ms.WriteTo(w)
+}
+
+func (h *CalDAVHandler) calendarMultiget(w http.ResponseWriter, r *http.Request, userID uuid.UUID, root *dav.Node, req dav.PropRequest, withData bool) {
+	ms := &dav.Multistatus{}
+
+	for _, hrefNode := range root.ChildrenNamed(dav.NSDAV, "href") {
+		href := hrefNode.Text()
+		u, err := url.Parse(href)
+		if err != nil {
+			ms.Responses = append(ms.Responses, dav.Response{Href: href, Status: http.StatusNotFound})
+			continue
+		}
+
+		kind, uid := classify(u.Path)
+		if kind != davObject {
+			ms.Responses = append(ms.Responses, dav.Response{Href: href, Status: http.StatusNotFound})
+			continue
+		}
+
+		todo, err := h.todoService.GetByICalUID(r.Context(), uid, userID)
+		if err != nil {
+			h.serverError(w, err)
+			return
+		}
+		if todo == nil {
+			ms.Responses = append(ms.Responses, dav.Response{Href: href, Status: http.StatusNotFound})
+			continue
+		}
+		ms.Responses = append(ms.Responses, dav.NewResponse(href, req, objectProps(todo, withData)))
+	}
+
+	ms.WriteTo(w)
+}
+
+func (h *CalDAVHandler) syncCollection(w http.ResponseWriter, r *http.Request, userID uuid.UUID, root *dav.Node, req dav.PropRequest, withData bool) {
+	since, ok := parseSyncToken(root.Child(dav.NSDAV, "sync-token").Text())
+	if !ok {
+		dav.WriteError(w, http.StatusForbidden, dav.NSDAV, "valid-sync-token")
+		return
+	}
+
+	changes, err := h.todoService.Changes(r.Context(), userID, since)
+	if err != nil {
+		h.serverError(w, err)
+		return
+	}
+
+	ms := &dav.Multistatus{SyncToken: syncToken(changes.Position)}
+	for _, todo := range changes.Changed {
+		ms.Responses = append(ms.Responses, dav.NewResponse(objectHref(todo), req, objectProps(todo, withData)))
+	}
+	// An initial sync only lists existing members.
+	if since > 0 {
+		for _, t := range changes.Deleted {
+			ms.Responses = append(ms.Responses, dav.Response{
+				Href:   caldavTodosPath + url.PathEscape(service.TombstoneUID(t)) + ".ics",
+				Status: http.StatusNotFound,
+			})
+		}
+	}
+
+	ms.WriteTo(w)
+}
+
+func (h *CalDAVHandler) get(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	kind, uid := classify(r.URL.Path)
+	switch kind {
+	case davCollection:
+		data, err := h.calendarService.Export(r.Context(), userID, models.TodoFilters{})
+		if err != nil {
+			h.serverError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Write(data)
+		return
+	case davObject:
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	todo, err := h.todoService.GetByICalUID(r.Context(), uid, userID)
+	if err != nil {
+		h.serverError(w, err)
+		return
+	}
+	if todo == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := service.EncodeVTodo(todo)
+	if err != nil {
+		h.serverError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("ETag", etag(todo))
+	w.Header().Set("Last-Modified", todo.UpdatedAt.UTC().Format(http.TimeFormat))
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.Write(data)
+}
+
+func (h *CalDAVHandler) put(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	kind, uid := classify(r.URL.Path)
+	if kind != davObject {
+		http.Error(w, "PUT is only allowed on calendar objects", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCalendarUpload))
+	if err != nil {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	roots, err := ical.Decode(bytes.NewReader(body))
+	if err != nil || len(roots) != 1 || roots[0].Name != "VCALENDAR" {
+		dav.WriteError(w, http.StatusForbidden, dav.NSCalDAV, "valid-calendar-data")
+		return
+	}
+	vtodos := roots[0].Find("VTODO")
+	if len(vtodos) != 1 {
+		dav.WriteError(w, http.StatusForbidden, dav.NSCalDAV, "supported-calendar-component")
+		return
+	}
+	if bodyUID, _ := vtodos[0].Text("UID"); strings.TrimSpace(bodyUID) != uid {
+		dav.WriteError(w, http.StatusForbidden, dav.NSCalDAV, "valid-calendar-object-resource")
+		return
+	}
+
+	existing, err := h.todoService.GetByICalUID(r.Context(), uid, userID)
+	if err != nil {
+		h.serverError(w, err)
+		return
+	}
+	if !preconditionsMet(r, existing) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	todo, created, err := h.calendarService.SaveVTodo(r.Context(), userID, vtodos[0])
+	if errors.Is(err, service.ErrPermissionDenied) {
+		h.serverError(w, err)
+		return
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("uid", uid).Msg("CalDAV PUT rejected")
+		dav.WriteError(w, http.StatusForbidden, dav.NSCalDAV, "valid-calendar-object-resource")
+		return
+	}
+
+	w.Header().Set("ETag", etag(todo))
+	if created {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CalDAVHandler) delete(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	kind, uid := classify(r.URL.Path)
+	if kind != davObject {
+		http.Error(w, "DELETE is only allowed on calendar objects", http.StatusMethodNotAllowed)
+		return
+	}
+
+	todo, err := h.todoService.GetByICalUID(r.Context(), uid, userID)
+	if err != nil {
+		h.serverError(w, err)
+		return
+	}
+	if todo == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if !preconditionsMet(r, todo) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	if err := h.todoService.Delete(r.Context(), todo.ID, userID); err != nil {
+		h.serverError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// preconditionsMet evaluates If-Match and If-None-Match against the current
+// resource, which is nil when it does not exist yet.
+func preconditionsMet(r *http.Request, todo *models.Todo) bool {
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if todo == nil {
+			return false
+		}
+		if ifMatch != "*" && !etagListContains(ifMatch, etag(todo)) {
+			return false
+		}
+	}
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && todo != nil {
+		if ifNoneMatch == "*" || etagListContains(ifNoneMatch, etag(todo)) {
+			return false
+		}
+	}
+	return true
+}
+
+func etagListContains(list, tag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// serverError writes the response for a failed request; a denied permission
+// is reported as the DAV need-privileges precondition.
+func (h *CalDAVHandler) serverError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrPermissionDenied) {
+		dav.WriteError(w, http.StatusForbidden, dav.NSDAV, "need-privileges")
+		return
+	}
+	log.Error().Err(err).Msg("CalDAV request failed")
+	http.Error(w, "internal server error", http.StatusInternalServerError)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+// maxCalendarUpload caps the size of an imported .ics file.
+const maxCalendarUpload = 5 << 20
+
+type CalendarHandler struct {
+	calendarService *service.CalendarService
+	undoService     *service.UndoService
+}
+
+func NewCalendarHandler(calendarService *service.CalendarService, undoService *service.UndoService) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+		undoService:     undoService,
+	}
+}
+
+func (h *CalendarHandler) Export(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	data, err := h.calendarService.Export(r.Context(), userID, parseTodoFilters(r))
+	if err != nil {
+		if permissionDenied(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to export todos")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="todogo.ics"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// Import accepts either a raw text/calendar body or a multipart form with the
+// file in the "file" field.
+func (h *CalendarHandler) Import(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCalendarUpload)
+
+	var body io.Reader = r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "missing calendar file")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	ctx, undo := service.WithUndo(r.Context())
+	result, err := h.calendarService.Import(ctx, userID, body)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCalendar) {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to import calendar")
+		return
+	}
+
+	issueUndo(w, r, h.undoService, undo, userID)
+	response.Success(w, http.StatusOK, result, "calendar imported successfully")
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+type DigestHandler struct {
+	digestService *service.DigestService
+	validator     *validator.Validate
+}
+
+func NewDigestHandler(digestService *service.DigestService) *DigestHandler {
+	return &DigestHandler{
+		digestService: digestService,
+		validator:     validator.New(),
+	}
+}
+
+func (h *DigestHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	schedule, err := h.digestService.Get(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, err, "failed to fetch digest schedule")
+		return
+	}
+
+	response.Success(w, http.StatusOK, schedule, "digest schedule fetched successfully")
+}
+
+func (h *DigestHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	var req models.UpdateDigestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	schedule, err := h.digestService.Update(r.Context(), req, userID)
+	if err != nil {
+		h.writeError(w, err, "failed to update digest schedule")
+		return
+	}
+
+	response.Success(w, http.StatusOK, schedule, "digest schedule updated successfully")
+}
+
+func (h *DigestHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	if err := h.digestService.Delete(r.Context(), userID); err != nil {
+		h.writeError(w, err, "failed to delete digest schedule")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "digest schedule deleted successfully")
+}
+
+// Send emails the digest immediately so users can check their setup.
+func (h *DigestHandler) Send(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	if err := h.digestService.SendNow(r.Context(), userID); err != nil {
+		if errors.Is(err, service.ErrDigestNotFound) {
+			response.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Error().Err(err).Str("user_id", userID.String()).Msg("Failed to send digest")
+		response.Error(w, http.StatusBadGateway, "failed to send digest")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "digest sent successfully")
+}
+
+func (h *DigestHandler) writeError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrDigestNotFound):
+		response.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidDigestSchedule):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	swaggerFiles "github.com/swaggo/files/v2"
+	"github.com/yourusername/todogo-backend/pkg/openapi"
+)
+
+// DocsPath is where Swagger UI is served.
+const DocsPath = "/docs/"
+
+// swaggerInitializer replaces the bundled one, which points at the Petstore
+// example, with one that loads our document.
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "/openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    persistAuthorization: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+type DocsHandler struct {
+	spec []byte
+	ui   http.Handler
+}
+
+func NewDocsHandler(doc *openapi.Document) (*DocsHandler, error) {
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DocsHandler{
+		spec: spec,
+		ui:   http.StripPrefix(DocsPath, http.FileServer(http.FS(swaggerFiles.FS))),
+	}, nil
+}
+
+func (h *DocsHandler) Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(h.spec)
+}
+
+// SwaggerUI serves the embedded Swagger UI assets.
+func (h *DocsHandler) SwaggerUI(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == DocsPath+"swagger-initializer.js" {
+		w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+		w.Write([]byte(swaggerInitializer))
+		return
+	}
+	h.ui.ServeHTTP(w, r)
+}
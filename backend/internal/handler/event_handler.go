@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+const heartbeatInterval = 20 * time.Second
+
+type EventHandler struct {
+	eventService *service.EventService
+}
+
+func NewEventHandler(eventService *service.EventService) *EventHandler {
+	return &EventHandler{
+		eventService: eventService,
+	}
+}
+
+// Stream serves the user's todo events in the request's workspace as
+// Server-Sent Events. Clients resume with the Last-Event-ID header (or
+// last_event_id query parameter).
+func (h *EventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	lastID := int64(-1)
+	if raw := r.Header.Get("Last-Event-ID"); raw != "" {
+		lastID, _ = strconv.ParseInt(raw, 10, 64)
+	} else if raw := r.URL.Query().Get("last_event_id"); raw != "" {
+		lastID, _ = strconv.ParseInt(raw, 10, 64)
+	}
+
+	rc := http.NewResponseController(w)
+	// The server-wide write timeout would cut the stream off.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		response.Error(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	// Subscribe before reading the backlog so nothing falls in between.
+	sub := h.eventService.Subscribe(r.Context(), userID)
+	defer h.eventService.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+
+	lastSent := lastID
+	if lastID >= 0 {
+		for {
+			backlog, err := h.eventService.Since(r.Context(), userID, lastSent)
+			if err != nil {
+				return
+			}
+			for _, event := range backlog {
+				writeEvent(w, event)
+				lastSent = event.ID
+			}
+			if len(backlog) == 0 {
+				break
+			}
+		}
+	}
+	rc.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			if event.ID <= lastSent {
+				continue
+			}
+			writeEvent(w, event)
+			lastSent = event.ID
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event *models.StoredEvent) {
+	data, _ := json.Marshal(event.Payload)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.EventType, data)
+}
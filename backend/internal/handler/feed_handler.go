@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+type FeedHandler struct {
+	feedService *service.FeedService
+	validator   *validator.Validate
+}
+
+func NewFeedHandler(feedService *service.FeedService) *FeedHandler {
+	return &FeedHandler{
+		feedService: feedService,
+		validator:   validator.New(),
+	}
+}
+
+func (h *FeedHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	var req models.CreateFeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	feed, err := h.feedService.Create(r.Context(), req, userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to create feed")
+		return
+	}
+
+	response.Success(w, http.StatusCreated, feed, "feed created successfully")
+}
+
+func (h *FeedHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	feeds, err := h.feedService.GetAll(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to fetch feeds")
+		return
+	}
+
+	response.Success(w, http.StatusOK, feeds, "feeds fetched successfully")
+}
+
+func (h *FeedHandler) Regenerate(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid feed id")
+		return
+	}
+
+	feed, err := h.feedService.Regenerate(r.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrFeedNotFound) {
+			response.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to regenerate feed token")
+		return
+	}
+
+	response.Success(w, http.StatusOK, feed, "feed token regenerated successfully")
+}
+
+func (h *FeedHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid feed id")
+		return
+	}
+
+	if err := h.feedService.Revoke(r.Context(), id, userID); err != nil {
+		if errors.Is(err, service.ErrFeedNotFound) {
+			response.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to revoke feed")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "feed revoked successfully")
+}
+
+// Serve is the public, token-authenticated subscription endpoint.
+func (h *FeedHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	data, err := h.feedService.Render(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		if errors.Is(err, service.ErrFeedNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "failed to render feed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/graph"
+)
+
+const (
+	maxGraphQLBody = 1 << 20
+	// graphqlWSProtocol is the graphql-transport-ws subprotocol used by
+	// graphql-ws and Apollo clients.
+	graphqlWSProtocol  = "graphql-transport-ws"
+	connectionInitWait = 10 * time.Second
+)
+
+type GraphQLHandler struct {
+	server   *graph.Server
+	upgrader websocket.Upgrader
+}
+
+func NewGraphQLHandler(server *graph.Server, allowedOrigins []string) *GraphQLHandler {
+	return &GraphQLHandler{
+		server: server,
+		upgrader: websocket.Upgrader{
+			Subprotocols: []string{graphqlWSProtocol},
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" {
+					return true
+				}
+				for _, allowed := range allowedOrigins {
+					if allowed == "*" || allowed == origin {
+						return true
+					}
+				}
+				return false
+			},
+		},
+	}
+}
+
+// ServeHTTP handles queries and mutations over HTTP and upgrades WebSocket
+// requests for subscriptions.
+func (h *GraphQLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		h.serveWebSocket(w, r)
+		return
+	}
+
+	var req graph.Request
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				writeGraphQL(w, http.StatusBadRequest, graphqlError("invalid variables"))
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLBody)).Decode(&req); err != nil {
+			writeGraphQL(w, http.StatusBadRequest, graphqlError("invalid request body"))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeGraphQL(w, http.StatusMethodNotAllowed, graphqlError("method not allowed"))
+		return
+	}
+
+	op, result := h.server.Prepare(req)
+	if result != nil {
+		writeGraphQL(w, http.StatusBadRequest, result)
+		return
+	}
+
+	switch {
+	case op.Kind() == "subscription":
+		writeGraphQL(w, http.StatusBadRequest, graphqlError("subscriptions require a WebSocket connection"))
+		return
+	case op.Kind() == "mutation" && r.Method != http.MethodPost:
+		w.Header().Set("Allow", "POST")
+		writeGraphQL(w, http.StatusMethodNotAllowed, graphqlError("mutations require POST"))
+		return
+	}
+
+	writeGraphQL(w, http.StatusOK, h.server.Execute(r.Context(), op))
+}
+
+func writeGraphQL(w http.ResponseWriter, status int, result *graphql.Result) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+func graphqlError(message string) *graphql.Result {
+	return &graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(message)}}
+}
+
+// wsMessage is a graphql-transport-ws message.
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// wsConn is one graphql-transport-ws connection. The user was authenticated
+// by the HTTP middleware before the upgrade.
+type wsConn struct {
+	conn   *websocket.Conn
+	server *graph.Server
+	ctx    context.Context
+
+	writeMu sync.Mutex
+
+	mu    sync.Mutex
+	ops   map[string]context.CancelFunc
+	acked bool
+}
+
+func (h *GraphQLHandler) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	if conn.Subprotocol() != graphqlWSProtocol {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseProtocolError, "unsupported subprotocol"))
+		return
+	}
+	conn.SetReadLimit(maxGraphQLBody)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	c := &wsConn{
+		conn:   conn,
+		server: h.server,
+		ctx:    ctx,
+		ops:    map[string]context.CancelFunc{},
+	}
+	c.run()
+}
+
+func (c *wsConn) run() {
+	initTimer := time.AfterFunc(connectionInitWait, func() {
+		c.mu.Lock()
+		acked := c.acked
+		c.mu.Unlock()
+		if !acked {
+			c.close(4408, "Connection initialisation timeout")
+		}
+	})
+	defer initTimer.Stop()
+
+	for {
+		var msg wsMessage
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			if _, ok := err.(*websocket.CloseError); !ok && c.ctx.Err() == nil {
+				c.close(4400, "Invalid message")
+			}
+			return
+		}
+
+		switch msg.Type {
+		case "connection_init":
+			c.mu.Lock()
+			duplicate := c.acked
+			c.acked = true
+			c.mu.Unlock()
+			if duplicate {
+				c.close(4429, "Too many initialisation requests")
+				return
+			}
+			c.send(wsMessage{Type: "connection_ack"})
+		case "ping":
+			c.send(wsMessage{Type: "pong"})
+		case "pong":
+		case "subscribe":
+			if !c.start(msg) {
+				return
+			}
+		case "complete":
+			c.stop(msg.ID)
+		default:
+			c.close(4400, "Unknown message type")
+			return
+		}
+	}
+}
+
+// start runs an operation; it returns false when the connection was closed
+// because of a protocol violation.
+func (c *wsConn) start(msg wsMessage) bool {
+	c.mu.Lock()
+	if !c.acked {
+		c.mu.Unlock()
+		c.close(4401, "Unauthorized")
+		return false
+	}
+	if _, exists := c.ops[msg.ID]; exists || msg.ID == "" {
+		c.mu.Unlock()
+		c.close(4409, "Subscriber for "+msg.ID+" already exists")
+		return false
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.ops[msg.ID] = cancel
+	c.mu.Unlock()
+
+	var req graph.Request
+	if err := json.Unmarshal(msg.Payload, &req); err != nil {
+		c.close(4400, "Invalid subscribe payload")
+		return false
+	}
+
+	op, result := c.server.Prepare(req)
+	if result != nil {
+		payload, _ := json.Marshal(result.Errors)
+		c.finish(msg.ID, wsMessage{ID: msg.ID, Type: "error", Payload: payload})
+		return true
+	}
+
+	go func() {
+		if op.Kind() == "subscription" {
+			for result := range c.server.Subscribe(ctx, op) {
+				c.next(msg.ID, result)
+			}
+		} else {
+			c.next(msg.ID, c.server.Execute(ctx, op))
+		}
+		if ctx.Err() == nil {
+			c.finish(msg.ID, wsMessage{ID: msg.ID, Type: "complete"})
+		}
+	}()
+	return true
+}
+
+func (c *wsConn) next(id string, result *graphql.Result) {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode GraphQL result")
+		return
+	}
+	c.send(wsMessage{ID: id, Type: "next", Payload: payload})
+}
+
+// finish forgets an operation and sends its final message.
+func (c *wsConn) finish(id string, msg wsMessage) {
+	c.mu.Lock()
+	cancel, ok := c.ops[id]
+	delete(c.ops, id)
+	c.mu.Unlock()
+	if ok {
+		cancel()
+		c.send(msg)
+	}
+}
+
+// stop cancels an operation the client completed.
+func (c *wsConn) stop(id string) {
+	c.mu.Lock()
+	cancel, ok := c.ops[id]
+	delete(c.ops, id)
+	c.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+func (c *wsConn) send(msg wsMessage) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	c.conn.WriteJSON(msg)
+}
+
+func (c *wsConn) close(code int, reason string) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	c.conn.Close()
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+type InboxHandler struct {
+	inboxService *service.InboxService
+}
+
+func NewInboxHandler(inboxService *service.InboxService) *InboxHandler {
+	return &InboxHandler{
+		inboxService: inboxService,
+	}
+}
+
+// GetAll lists the newest notifications; ?unread=true leaves out read ones.
+func (h *InboxHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	result, err := h.inboxService.GetAll(r.Context(), userID, r.URL.Query().Get("unread") == "true")
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to fetch inbox")
+		return
+	}
+
+	response.Success(w, http.StatusOK, result, "inbox fetched successfully")
+}
+
+func (h *InboxHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid inbox item id")
+		return
+	}
+
+	if err := h.inboxService.MarkRead(r.Context(), id, userID); err != nil {
+		if errors.Is(err, service.ErrInboxItemNotFound) {
+			response.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to mark inbox item as read")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "inbox item marked as read")
+}
+
+func (h *InboxHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	if _, err := h.inboxService.MarkAllRead(r.Context(), userID); err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to mark inbox as read")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "inbox marked as read")
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+type MFAHandler struct {
+	mfaService *service.MFAService
+	validator  *validator.Validate
+}
+
+func NewMFAHandler(mfaService *service.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+		validator:  validator.New(),
+	}
+}
+
+// mfaError writes the response for errors shared by the two-factor
+// endpoints and reports whether it did.
+func mfaError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrInvalidMFACode):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrMFALocked):
+		response.Error(w, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, service.ErrTOTPAlreadyEnabled),
+		errors.Is(err, service.ErrTOTPNotEnrolled),
+		errors.Is(err, service.ErrTOTPNotEnabled):
+		response.Error(w, http.StatusConflict, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// Enroll starts TOTP enrollment and returns the secret to add to an
+// authenticator app.
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	enrollment, err := h.mfaService.Enroll(r.Context(), userID)
+	if err != nil {
+		if mfaError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to start two-factor enrollment")
+		return
+	}
+
+	response.Success(w, http.StatusOK, enrollment, "scan the code and confirm it to enable two-factor authentication")
+}
+
+func (h *MFAHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	var req models.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	codes, err := h.mfaService.Confirm(r.Context(), userID, req.Code)
+	if err != nil {
+		if mfaError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to enable two-factor authentication")
+		return
+	}
+
+	response.Success(w, http.StatusOK, codes, "two-factor authentication enabled, store the recovery codes safely")
+}
+
+func (h *MFAHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	var req models.DisableTOTPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	if err := h.mfaService.Disable(r.Context(), userID, req); err != nil {
+		if err.Error() == "invalid credentials" {
+			response.Error(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if mfaError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to disable two-factor authentication")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "two-factor authentication disabled")
+}
+
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	var req models.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+	if err != nil {
+		if mfaError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to generate recovery codes")
+		return
+	}
+
+	response.Success(w, http.StatusOK, codes, "recovery codes replaced, the previous ones no longer work")
+}
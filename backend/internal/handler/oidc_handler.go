@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+// oidcStateCookie binds a single sign-on to the browser that started it.
+const oidcStateCookie = "todogo_oidc_state"
+
+type OIDCHandler struct {
+	oidcService *service.OIDCService
+	validator   *validator.Validate
+	// secureCookies marks cookies Secure when the API is served over HTTPS.
+	secureCookies bool
+}
+
+func NewOIDCHandler(oidcService *service.OIDCService, secureCookies bool) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService:   oidcService,
+		validator:     validator.New(),
+		secureCookies: secureCookies,
+	}
+}
+
+func (h *OIDCHandler) setStateCookie(w http.ResponseWriter, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		Path:     "/api/v1/auth/oidc",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   h.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// Authorize starts a single sign-on and returns the provider URL to send the
+// user to.
+func (h *OIDCHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	authorization, err := h.oidcService.Authorize(r.Context())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSSODisabled):
+			response.Error(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrSSOUnavailable):
+			response.Error(w, http.StatusBadGateway, err.Error())
+		default:
+			response.Error(w, http.StatusInternalServerError, "failed to start single sign-on")
+		}
+		return
+	}
+
+	h.setStateCookie(w, authorization.State, int(service.OIDCStateExpiry.Seconds()))
+	response.Success(w, http.StatusOK, authorization, "continue at the provider")
+}
+
+// Callback completes a single sign-on with the code and state the provider
+// redirected back with.
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	var req models.OIDCCallbackRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	var boundState string
+	if cookie, err := r.Cookie(oidcStateCookie); err == nil {
+		boundState = cookie.Value
+	}
+	// The state works once whatever the outcome
+	h.setStateCookie(w, "", -1)
+
+	result, err := h.oidcService.Callback(r.Context(), req, boundState)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSSODisabled):
+			response.Error(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrInvalidOIDCState), errors.Is(err, service.ErrSSONoEmail):
+			response.Error(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrSSOFailed):
+			response.Error(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, service.ErrSSOLinkRefused):
+			response.Error(w, http.StatusConflict, err.Error())
+		case errors.Is(err, service.ErrSSOSignupClosed), errors.Is(err, service.ErrAccountDisabled),
+			errors.Is(err, service.ErrPasswordResetRequired), errors.Is(err, service.ErrEmailNotVerified):
+			response.Error(w, http.StatusForbidden, err.Error())
+		default:
+			response.Error(w, http.StatusInternalServerError, "failed to complete single sign-on")
+		}
+		return
+	}
+
+	if result.MFARequired {
+		response.Success(w, http.StatusOK, result, "enter the code from your authenticator app")
+		return
+	}
+
+	response.Success(w, http.StatusOK, result, "login successful")
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+type RoleHandler struct {
+	policyService *service.PolicyService
+	validator     *validator.Validate
+}
+
+func NewRoleHandler(policyService *service.PolicyService) *RoleHandler {
+	return &RoleHandler{
+		policyService: policyService,
+		validator:     validator.New(),
+	}
+}
+
+// permissionDenied writes a 403 when the caller's role lacks a permission
+// and reports whether it did.
+func permissionDenied(w http.ResponseWriter, err error) bool {
+	if !errors.Is(err, service.ErrPermissionDenied) {
+		return false
+	}
+	response.Error(w, http.StatusForbidden, err.Error())
+	return true
+}
+
+func (h *RoleHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	response.Success(w, http.StatusOK, h.policyService.Roles(), "roles fetched successfully")
+}
+
+func (h *RoleHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	var req models.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	user, err := h.policyService.SetRole(r.Context(), id, req.Role, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			response.Error(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, repository.ErrLastAdmin):
+			response.Error(w, http.StatusConflict, err.Error())
+		default:
+			response.Error(w, http.StatusInternalServerError, "failed to update role")
+		}
+		return
+	}
+
+	response.Success(w, http.StatusOK, user, "role updated successfully")
+}
+
+func (h *RoleHandler) Audit(w http.ResponseWriter, r *http.Request) {
+	var action *models.AuditAction
+	if v := r.URL.Query().Get("action"); v != "" {
+		a := models.AuditAction(v)
+		action = &a
+	}
+
+	entries, err := h.policyService.Audit(r.Context(), action)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to fetch audit log")
+		return
+	}
+
+	response.Success(w, http.StatusOK, entries, "audit log fetched successfully")
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+type StatsHandler struct {
+	statsService *service.StatsService
+}
+
+func NewStatsHandler(statsService *service.StatsService) *StatsHandler {
+	return &StatsHandler{
+		statsService: statsService,
+	}
+}
+
+// Get reports productivity stats. The window is set with ?days= and local
+// dates follow the IANA timezone in ?tz=.
+func (h *StatsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	opts := models.StatsOptions{Timezone: r.URL.Query().Get("tz")}
+	if days := r.URL.Query().Get("days"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, service.ErrInvalidStatsWindow.Error())
+			return
+		}
+		opts.Days = n
+	}
+
+	stats, err := h.statsService.Get(r.Context(), userID, opts)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStatsWindow) || errors.Is(err, repository.ErrInvalidTimezone) {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to compute stats")
+		return
+	}
+
+	response.Success(w, http.StatusOK, stats, "stats fetched successfully")
+}
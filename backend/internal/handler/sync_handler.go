@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+const maxSyncBody = 5 << 20
+
+type SyncHandler struct {
+	syncService *service.SyncService
+	validator   *validator.Validate
+}
+
+func NewSyncHandler(syncService *service.SyncService) *SyncHandler {
+	return &SyncHandler{
+		syncService: syncService,
+		validator:   validator.New(),
+	}
+}
+
+func (h *SyncHandler) Pull(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	resp, err := h.syncService.Pull(r.Context(), userID, r.URL.Query().Get("sync_token"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, resp, "changes fetched successfully")
+}
+
+func (h *SyncHandler) Push(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	var req models.SyncRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSyncBody)).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	resp, err := h.syncService.Push(r.Context(), userID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, resp, "changes synced successfully")
+}
+
+func (h *SyncHandler) writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrInvalidSyncToken) {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if permissionDenied(w, err) {
+		return
+	}
+	response.Error(w, http.StatusInternalServerError, "failed to sync todos")
+}
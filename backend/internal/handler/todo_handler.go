@@ -2,6 +2,8 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -15,12 +17,14 @@ import (
 
 type TodoHandler struct {
 	todoService *service.TodoService
+	undoService *service.UndoService
 	validator   *validator.Validate
 }
 
-func NewTodoHandler(todoService *service.TodoService) *TodoHandler {
+func NewTodoHandler(todoService *service.TodoService, undoService *service.UndoService) *TodoHandler {
 	return &TodoHandler{
 		todoService: todoService,
+		undoService: undoService,
 		validator:   validator.New(),
 	}
 }
@@ -39,44 +43,32 @@ func (h *TodoHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todo, err := h.todoService.Create(r.Context(), req, userID)
+	ctx, undo := service.WithUndo(r.Context())
+	todo, err := h.todoService.Create(ctx, req, userID)
 	if err != nil {
+		if permissionDenied(w, err) {
+			return
+		}
 		// Log the actual error for debugging
 		println("Error creating todo:", err.Error())
 		response.Error(w, http.StatusInternalServerError, "failed to create todo: "+err.Error())
 		return
 	}
 
+	issueUndo(w, r, h.undoService, undo, userID)
 	response.Success(w, http.StatusCreated, todo, "todo created successfully")
 }
 
 func (h *TodoHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
 
-	// Parse filters from query params
-	filters := models.TodoFilters{}
-
-	if status := r.URL.Query().Get("status"); status != "" {
-		s := models.TodoStatus(status)
-		filters.Status = &s
-	}
-
-	if priority := r.URL.Query().Get("priority"); priority != "" {
-		p := models.TodoPriority(priority)
-		filters.Priority = &p
-	}
-
-	if search := r.URL.Query().Get("search"); search != "" {
-		filters.Search = &search
-	}
-
-	if tags := r.URL.Query().Get("tags"); tags != "" {
-		// Split tags by comma
-		filters.Tags = []string{tags}
-	}
+	filters := parseTodoFilters(r)
 
 	todos, err := h.todoService.GetAll(r.Context(), userID, filters)
 	if err != nil {
+		if permissionDenied(w, err) {
+			return
+		}
 		response.Error(w, http.StatusInternalServerError, "failed to fetch todos")
 		return
 	}
@@ -95,6 +87,9 @@ func (h *TodoHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	todo, err := h.todoService.GetByID(r.Context(), id, userID)
 	if err != nil {
+		if permissionDenied(w, err) {
+			return
+		}
 		if err.Error() == "todo not found" {
 			response.Error(w, http.StatusNotFound, err.Error())
 			return
@@ -126,16 +121,25 @@ func (h *TodoHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todo, err := h.todoService.Update(r.Context(), id, req, userID)
+	ctx, undo := service.WithUndo(r.Context())
+	todo, err := h.todoService.Update(ctx, id, req, userID)
 	if err != nil {
+		if permissionDenied(w, err) {
+			return
+		}
 		if err.Error() == "todo not found" {
 			response.Error(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if errors.Is(err, service.ErrNotTodoOwner) {
+			response.Error(w, http.StatusForbidden, err.Error())
+			return
+		}
 		response.Error(w, http.StatusInternalServerError, "failed to update todo")
 		return
 	}
 
+	issueUndo(w, r, h.undoService, undo, userID)
 	response.Success(w, http.StatusOK, todo, "todo updated successfully")
 }
 
@@ -148,12 +152,21 @@ func (h *TodoHandler) MarkAsCompleted(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todo, err := h.todoService.MarkAsCompleted(r.Context(), id, userID)
+	ctx, undo := service.WithUndo(r.Context())
+	todo, err := h.todoService.MarkAsCompleted(ctx, id, userID)
 	if err != nil {
+		if permissionDenied(w, err) {
+			return
+		}
+		if err.Error() == "todo not found" {
+			response.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
 		response.Error(w, http.StatusInternalServerError, "failed to mark todo as completed")
 		return
 	}
 
+	issueUndo(w, r, h.undoService, undo, userID)
 	response.Success(w, http.StatusOK, todo, "todo marked as completed")
 }
 
@@ -166,15 +179,171 @@ func (h *TodoHandler) MarkAsIncomplete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todo, err := h.todoService.MarkAsIncomplete(r.Context(), id, userID)
+	ctx, undo := service.WithUndo(r.Context())
+	todo, err := h.todoService.MarkAsIncomplete(ctx, id, userID)
 	if err != nil {
+		if permissionDenied(w, err) {
+			return
+		}
+		if err.Error() == "todo not found" {
+			response.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
 		response.Error(w, http.StatusInternalServerError, "failed to mark todo as incomplete")
 		return
 	}
 
+	issueUndo(w, r, h.undoService, undo, userID)
 	response.Success(w, http.StatusOK, todo, "todo marked as incomplete")
 }
 
+func (h *TodoHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid todo id")
+		return
+	}
+
+	ctx, undo := service.WithUndo(r.Context())
+	todo, err := h.todoService.Archive(ctx, id, userID)
+	if err != nil {
+		if permissionDenied(w, err) {
+			return
+		}
+		if err.Error() == "todo not found" {
+			response.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrNotTodoOwner) {
+			response.Error(w, http.StatusForbidden, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to archive todo")
+		return
+	}
+
+	issueUndo(w, r, h.undoService, undo, userID)
+	response.Success(w, http.StatusOK, todo, "todo archived")
+}
+
+func (h *TodoHandler) Unarchive(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid todo id")
+		return
+	}
+
+	ctx, undo := service.WithUndo(r.Context())
+	todo, err := h.todoService.Unarchive(ctx, id, userID)
+	if err != nil {
+		if permissionDenied(w, err) {
+			return
+		}
+		if err.Error() == "todo not found" {
+			response.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrNotTodoOwner) {
+			response.Error(w, http.StatusForbidden, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to unarchive todo")
+		return
+	}
+
+	issueUndo(w, r, h.undoService, undo, userID)
+	response.Success(w, http.StatusOK, todo, "todo unarchived")
+}
+
+// Assign hands the todo to another user, who can then view, complete and
+// reopen it.
+func (h *TodoHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid todo id")
+		return
+	}
+
+	var req models.AssignTodoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	ctx, undo := service.WithUndo(r.Context())
+	todo, err := h.todoService.Assign(ctx, id, req.Email, userID)
+	if err != nil {
+		switch {
+		case err.Error() == "todo not found":
+			response.Error(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrNotTodoOwner), errors.Is(err, service.ErrPermissionDenied):
+			response.Error(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrAssigneeNotFound), errors.Is(err, service.ErrAssigneeNotMember):
+			response.Error(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			response.Error(w, http.StatusInternalServerError, "failed to assign todo")
+		}
+		return
+	}
+
+	issueUndo(w, r, h.undoService, undo, userID)
+	response.Success(w, http.StatusOK, todo, "todo assigned")
+}
+
+func (h *TodoHandler) Duplicate(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid todo id")
+		return
+	}
+
+	// All fields are optional, so an empty body duplicates with the defaults
+	var req models.DuplicateTodoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	ctx, undo := service.WithUndo(r.Context())
+	todo, err := h.todoService.Duplicate(ctx, id, req, userID)
+	if err != nil {
+		if permissionDenied(w, err) {
+			return
+		}
+		if err.Error() == "todo not found" {
+			response.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrNotTodoOwner) {
+			response.Error(w, http.StatusForbidden, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to duplicate todo")
+		return
+	}
+
+	issueUndo(w, r, h.undoService, undo, userID)
+	response.Success(w, http.StatusCreated, todo, "todo duplicated successfully")
+}
+
 func (h *TodoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
 	
@@ -184,15 +353,56 @@ func (h *TodoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.todoService.Delete(r.Context(), id, userID); err != nil {
+	ctx, undo := service.WithUndo(r.Context())
+	if err := h.todoService.Delete(ctx, id, userID); err != nil {
+		if permissionDenied(w, err) {
+			return
+		}
 		println("Error deleting todo:", err.Error())
-		if err.Error() == "sql: no rows in result set" {
+		if err.Error() == "todo not found" || err.Error() == "sql: no rows in result set" {
 			response.Error(w, http.StatusNotFound, "todo not found")
 			return
 		}
-		response.Error(w, http.StatusInternalServerError, "failed to delete todo: "+err.Error())
+		if errors.Is(err, service.ErrNotTodoOwner) {
+			response.Error(w, http.StatusForbidden, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to delete todo")
 		return
 	}
 
+	issueUndo(w, r, h.undoService, undo, userID)
 	response.Success(w, http.StatusOK, nil, "todo deleted successfully")
 }
+
+// parseTodoFilters reads the todo list filters from the query string.
+func parseTodoFilters(r *http.Request) models.TodoFilters {
+	filters := models.TodoFilters{}
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		s := models.TodoStatus(status)
+		filters.Status = &s
+	}
+
+	if priority := r.URL.Query().Get("priority"); priority != "" {
+		p := models.TodoPriority(priority)
+		filters.Priority = &p
+	}
+
+	if search := r.URL.Query().Get("search"); search != "" {
+		filters.Search = &search
+	}
+
+	// Archived todos are only listed on request
+	filters.Archived = r.URL.Query().Get("archived") == "true"
+
+	// Other users' todos assigned to the caller
+	filters.AssignedToMe = r.URL.Query().Get("assigned") == "me"
+
+	if tags := r.URL.Query().Get("tags"); tags != "" {
+		// Split tags by comma
+		filters.Tags = []string{tags}
+	}
+
+	return filters
+}
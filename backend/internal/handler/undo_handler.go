@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/repository"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+type UndoHandler struct {
+	undoService *service.UndoService
+}
+
+func NewUndoHandler(undoService *service.UndoService) *UndoHandler {
+	return &UndoHandler{
+		undoService: undoService,
+	}
+}
+
+func (h *UndoHandler) Undo(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	result, err := h.undoService.Undo(r.Context(), chi.URLParam(r, "token"), userID)
+	if err != nil {
+		if permissionDenied(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrUndoNotFound):
+			response.Error(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, repository.ErrUndoConflict):
+			response.Error(w, http.StatusConflict, err.Error())
+		default:
+			response.Error(w, http.StatusInternalServerError, "failed to undo")
+		}
+		return
+	}
+
+	response.Success(w, http.StatusOK, result, "undo applied")
+}
+
+// issueUndo sets the X-Undo-Token and X-Undo-Expires headers for the changes
+// recorded in scope. It must run before the response is written. A failure
+// only costs the client its undo, so it is logged and not reported.
+func issueUndo(w http.ResponseWriter, r *http.Request, undoService *service.UndoService, scope *service.UndoScope, userID uuid.UUID) {
+	token, expiresAt, err := undoService.Issue(r.Context(), scope, userID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID.String()).Msg("Failed to issue undo token")
+		return
+	}
+	if token == "" {
+		return
+	}
+
+	w.Header().Set("X-Undo-Token", token)
+	w.Header().Set("X-Undo-Expires", expiresAt.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+type ViewHandler struct {
+	viewService *service.ViewService
+	validator   *validator.Validate
+}
+
+func NewViewHandler(viewService *service.ViewService) *ViewHandler {
+	return &ViewHandler{
+		viewService: viewService,
+		validator:   validator.New(),
+	}
+}
+
+func (h *ViewHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	var req models.CreateViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	view, err := h.viewService.Create(r.Context(), req, userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to create view")
+		return
+	}
+
+	response.Success(w, http.StatusCreated, view, "view created successfully")
+}
+
+func (h *ViewHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	views, err := h.viewService.GetAll(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to fetch views")
+		return
+	}
+
+	response.Success(w, http.StatusOK, views, "views fetched successfully")
+}
+
+func (h *ViewHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	view, err := h.viewService.GetByID(r.Context(), chi.URLParam(r, "id"), userID)
+	if err != nil {
+		h.writeError(w, err, "failed to fetch view")
+		return
+	}
+
+	response.Success(w, http.StatusOK, view, "view fetched successfully")
+}
+
+func (h *ViewHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	var req models.UpdateViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	view, err := h.viewService.Update(r.Context(), chi.URLParam(r, "id"), req, userID)
+	if err != nil {
+		h.writeError(w, err, "failed to update view")
+		return
+	}
+
+	response.Success(w, http.StatusOK, view, "view updated successfully")
+}
+
+func (h *ViewHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	if err := h.viewService.Delete(r.Context(), chi.URLParam(r, "id"), userID); err != nil {
+		h.writeError(w, err, "failed to delete view")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "view deleted successfully")
+}
+
+// Todos evaluates the view. Relative due ranges follow the IANA timezone in
+// ?tz=.
+func (h *ViewHandler) Todos(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	result, err := h.viewService.Todos(r.Context(), chi.URLParam(r, "id"), r.URL.Query().Get("tz"), userID)
+	if err != nil {
+		h.writeError(w, err, "failed to fetch todos")
+		return
+	}
+
+	response.Success(w, http.StatusOK, result, "todos fetched successfully")
+}
+
+func (h *ViewHandler) writeError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrViewNotFound):
+		response.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrBuiltInView):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, repository.ErrInvalidTimezone):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+	validator      *validator.Validate
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		validator:      validator.New(),
+	}
+}
+
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	var req models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	hook, err := h.webhookService.Create(r.Context(), req, userID)
+	if err != nil {
+		h.writeError(w, err, "failed to create webhook")
+		return
+	}
+
+	response.Success(w, http.StatusCreated, hook, "webhook created successfully")
+}
+
+func (h *WebhookHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	hooks, err := h.webhookService.GetAll(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to fetch webhooks")
+		return
+	}
+
+	response.Success(w, http.StatusOK, hooks, "webhooks fetched successfully")
+}
+
+func (h *WebhookHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	hook, err := h.webhookService.GetByID(r.Context(), id, userID)
+	if err != nil {
+		h.writeError(w, err, "failed to fetch webhook")
+		return
+	}
+
+	response.Success(w, http.StatusOK, hook, "webhook fetched successfully")
+}
+
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	hook, err := h.webhookService.Update(r.Context(), id, req, userID)
+	if err != nil {
+		h.writeError(w, err, "failed to update webhook")
+		return
+	}
+
+	response.Success(w, http.StatusOK, hook, "webhook updated successfully")
+}
+
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	if err := h.webhookService.Delete(r.Context(), id, userID); err != nil {
+		h.writeError(w, err, "failed to delete webhook")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "webhook deleted successfully")
+}
+
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	deliveries, err := h.webhookService.Deliveries(r.Context(), id, userID)
+	if err != nil {
+		h.writeError(w, err, "failed to fetch deliveries")
+		return
+	}
+
+	response.Success(w, http.StatusOK, deliveries, "deliveries fetched successfully")
+}
+
+func (h *WebhookHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	deliveryID, err := uuid.Parse(chi.URLParam(r, "deliveryID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid delivery id")
+		return
+	}
+
+	delivery, err := h.webhookService.Replay(r.Context(), id, deliveryID, userID)
+	if err != nil {
+		h.writeError(w, err, "failed to replay delivery")
+		return
+	}
+
+	response.Success(w, http.StatusAccepted, delivery, "delivery queued for replay")
+}
+
+func (h *WebhookHandler) Ping(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	delivery, err := h.webhookService.Ping(r.Context(), id, userID)
+	if err != nil {
+		h.writeError(w, err, "failed to send ping")
+		return
+	}
+
+	response.Success(w, http.StatusAccepted, delivery, "ping queued")
+}
+
+func (h *WebhookHandler) writeError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, service.ErrWebhookNotFound) || errors.Is(err, service.ErrDeliveryNotFound) {
+		response.Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrWebhookURLNotAllowed) {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Error(w, http.StatusInternalServerError, message)
+}
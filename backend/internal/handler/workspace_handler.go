@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/middleware"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+type WorkspaceHandler struct {
+	workspaceService *service.WorkspaceService
+	authService      *service.AuthService
+	validator        *validator.Validate
+}
+
+func NewWorkspaceHandler(workspaceService *service.WorkspaceService, authService *service.AuthService) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceService: workspaceService,
+		authService:      authService,
+		validator:        validator.New(),
+	}
+}
+
+// workspaceError writes the response for errors shared by the workspace
+// endpoints and reports whether it did.
+func workspaceError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrWorkspaceNotFound),
+		errors.Is(err, service.ErrMemberNotFound),
+		errors.Is(err, service.ErrInvitationNotFound):
+		response.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrNotWorkspaceOwner),
+		errors.Is(err, service.ErrPersonalWorkspace),
+		errors.Is(err, service.ErrOwnerCannotLeave),
+		errors.Is(err, repository.ErrInvitationEmailMismatch):
+		response.Error(w, http.StatusForbidden, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *WorkspaceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	var req models.CreateWorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	ws, err := h.workspaceService.Create(r.Context(), req, userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to create workspace")
+		return
+	}
+
+	response.Success(w, http.StatusCreated, ws, "workspace created successfully")
+}
+
+func (h *WorkspaceHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	workspaces, err := h.workspaceService.GetAll(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to fetch workspaces")
+		return
+	}
+
+	response.Success(w, http.StatusOK, workspaces, "workspaces fetched successfully")
+}
+
+func (h *WorkspaceHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid workspace id")
+		return
+	}
+
+	ws, err := h.workspaceService.GetByID(r.Context(), id, userID)
+	if err != nil {
+		if workspaceError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to fetch workspace")
+		return
+	}
+
+	response.Success(w, http.StatusOK, ws, "workspace fetched successfully")
+}
+
+func (h *WorkspaceHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid workspace id")
+		return
+	}
+
+	var req models.UpdateWorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	ws, err := h.workspaceService.Update(r.Context(), id, req, userID)
+	if err != nil {
+		if workspaceError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to update workspace")
+		return
+	}
+
+	response.Success(w, http.StatusOK, ws, "workspace updated successfully")
+}
+
+func (h *WorkspaceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid workspace id")
+		return
+	}
+
+	if err := h.workspaceService.Delete(r.Context(), id, userID); err != nil {
+		if workspaceError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to delete workspace")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "workspace deleted successfully")
+}
+
+// Switch issues a token that defaults to the workspace, for clients that
+// would rather not send X-Workspace-ID with every request.
+func (h *WorkspaceHandler) Switch(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid workspace id")
+		return
+	}
+
+	if _, err := h.workspaceService.GetByID(r.Context(), id, userID); err != nil {
+		if workspaceError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to switch workspace")
+		return
+	}
+
+	resp, err := h.authService.IssueWorkspaceToken(r.Context(), userID, id)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "failed to switch workspace")
+		return
+	}
+
+	response.Success(w, http.StatusOK, resp, "workspace switched")
+}
+
+func (h *WorkspaceHandler) Members(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid workspace id")
+		return
+	}
+
+	members, err := h.workspaceService.Members(r.Context(), id, userID)
+	if err != nil {
+		if workspaceError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to fetch members")
+		return
+	}
+
+	response.Success(w, http.StatusOK, members, "members fetched successfully")
+}
+
+// RemoveMember removes another member, which only owners can do, or lets the
+// caller leave.
+func (h *WorkspaceHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid workspace id")
+		return
+	}
+
+	memberID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	if err := h.workspaceService.RemoveMember(r.Context(), id, memberID, userID); err != nil {
+		if workspaceError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to remove member")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "member removed")
+}
+
+func (h *WorkspaceHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid workspace id")
+		return
+	}
+
+	var req models.CreateInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		response.ValidationError(w, err)
+		return
+	}
+
+	inv, err := h.workspaceService.Invite(r.Context(), id, req, userID)
+	if err != nil {
+		if workspaceError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to create invitation")
+		return
+	}
+
+	response.Success(w, http.StatusCreated, inv, "invitation created successfully")
+}
+
+func (h *WorkspaceHandler) Invitations(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid workspace id")
+		return
+	}
+
+	invitations, err := h.workspaceService.Invitations(r.Context(), id, userID)
+	if err != nil {
+		if workspaceError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to fetch invitations")
+		return
+	}
+
+	response.Success(w, http.StatusOK, invitations, "invitations fetched successfully")
+}
+
+func (h *WorkspaceHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid workspace id")
+		return
+	}
+
+	invitationID, err := uuid.Parse(chi.URLParam(r, "invitationID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid invitation id")
+		return
+	}
+
+	if err := h.workspaceService.RevokeInvitation(r.Context(), id, invitationID, userID); err != nil {
+		if workspaceError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to revoke invitation")
+		return
+	}
+
+	response.Success(w, http.StatusOK, nil, "invitation revoked")
+}
+
+// Accept joins the workspace of an invitation sent to the caller's email.
+func (h *WorkspaceHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+
+	ws, err := h.workspaceService.Accept(r.Context(), chi.URLParam(r, "token"), userID)
+	if err != nil {
+		if workspaceError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "failed to accept invitation")
+		return
+	}
+
+	response.Success(w, http.StatusOK, ws, "invitation accepted")
+}
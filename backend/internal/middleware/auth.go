@@ -2,9 +2,12 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/models"
 	"github.com/yourusername/todogo-backend/internal/service"
 	"github.com/yourusername/todogo-backend/pkg/response"
 )
@@ -14,8 +17,28 @@ type contextKey string
 const (
 	UserIDKey contextKey = "user_id"
 	EmailKey  contextKey = "email"
+	// RoleKey holds the role from the token, which may be stale; use the
+	// policy service to check permissions.
+	RoleKey contextKey = "role"
+	// WorkspaceClaimKey holds the token's workspace claim, if any, for
+	// WorkspaceMiddleware.
+	WorkspaceClaimKey contextKey = "workspace_claim"
+	// ClaimsKey holds the token's *service.Claims.
+	ClaimsKey contextKey = "claims"
 )
 
+// sessionError returns the message for a token that was rejected. Reasons
+// the user can act on are named; anything else is reported generically.
+func sessionError(err error) string {
+	if errors.Is(err, service.ErrAccountDisabled) ||
+		errors.Is(err, service.ErrPasswordResetRequired) ||
+		errors.Is(err, service.ErrEmailNotVerified) ||
+		errors.Is(err, service.ErrSessionExpired) {
+		return err.Error()
+	}
+	return "invalid or expired token"
+}
+
 func AuthMiddleware(authService *service.AuthService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -35,17 +58,98 @@ func AuthMiddleware(authService *service.AuthService) func(http.Handler) http.Ha
 			token := parts[1]
 
 			// Validate token
-			claims, err := authService.ValidateToken(token)
+			claims, err := authService.ValidateSession(r.Context(), token)
 			if err != nil {
-				response.Error(w, http.StatusUnauthorized, "invalid or expired token")
+				response.Error(w, http.StatusUnauthorized, sessionError(err))
 				return
 			}
 
 			// Add user info to context
 			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 			ctx = context.WithValue(ctx, EmailKey, claims.Email)
+			ctx = context.WithValue(ctx, RoleKey, claims.Role)
+			ctx = context.WithValue(ctx, WorkspaceClaimKey, claims.WorkspaceID)
+			ctx = context.WithValue(ctx, ClaimsKey, claims)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequirePermission rejects requests whose user's role lacks the permission
+// with 403; the policy service audits the denial. It must run after
+// authentication.
+func RequirePermission(policyService *service.PolicyService, perm models.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := r.Context().Value(UserIDKey).(uuid.UUID)
+
+			if err := policyService.Authorize(r.Context(), userID, perm, r.Method+" "+r.URL.Path); err != nil {
+				if errors.Is(err, service.ErrPermissionDenied) {
+					response.Error(w, http.StatusForbidden, err.Error())
+					return
+				}
+				response.Error(w, http.StatusInternalServerError, "failed to check permissions")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BasicAuthMiddleware authenticates with HTTP Basic credentials (email and
+// password) for protocols whose clients cannot send a JWT, such as CalDAV.
+// A Bearer token is accepted as well.
+func BasicAuthMiddleware(authService *service.AuthService, realm string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			unauthorized := func() {
+				w.Header().Set("WWW-Authenticate", `Basic realm="`+realm+`", charset="UTF-8"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+			}
+
+			var ctx context.Context
+			if email, password, ok := r.BasicAuth(); ok {
+				user, err := authService.Authenticate(r.Context(), email, password)
+				if err != nil {
+					unauthorized()
+					return
+				}
+				ctx = context.WithValue(r.Context(), UserIDKey, user.ID)
+				ctx = context.WithValue(ctx, EmailKey, user.Email)
+				ctx = context.WithValue(ctx, RoleKey, user.Role)
+			} else if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+				claims, err := authService.ValidateSession(r.Context(), token)
+				if err != nil {
+					unauthorized()
+					return
+				}
+				ctx = context.WithValue(r.Context(), UserIDKey, claims.UserID)
+				ctx = context.WithValue(ctx, EmailKey, claims.Email)
+				ctx = context.WithValue(ctx, RoleKey, claims.Role)
+				ctx = context.WithValue(ctx, WorkspaceClaimKey, claims.WorkspaceID)
+				ctx = context.WithValue(ctx, ClaimsKey, claims)
+			} else {
+				unauthorized()
+				return
+			}
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// TokenFromQuery copies an access_token query parameter into the
+// Authorization header. It exists for browser EventSource connections, which
+// cannot set headers, and must only wrap such streaming routes.
+func TokenFromQuery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			if token := r.URL.Query().Get("access_token"); token != "" {
+				r.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
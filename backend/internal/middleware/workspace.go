@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/service"
+	"github.com/yourusername/todogo-backend/internal/tenant"
+	"github.com/yourusername/todogo-backend/pkg/response"
+)
+
+// WorkspaceHeader names the workspace a request works in. It takes
+// precedence over the token's workspace claim.
+const WorkspaceHeader = "X-Workspace-ID"
+
+// WorkspaceMiddleware scopes the request to the workspace from the
+// X-Workspace-ID header or the token's claim, or else to the user's personal
+// workspace. It must run after authentication.
+func WorkspaceMiddleware(workspaceService *service.WorkspaceService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := r.Context().Value(UserIDKey).(uuid.UUID)
+
+			requested, _ := r.Context().Value(WorkspaceClaimKey).(*uuid.UUID)
+			if header := r.Header.Get(WorkspaceHeader); header != "" {
+				id, err := uuid.Parse(header)
+				if err != nil {
+					response.Error(w, http.StatusBadRequest, "invalid "+WorkspaceHeader+" header")
+					return
+				}
+				requested = &id
+			}
+
+			workspaceID, err := workspaceService.Resolve(r.Context(), userID, requested)
+			if err != nil {
+				if errors.Is(err, service.ErrInvalidWorkspaceScope) {
+					response.Error(w, http.StatusForbidden, err.Error())
+					return
+				}
+				response.Error(w, http.StatusInternalServerError, "failed to resolve workspace")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(tenant.WithWorkspace(r.Context(), workspaceID)))
+		})
+	}
+}
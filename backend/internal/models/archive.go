@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchiveRule archives a user's completed todos automatically once they have
+// been completed for CompletedAfterDays.
+type ArchiveRule struct {
+	UserID             uuid.UUID `json:"-" db:"user_id"`
+	CompletedAfterDays int       `json:"completed_after_days" db:"completed_after_days"`
+	Enabled            bool      `json:"enabled" db:"enabled"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type UpdateArchiveRuleRequest struct {
+	CompletedAfterDays int `json:"completed_after_days" validate:"required,min=1,max=3650"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled"`
+}
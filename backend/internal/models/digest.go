@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DigestFrequency string
+
+const (
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+// DigestSchedule controls when a user receives the email digest of due and
+// overdue todos. SendTime and Weekday are in the schedule's timezone.
+type DigestSchedule struct {
+	UserID    uuid.UUID       `json:"-" db:"user_id"`
+	Frequency DigestFrequency `json:"frequency" db:"frequency"`
+	// Weekday is 0 (Sunday) to 6 (Saturday); only weekly digests use it.
+	Weekday    *int       `json:"weekday" db:"weekday"`
+	SendTime   string     `json:"send_time" db:"send_time"`
+	Timezone   string     `json:"timezone" db:"timezone"`
+	Enabled    bool       `json:"enabled" db:"enabled"`
+	NextRunAt  time.Time  `json:"next_run_at" db:"next_run_at"`
+	Attempts   int        `json:"-" db:"attempts"`
+	LastSentAt *time.Time `json:"last_sent_at" db:"last_sent_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type UpdateDigestRequest struct {
+	Frequency DigestFrequency `json:"frequency" validate:"required,oneof=daily weekly"`
+	Weekday   *int            `json:"weekday" validate:"omitempty,min=0,max=6"`
+	SendTime  string          `json:"send_time" validate:"required,datetime=15:04"`
+	Timezone  string          `json:"timezone" validate:"required,timezone"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled"`
+}
+
+// DigestRecipient is a claimed schedule together with its owner's address.
+type DigestRecipient struct {
+	Schedule *DigestSchedule
+	Name     string
+	Email    string
+}
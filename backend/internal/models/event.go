@@ -0,0 +1,19 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StoredEvent is a todo change kept for a while so that stream clients can
+// resume after a disconnect.
+type StoredEvent struct {
+	ID          int64           `json:"id" db:"id"`
+	UserID      uuid.UUID       `json:"user_id" db:"user_id"`
+	WorkspaceID uuid.UUID       `json:"workspace_id" db:"workspace_id"`
+	EventType   string          `json:"event_type" db:"event_type"`
+	Payload     json.RawMessage `json:"payload" db:"payload"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarFeed is a read-only iCalendar subscription authenticated by a secret
+// token in its URL instead of the Authorization header.
+type CalendarFeed struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	WorkspaceID uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	Name        string    `json:"name" db:"name"`
+	Tag         *string   `json:"tag" db:"tag"`
+	TokenHash   string    `json:"-" db:"token_hash"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	// URL is only populated when a token has just been issued; the plain
+	// token is never stored.
+	URL string `json:"url,omitempty" db:"-"`
+}
+
+type CreateFeedRequest struct {
+	Name string  `json:"name" validate:"required,min=1,max=100"`
+	Tag  *string `json:"tag" validate:"omitempty,min=1,max=100"`
+}
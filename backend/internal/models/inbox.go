@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InboxItemType string
+
+const (
+	InboxAssigned   InboxItemType = "assigned"
+	InboxUnassigned InboxItemType = "unassigned"
+)
+
+// InboxItem tells a user that a todo was assigned to them or taken away.
+type InboxItem struct {
+	ID     uuid.UUID     `json:"id" db:"id"`
+	UserID uuid.UUID     `json:"-" db:"user_id"`
+	Type   InboxItemType `json:"type" db:"type"`
+	TodoID uuid.UUID     `json:"todo_id" db:"todo_id"`
+	// WorkspaceID is the todo's workspace, which requests for it must use.
+	WorkspaceID uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	TodoTitle   string    `json:"todo_title" db:"todo_title"`
+	// ActorID is the owner who changed the assignment; nil once their
+	// account is gone.
+	ActorID   *uuid.UUID `json:"actor_id" db:"actor_id"`
+	ReadAt    *time.Time `json:"read_at" db:"read_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+type InboxResult struct {
+	Unread int          `json:"unread"`
+	Items  []*InboxItem `json:"items"`
+}
+
+// AssignTodoRequest assigns a todo to the user with the given email, or
+// unassigns it when Email is null.
+type AssignTodoRequest struct {
+	Email *string `json:"email" validate:"omitempty,email"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is a user's account-wide role. It decides which permissions the user
+// has, independent of what they own.
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+	RoleGuest  Role = "guest"
+)
+
+type Permission string
+
+const (
+	PermTodoRead        Permission = "todo:read"
+	PermTodoCreate      Permission = "todo:create"
+	PermTodoUpdate      Permission = "todo:update"
+	PermTodoDelete      Permission = "todo:delete"
+	PermTodoAssign      Permission = "todo:assign"
+	PermTagManage       Permission = "tag:manage"
+	PermFeedManage      Permission = "feed:manage"
+	PermWebhookManage   Permission = "webhook:manage"
+	PermWorkspaceManage Permission = "workspace:manage"
+	PermUserManage      Permission = "user:manage"
+	PermAuditRead       Permission = "audit:read"
+)
+
+type RoleInfo struct {
+	Role        Role         `json:"role"`
+	Permissions []Permission `json:"permissions"`
+}
+
+type UpdateRoleRequest struct {
+	Role Role `json:"role" validate:"required,oneof=admin member guest"`
+}
+
+type AuditAction string
+
+const (
+	AuditAccessDenied  AuditAction = "access.denied"
+	AuditRoleChanged   AuditAction = "role.changed"
+	AuditUserDisabled  AuditAction = "user.disabled"
+	AuditUserEnabled   AuditAction = "user.enabled"
+	AuditPasswordReset AuditAction = "user.password_reset"
+	AuditUserDeleted   AuditAction = "user.deleted"
+	AuditTokenReused   AuditAction = "token.reused"
+	AuditMFAEnabled    AuditAction = "mfa.enabled"
+	AuditMFADisabled   AuditAction = "mfa.disabled"
+	AuditSSOLinked     AuditAction = "sso.linked"
+)
+
+// AuditEntry records a security-relevant event. Target names what the action
+// was about, such as "todo:<id>", "user:<id>" or an HTTP route.
+type AuditEntry struct {
+	ID int64 `json:"id" db:"id"`
+	// ActorID is nil once the user's account is gone.
+	ActorID   *uuid.UUID      `json:"actor_id" db:"actor_id"`
+	Action    AuditAction     `json:"action" db:"action"`
+	Target    string          `json:"target" db:"target"`
+	Details   json.RawMessage `json:"details" db:"details"`
+	RequestID string          `json:"request_id" db:"request_id"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
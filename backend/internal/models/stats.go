@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// StatsOptions selects the window and timezone of the productivity stats.
+type StatsOptions struct {
+	// Days is the length of the window, ending today in Timezone.
+	Days     int
+	Timezone string
+}
+
+type TodoStats struct {
+	Timezone   string         `json:"timezone"`
+	Window     StatsWindow    `json:"window"`
+	Total      int            `json:"total"`
+	ByStatus   map[string]int `json:"by_status"`
+	ByPriority map[string]int `json:"by_priority"`
+	ByTag      []TagCount     `json:"by_tag"`
+	// Overdue counts pending todos whose due date has passed.
+	Overdue    int            `json:"overdue"`
+	Completion CompletionRate `json:"completion"`
+	// MedianCompletionSeconds is the median time from creation to completion
+	// of the todos completed in the window, or null if there are none.
+	MedianCompletionSeconds *float64         `json:"median_completion_seconds"`
+	DailyCompletions        []DailyCount     `json:"daily_completions"`
+	Streaks                 CompletionStreak `json:"streaks"`
+}
+
+type StatsWindow struct {
+	Days int `json:"days"`
+	// From and To are local calendar dates (YYYY-MM-DD), both inclusive.
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Start is the first instant of the window.
+	Start time.Time `json:"start"`
+}
+
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// CompletionRate covers the todos created in the window.
+type CompletionRate struct {
+	Created   int     `json:"created"`
+	Completed int     `json:"completed"`
+	Rate      float64 `json:"rate"`
+}
+
+type DailyCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// CompletionStreak counts consecutive local days with at least one
+// completion. The current streak survives until a full day passes without
+// one, so it still counts on a day with no completions yet.
+type CompletionStreak struct {
+	Current int `json:"current"`
+	Longest int `json:"longest"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SyncStrategy string
+
+const (
+	// SyncLastWriterWins applies a mutation as a whole if it is based on the
+	// current version or is newer than the server copy.
+	SyncLastWriterWins SyncStrategy = "lww"
+	// SyncFieldMerge applies every field the server has not changed since the
+	// mutation's base version; concurrently changed fields fall back to
+	// last-writer-wins.
+	SyncFieldMerge SyncStrategy = "merge"
+)
+
+type SyncOp string
+
+const (
+	SyncOpUpsert SyncOp = "upsert"
+	SyncOpDelete SyncOp = "delete"
+)
+
+type SyncResultStatus string
+
+const (
+	SyncApplied  SyncResultStatus = "applied"
+	SyncMerged   SyncResultStatus = "merged"
+	SyncConflict SyncResultStatus = "conflict"
+	SyncError    SyncResultStatus = "error"
+)
+
+// SyncMutation is one offline change. Fields holds only the fields the client
+// changed; supported are title, description, priority, due_date, tags and
+// completed.
+type SyncMutation struct {
+	Op          SyncOp                     `json:"op" validate:"required,oneof=upsert delete"`
+	ID          uuid.UUID                  `json:"id" validate:"required"`
+	BaseVersion int                        `json:"base_version" validate:"min=0"`
+	UpdatedAt   time.Time                  `json:"updated_at" validate:"required"`
+	Fields      map[string]json.RawMessage `json:"fields"`
+}
+
+type SyncRequest struct {
+	SyncToken string         `json:"sync_token"`
+	Strategy  SyncStrategy   `json:"strategy" validate:"omitempty,oneof=lww merge"`
+	Mutations []SyncMutation `json:"mutations" validate:"max=500,dive"`
+}
+
+type SyncResult struct {
+	ID     uuid.UUID        `json:"id"`
+	Status SyncResultStatus `json:"status"`
+	// Todo is the server copy after the mutation; Deleted is set instead
+	// when the todo no longer exists.
+	Todo      *Todo          `json:"todo,omitempty"`
+	Deleted   *TodoTombstone `json:"deleted,omitempty"`
+	Discarded []string       `json:"discarded_fields,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+type SyncResponse struct {
+	Changed   []*Todo          `json:"changed"`
+	Deleted   []*TodoTombstone `json:"deleted"`
+	SyncToken string           `json:"sync_token"`
+	Results   []SyncResult     `json:"results,omitempty"`
+}
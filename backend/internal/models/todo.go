@@ -25,17 +25,44 @@ const (
 
 type Todo struct {
 	ID          uuid.UUID     `json:"id" db:"id"`
+	WorkspaceID uuid.UUID     `json:"workspace_id" db:"workspace_id"`
 	Title       string        `json:"title" db:"title" validate:"required,min=1,max=200"`
 	Description *string       `json:"description" db:"description" validate:"omitempty,max=1000"`
 	Completed   bool          `json:"completed" db:"completed"`
 	Status      TodoStatus    `json:"status" db:"status"`
 	Priority    TodoPriority  `json:"priority" db:"priority"`
 	UserID      uuid.UUID     `json:"user_id" db:"user_id"`
+	AssigneeID  *uuid.UUID    `json:"assignee_id" db:"assignee_id"`
 	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at"`
 	CompletedAt *time.Time    `json:"completed_at" db:"completed_at"`
+	ArchivedAt  *time.Time    `json:"archived_at" db:"archived_at"`
 	DueDate     *time.Time    `json:"due_date" db:"due_date"`
 	Tags        pq.StringArray `json:"tags" db:"tags"`
+	ICalUID     *string        `json:"ical_uid,omitempty" db:"ical_uid"`
+	SyncSeq     int64          `json:"-" db:"sync_seq"`
+	Version     int            `json:"version" db:"version"`
+	// FieldVersions maps each editable field to the version that last
+	// changed it.
+	FieldVersions map[string]int `json:"-" db:"field_versions"`
+}
+
+// FieldVersion returns the version that last changed the field; fields never
+// changed since creation report version 1.
+func (t *Todo) FieldVersion(field string) int {
+	if v, ok := t.FieldVersions[field]; ok {
+		return v
+	}
+	return 1
+}
+
+// TodoTombstone records a deleted todo for clients that sync incrementally.
+type TodoTombstone struct {
+	TodoID    uuid.UUID `json:"id" db:"todo_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	ICalUID   *string   `json:"ical_uid,omitempty" db:"ical_uid"`
+	DeletedAt time.Time `json:"deleted_at" db:"deleted_at"`
+	SyncSeq   int64     `json:"-" db:"sync_seq"`
 }
 
 type CreateTodoRequest struct {
@@ -44,6 +71,8 @@ type CreateTodoRequest struct {
 	Priority    *TodoPriority `json:"priority" validate:"omitempty,oneof=low medium high"`
 	DueDate     *time.Time    `json:"due_date"`
 	Tags        []string      `json:"tags"`
+	// ICalUID is set by calendar importers, never from request bodies.
+	ICalUID *string `json:"-"`
 }
 
 type UpdateTodoRequest struct {
@@ -54,11 +83,35 @@ type UpdateTodoRequest struct {
 	Tags        []string      `json:"tags"`
 }
 
+// DuplicateTodoRequest chooses what a copy takes over from the original.
+// Completion is reset unless set to false. Tags are kept by default when the
+// user may manage tags.
+type DuplicateTodoRequest struct {
+	Title           *string `json:"title" validate:"omitempty,min=1,max=200"`
+	KeepTags        *bool   `json:"keep_tags"`
+	ResetCompletion *bool   `json:"reset_completion"`
+	// DueOffsetDays moves the copy's due date by whole days.
+	DueOffsetDays int `json:"due_offset_days" validate:"min=-3650,max=3650"`
+}
+
 type TodoFilters struct {
 	Status   *TodoStatus   `json:"status"`
 	Priority *TodoPriority `json:"priority"`
 	Search   *string       `json:"search"`
 	Tags     []string      `json:"tags"`
+	// DueFrom and DueBefore bound due dates; NoDueDate selects todos
+	// without one.
+	DueFrom   *time.Time `json:"due_from"`
+	DueBefore *time.Time `json:"due_before"`
+	NoDueDate bool       `json:"no_due_date"`
+	// Archived lists archived todos instead of active ones.
+	Archived bool `json:"archived"`
+	// AssignedToMe lists other users' todos assigned to the user instead
+	// of the user's own.
+	AssignedToMe bool `json:"assigned_to_me"`
+	// Sort defaults to newest first.
+	Sort      ViewSortField `json:"sort"`
+	Direction SortDirection `json:"direction"`
 }
 
 // Scan implements sql.Scanner interface
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UndoEntry records one todo changed by an undoable action. Before is the
+// stored row as it was, or empty when the action created the todo; Version
+// is the version the action left the todo at, or 0 when it deleted it.
+type UndoEntry struct {
+	TodoID  uuid.UUID       `json:"todo_id"`
+	Before  json.RawMessage `json:"before,omitempty"`
+	Version int             `json:"version"`
+}
+
+// UndoAction is the set of changes an undo token reverts.
+type UndoAction struct {
+	UserID    uuid.UUID   `json:"-" db:"user_id"`
+	Action    string      `json:"action" db:"action"`
+	Entries   []UndoEntry `json:"-" db:"entries"`
+	ExpiresAt time.Time   `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
+
+// UndoResult reports what an undo changed: todos recreated after a delete,
+// todos put back to their earlier state, and todos removed because the
+// action created them.
+type UndoResult struct {
+	Action   string  `json:"action"`
+	Restored []*Todo `json:"restored"`
+	Reverted []*Todo `json:"reverted"`
+	Removed  []*Todo `json:"removed"`
+}
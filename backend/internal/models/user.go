@@ -7,12 +7,44 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	Name      string    `json:"name" db:"name" validate:"required,min=2,max=255"`
-	Email     string    `json:"email" db:"email" validate:"required,email"`
-	Password  string    `json:"-" db:"password" validate:"required,min=6"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID       uuid.UUID `json:"id" db:"id"`
+	Name     string    `json:"name" db:"name" validate:"required,min=2,max=255"`
+	Email    string    `json:"email" db:"email" validate:"required,email"`
+	Password string    `json:"-" db:"password" validate:"required,min=6"`
+	Role     Role      `json:"role" db:"role"`
+	// DisabledAt is set while an admin has disabled the account.
+	DisabledAt *time.Time `json:"disabled_at" db:"disabled_at"`
+	// PasswordResetRequired blocks sign-in until the user picks a new
+	// password.
+	PasswordResetRequired bool       `json:"password_reset_required" db:"password_reset_required"`
+	PasswordChangedAt     *time.Time `json:"-" db:"password_changed_at"`
+	// EmailVerifiedAt is nil until the user follows the link sent to their
+	// current address.
+	EmailVerifiedAt *time.Time `json:"email_verified_at" db:"email_verified_at"`
+	// TOTPEnabledAt is set while sign-in asks for a TOTP code.
+	TOTPEnabledAt *time.Time `json:"totp_enabled_at" db:"totp_enabled_at"`
+	// TokenGeneration is bumped by signing out everywhere; tokens issued
+	// with an older generation are rejected.
+	TokenGeneration int       `json:"-" db:"token_generation"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// AdminUser is a user as listed to admins, with their todo counts.
+type AdminUser struct {
+	User
+	TodoCount      int `json:"todo_count"`
+	CompletedCount int `json:"completed_count"`
+}
+
+// UserFilters narrows the admin user listing.
+type UserFilters struct {
+	// Search matches name and email.
+	Search   *string
+	Role     *Role
+	Disabled *bool
+	Limit    int
+	Offset   int
 }
 
 type RegisterRequest struct {
@@ -26,7 +58,169 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required,min=6"`
 }
 
+// ChangePasswordRequest sets a new password with the current one. It works
+// without a token so that users who must reset their password can do so.
+type ChangePasswordRequest struct {
+	Email           string `json:"email" validate:"required,email"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6,nefield=CurrentPassword"`
+	// Code is a TOTP or recovery code, required with two-factor
+	// authentication.
+	Code string `json:"code,omitempty"`
+}
+
+// ChangeEmailRequest moves the account to a new address, which has to be
+// verified again.
+type ChangeEmailRequest struct {
+	Email           string `json:"email" validate:"required,email"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// EmailVerificationToken verifies Email for the user, as long as it is still
+// their address.
+type EmailVerificationToken struct {
+	ID        uuid.UUID `db:"id"`
+	UserID    uuid.UUID `db:"user_id"`
+	Email     string    `db:"email"`
+	TokenHash string    `db:"token_hash"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// LoginResponse signs the user in. The tokens are left out when the user
+// registered but must verify their email address first.
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	// MFARequired is set instead of the tokens when the user has two-factor
+	// authentication: exchange MFAToken and a code for them.
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+	Token       string `json:"token,omitempty"`
+	// ExpiresAt is when Token expires; renew it with RefreshToken before
+	// then.
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	RefreshToken string     `json:"refresh_token,omitempty"`
+	User         User       `json:"user"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=6"`
+}
+
+// PasswordResetToken lets the user set a new password once before it
+// expires.
+type PasswordResetToken struct {
+	ID        uuid.UUID  `db:"id"`
+	UserID    uuid.UUID  `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// TOTPEnrollment is what authenticator apps need to add the account.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// MFACodeRequest carries a TOTP code or, where accepted, a recovery code.
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTOTPRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	Code            string `json:"code" validate:"required"`
+}
+
+// MFAVerifyRequest completes a sign-in that asked for a second factor.
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// RecoveryCodes each sign in once in place of a TOTP code. They are only
+// shown when generated.
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+// MFAChallenge is a sign-in waiting for its second factor.
+type MFAChallenge struct {
+	ID        uuid.UUID `db:"id"`
+	UserID    uuid.UUID `db:"user_id"`
+	TokenHash string    `db:"token_hash"`
+	Attempts  int       `db:"attempts"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// LogoutRequest optionally names the refresh token to revoke along with the
+// access token.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// RefreshToken renews access tokens. Each one can be used once; using it
+// issues its successor in the same family.
+type RefreshToken struct {
+	ID          uuid.UUID  `db:"id"`
+	UserID      uuid.UUID  `db:"user_id"`
+	FamilyID    uuid.UUID  `db:"family_id"`
+	TokenHash   string     `db:"token_hash"`
+	WorkspaceID *uuid.UUID `db:"workspace_id"`
+	ExpiresAt   time.Time  `db:"expires_at"`
+	UsedAt      *time.Time `db:"used_at"`
+	RevokedAt   *time.Time `db:"revoked_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
+
+// OIDCAuthorization starts a single sign-on. The web app keeps State and
+// checks that the provider returns it.
+type OIDCAuthorization struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+// OIDCCallbackRequest completes a single sign-on with what the provider
+// redirected back with.
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+// OIDCState is a single sign-on waiting for the provider to redirect back.
+type OIDCState struct {
+	StateHash    string    `db:"state_hash"`
+	Nonce        string    `db:"nonce"`
+	CodeVerifier string    `db:"code_verifier"`
+	ExpiresAt    time.Time `db:"expires_at"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// UserIdentity links a user to their account at an OpenID Connect provider.
+type UserIdentity struct {
+	ID        uuid.UUID `db:"id"`
+	UserID    uuid.UUID `db:"user_id"`
+	Issuer    string    `db:"issuer"`
+	Subject   string    `db:"subject"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
 }
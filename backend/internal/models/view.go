@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+)
+
+type ViewSortField string
+
+const (
+	SortCreatedAt ViewSortField = "created_at"
+	SortUpdatedAt ViewSortField = "updated_at"
+	SortDueDate   ViewSortField = "due_date"
+	SortPriority  ViewSortField = "priority"
+	SortTitle     ViewSortField = "title"
+)
+
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+)
+
+type ViewGroupBy string
+
+const (
+	GroupNone     ViewGroupBy = "none"
+	GroupStatus   ViewGroupBy = "status"
+	GroupPriority ViewGroupBy = "priority"
+	GroupDueDate  ViewGroupBy = "due_date"
+	GroupTag      ViewGroupBy = "tag"
+)
+
+// View is a saved filter, sort and grouping of todos. Built-in views have
+// a fixed slug as ID and cannot be changed.
+type View struct {
+	ID        string        `json:"id" db:"id"`
+	Name      string        `json:"name" db:"name"`
+	Filter    ViewFilter    `json:"filter" db:"filter"`
+	Sort      ViewSortField `json:"sort" db:"sort"`
+	Direction SortDirection `json:"direction" db:"direction"`
+	GroupBy   ViewGroupBy   `json:"group_by" db:"group_by"`
+	BuiltIn   bool          `json:"built_in" db:"-"`
+	CreatedAt *time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt *time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+type ViewFilter struct {
+	Status   *TodoStatus   `json:"status,omitempty" validate:"omitempty,oneof=pending completed"`
+	Priority *TodoPriority `json:"priority,omitempty" validate:"omitempty,oneof=low medium high"`
+	// Tags matches todos with any of the tags.
+	Tags   []string  `json:"tags,omitempty" validate:"omitempty,dive,min=1"`
+	Search *string   `json:"search,omitempty" validate:"omitempty,max=200"`
+	Due    *DueRange `json:"due,omitempty"`
+}
+
+// DueRange bounds due dates. Absolute bounds are timestamps; relative bounds
+// count days from the start of today in the timezone the view is evaluated
+// in, so {"from_days": 0, "to_days": 1} is today. Lower bounds are
+// inclusive and upper bounds exclusive.
+type DueRange struct {
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	FromDays *int       `json:"from_days,omitempty" validate:"omitempty,min=-3650,max=3650"`
+	ToDays   *int       `json:"to_days,omitempty" validate:"omitempty,min=-3650,max=3650"`
+	// None selects todos without a due date; the bounds are then ignored.
+	None bool `json:"none,omitempty"`
+}
+
+type CreateViewRequest struct {
+	Name      string        `json:"name" validate:"required,min=1,max=100"`
+	Filter    ViewFilter    `json:"filter"`
+	Sort      ViewSortField `json:"sort" validate:"omitempty,oneof=created_at updated_at due_date priority title"`
+	Direction SortDirection `json:"direction" validate:"omitempty,oneof=asc desc"`
+	GroupBy   ViewGroupBy   `json:"group_by" validate:"omitempty,oneof=none status priority due_date tag"`
+}
+
+type UpdateViewRequest struct {
+	Name      *string        `json:"name" validate:"omitempty,min=1,max=100"`
+	Filter    *ViewFilter    `json:"filter"`
+	Sort      *ViewSortField `json:"sort" validate:"omitempty,oneof=created_at updated_at due_date priority title"`
+	Direction *SortDirection `json:"direction" validate:"omitempty,oneof=asc desc"`
+	GroupBy   *ViewGroupBy   `json:"group_by" validate:"omitempty,oneof=none status priority due_date tag"`
+}
+
+// ViewResult is a view evaluated against the user's todos. Ungrouped views
+// return a single group with an empty key.
+type ViewResult struct {
+	View     *View       `json:"view"`
+	Timezone string      `json:"timezone"`
+	Total    int         `json:"total"`
+	Groups   []TodoGroup `json:"groups"`
+}
+
+type TodoGroup struct {
+	Key   string  `json:"key"`
+	Todos []*Todo `json:"todos"`
+}
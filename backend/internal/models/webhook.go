@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	DeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// Webhook is an endpoint that receives signed todo events.
+type Webhook struct {
+	ID     uuid.UUID      `json:"id" db:"id"`
+	UserID uuid.UUID      `json:"user_id" db:"user_id"`
+	URL    string         `json:"url" db:"url"`
+	Events pq.StringArray `json:"events" db:"events"`
+	// Secret signs deliveries. It is only returned when the webhook is created.
+	Secret       string     `json:"secret,omitempty" db:"secret"`
+	Active       bool       `json:"active" db:"active"`
+	FailureCount int        `json:"failure_count" db:"failure_count"`
+	DisabledAt   *time.Time `json:"disabled_at" db:"disabled_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookDelivery is one event queued for, or sent to, a webhook endpoint.
+type WebhookDelivery struct {
+	ID             uuid.UUID             `json:"id" db:"id"`
+	WebhookID      uuid.UUID             `json:"webhook_id" db:"webhook_id"`
+	EventID        uuid.UUID             `json:"event_id" db:"event_id"`
+	EventType      string                `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage       `json:"payload" db:"payload"`
+	Status         WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                   `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at" db:"next_attempt_at"`
+	LastStatusCode *int                  `json:"last_status_code" db:"last_status_code"`
+	LastError      *string               `json:"last_error" db:"last_error"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at" db:"delivered_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url,max=2000"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=todo.created todo.updated todo.completed todo.deleted todo.assigned"`
+}
+
+type UpdateWebhookRequest struct {
+	URL    *string  `json:"url" validate:"omitempty,url,max=2000"`
+	Events []string `json:"events" validate:"omitempty,min=1,dive,oneof=todo.created todo.updated todo.completed todo.deleted todo.assigned"`
+	// Active re-enables an endpoint that was disabled after repeated failures.
+	Active *bool `json:"active"`
+}
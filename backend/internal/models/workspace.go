@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WorkspaceRole string
+
+const (
+	WorkspaceRoleOwner  WorkspaceRole = "owner"
+	WorkspaceRoleMember WorkspaceRole = "member"
+)
+
+// Workspace partitions todos between teams. Every user has a personal
+// workspace that cannot be shared, renamed or deleted.
+type Workspace struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	Name     string    `json:"name" db:"name"`
+	Personal bool      `json:"personal" db:"-"`
+	// Role is the caller's role in the workspace.
+	Role      WorkspaceRole `json:"role" db:"role"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+type WorkspaceMember struct {
+	UserID   uuid.UUID     `json:"user_id" db:"user_id"`
+	Name     string        `json:"name" db:"name"`
+	Email    string        `json:"email" db:"email"`
+	Role     WorkspaceRole `json:"role" db:"role"`
+	JoinedAt time.Time     `json:"joined_at" db:"joined_at"`
+}
+
+// WorkspaceInvitation lets the user with the given email join a workspace
+// until it expires.
+type WorkspaceInvitation struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	Email       string     `json:"email" db:"email"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	InvitedBy   *uuid.UUID `json:"invited_by" db:"invited_by"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at" db:"accepted_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	// Token is only populated when the invitation has just been created; the
+	// plain token is never stored.
+	Token string `json:"token,omitempty" db:"-"`
+}
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+type UpdateWorkspaceRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
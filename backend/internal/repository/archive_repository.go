@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+type ArchiveRepository struct {
+	db *database.DB
+}
+
+func NewArchiveRepository(db *database.DB) *ArchiveRepository {
+	return &ArchiveRepository{db: db}
+}
+
+func (r *ArchiveRepository) GetRule(ctx context.Context, userID uuid.UUID) (*models.ArchiveRule, error) {
+	query := `
+		SELECT user_id, completed_after_days, enabled, created_at, updated_at
+		FROM archive_rules WHERE user_id = $1
+	`
+
+	rule := &models.ArchiveRule{}
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&rule.UserID,
+		&rule.CompletedAfterDays,
+		&rule.Enabled,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// UpsertRule creates or replaces the user's rule.
+func (r *ArchiveRepository) UpsertRule(ctx context.Context, rule *models.ArchiveRule) error {
+	query := `
+		INSERT INTO archive_rules (user_id, completed_after_days, enabled)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET completed_after_days = EXCLUDED.completed_after_days,
+			enabled = EXCLUDED.enabled,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		rule.UserID,
+		rule.CompletedAfterDays,
+		rule.Enabled,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+}
+
+func (r *ArchiveRepository) DeleteRule(ctx context.Context, userID uuid.UUID) error {
+	query := `DELETE FROM archive_rules WHERE user_id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+// auditRetention is how long audit entries are kept.
+const auditRetention = 90 * 24 * time.Hour
+
+type AuditRepository struct {
+	db *database.DB
+}
+
+func NewAuditRepository(db *database.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create stores an entry and drops those past retention on the way.
+func (r *AuditRepository) Create(ctx context.Context, entry *models.AuditEntry) error {
+	now := time.Now().UTC()
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM audit_log WHERE created_at < $1`, now.Add(-auditRetention)); err != nil {
+		return err
+	}
+
+	details := entry.Details
+	if len(details) == 0 {
+		details = []byte("{}")
+	}
+
+	query := `
+		INSERT INTO audit_log (actor_id, action, target, details, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		entry.ActorID,
+		entry.Action,
+		entry.Target,
+		[]byte(details),
+		entry.RequestID,
+		now,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// GetRecent returns up to limit of the newest entries, optionally only
+// those with the given action.
+func (r *AuditRepository) GetRecent(ctx context.Context, action *models.AuditAction, limit int) ([]*models.AuditEntry, error) {
+	query := `
+		SELECT id, actor_id, action, target, details, request_id, created_at
+		FROM audit_log
+		WHERE ($1::text IS NULL OR action = $1)
+		ORDER BY id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, action, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.AuditEntry{}
+	for rows.Next() {
+		entry := &models.AuditEntry{}
+		var details []byte
+		if err := rows.Scan(&entry.ID, &entry.ActorID, &entry.Action, &entry.Target, &details, &entry.RequestID, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entry.Details = details
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+type DigestRepository struct {
+	db *database.DB
+}
+
+func NewDigestRepository(db *database.DB) *DigestRepository {
+	return &DigestRepository{db: db}
+}
+
+const digestColumns = `d.user_id, d.frequency, d.weekday, to_char(d.send_time, 'HH24:MI'), d.timezone, d.enabled, d.next_run_at, d.attempts, d.last_sent_at, d.created_at, d.updated_at`
+
+func scanDigestSchedule(row rowScanner, extra ...interface{}) (*models.DigestSchedule, error) {
+	s := &models.DigestSchedule{}
+	dest := []interface{}{
+		&s.UserID,
+		&s.Frequency,
+		&s.Weekday,
+		&s.SendTime,
+		&s.Timezone,
+		&s.Enabled,
+		&s.NextRunAt,
+		&s.Attempts,
+		&s.LastSentAt,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *DigestRepository) Get(ctx context.Context, userID uuid.UUID) (*models.DigestSchedule, error) {
+	query := `SELECT ` + digestColumns + ` FROM digest_schedules d WHERE d.user_id = $1`
+
+	s, err := scanDigestSchedule(r.db.QueryRowContext(ctx, query, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// Upsert creates or replaces the user's schedule and resets pending retries.
+func (r *DigestRepository) Upsert(ctx context.Context, s *models.DigestSchedule) error {
+	query := `
+		INSERT INTO digest_schedules (user_id, frequency, weekday, send_time, timezone, enabled, next_run_at)
+		VALUES ($1, $2, $3, $4::time, $5, $6, $7)
+		ON CONFLICT (user_id) DO UPDATE
+		SET frequency = EXCLUDED.frequency,
+			weekday = EXCLUDED.weekday,
+			send_time = EXCLUDED.send_time,
+			timezone = EXCLUDED.timezone,
+			enabled = EXCLUDED.enabled,
+			next_run_at = EXCLUDED.next_run_at,
+			attempts = 0,
+			updated_at = NOW()
+		RETURNING last_sent_at, created_at, updated_at
+	`
+
+	s.Attempts = 0
+	return r.db.QueryRowContext(ctx, query,
+		s.UserID,
+		s.Frequency,
+		s.Weekday,
+		s.SendTime,
+		s.Timezone,
+		s.Enabled,
+		s.NextRunAt,
+	).Scan(&s.LastSentAt, &s.CreatedAt, &s.UpdatedAt)
+}
+
+func (r *DigestRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	query := `DELETE FROM digest_schedules WHERE user_id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ClaimDue leases up to limit enabled schedules whose run is due by pushing
+// their next run into the future and counting the attempt. Concurrent
+// workers, also on other instances, never claim the same schedule. Disabled
+// users and unverified addresses are skipped until that changes.
+func (r *DigestRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.DigestRecipient, error) {
+	query := `
+		UPDATE digest_schedules d
+		SET next_run_at = $1, attempts = d.attempts + 1
+		FROM users u
+		WHERE u.id = d.user_id AND u.disabled_at IS NULL AND u.email_verified_at IS NOT NULL AND d.user_id IN (
+			SELECT s.user_id FROM digest_schedules s
+			JOIN users su ON su.id = s.user_id
+			WHERE s.enabled AND s.next_run_at <= $2
+				AND su.disabled_at IS NULL AND su.email_verified_at IS NOT NULL
+			ORDER BY s.next_run_at
+			LIMIT $3
+			FOR UPDATE OF s SKIP LOCKED
+		)
+		RETURNING ` + digestColumns + `, u.name, u.email`
+
+	// Run times are computed across timezones and stored as UTC.
+	now := time.Now().UTC()
+	rows, err := r.db.QueryContext(ctx, query, now.Add(lease), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipients := []*models.DigestRecipient{}
+	for rows.Next() {
+		recipient := &models.DigestRecipient{}
+		recipient.Schedule, err = scanDigestSchedule(rows, &recipient.Name, &recipient.Email)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	return recipients, rows.Err()
+}
+
+// Reschedule sets the next regular run after a digest was sent, or skipped
+// for good, and clears the attempt counter.
+func (r *DigestRepository) Reschedule(ctx context.Context, userID uuid.UUID, nextRunAt time.Time, sentAt *time.Time) error {
+	query := `
+		UPDATE digest_schedules
+		SET next_run_at = $1, attempts = 0, last_sent_at = COALESCE($2, last_sent_at)
+		WHERE user_id = $3
+	`
+
+	_, err := r.db.ExecContext(ctx, query, nextRunAt, sentAt, userID)
+	return err
+}
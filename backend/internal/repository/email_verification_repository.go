@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+// verificationHistory is how long sent verification tokens are kept around
+// to count them against the resend limits.
+const verificationHistory = 24 * time.Hour
+
+type EmailVerificationRepository struct {
+	db *database.DB
+}
+
+func NewEmailVerificationRepository(db *database.DB) *EmailVerificationRepository {
+	return &EmailVerificationRepository{db: db}
+}
+
+// Create stores a new verification token. Tokens sent to the user longer
+// than a day ago are dropped on the way.
+func (r *EmailVerificationRepository) Create(ctx context.Context, t *models.EmailVerificationToken) error {
+	now := time.Now().UTC()
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM email_verification_tokens WHERE user_id = $1 AND created_at < $2`,
+		t.UserID, now.Add(-verificationHistory))
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO email_verification_tokens (id, user_id, email, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	t.ID = uuid.New()
+	t.CreatedAt = now
+
+	_, err = r.db.ExecContext(ctx, query, t.ID, t.UserID, t.Email, t.TokenHash, t.ExpiresAt.UTC(), t.CreatedAt)
+	return err
+}
+
+// CountSince returns how many verification tokens were sent to the user
+// since the time, and when the last one was.
+func (r *EmailVerificationRepository) CountSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, *time.Time, error) {
+	query := `
+		SELECT COUNT(*), MAX(created_at)
+		FROM email_verification_tokens
+		WHERE user_id = $1 AND created_at >= $2
+	`
+
+	var count int
+	var last *time.Time
+	err := r.db.QueryRowContext(ctx, query, userID, since.UTC()).Scan(&count, &last)
+	return count, last, err
+}
+
+// Consume deletes the unexpired token with the hash and returns it, or nil
+// when there is none.
+func (r *EmailVerificationRepository) Consume(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error) {
+	query := `
+		DELETE FROM email_verification_tokens
+		WHERE token_hash = $1 AND expires_at > $2
+		RETURNING id, user_id, email, token_hash, expires_at, created_at
+	`
+
+	t := &models.EmailVerificationToken{}
+	err := r.db.QueryRowContext(ctx, query, tokenHash, time.Now().UTC()).Scan(
+		&t.ID,
+		&t.UserID,
+		&t.Email,
+		&t.TokenHash,
+		&t.ExpiresAt,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// DeleteForUser drops the user's verification tokens.
+func (r *EmailVerificationRepository) DeleteForUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM email_verification_tokens WHERE user_id = $1`, userID)
+	return err
+}
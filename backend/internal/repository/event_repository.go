@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+// EventsChannel is the Postgres NOTIFY channel announcing new todo events.
+const EventsChannel = "todo_events"
+
+type EventRepository struct {
+	db *database.DB
+}
+
+func NewEventRepository(db *database.DB) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+// EventNotification is the NOTIFY payload. The event itself is loaded from
+// the table because NOTIFY payloads are limited to 8000 bytes.
+type EventNotification struct {
+	ID     int64     `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// Create stores an event and notifies every listening instance in the same
+// statement, so a notification is only sent for committed events.
+func (r *EventRepository) Create(ctx context.Context, event *models.StoredEvent) error {
+	query := `
+		WITH inserted AS (
+			INSERT INTO todo_events (user_id, workspace_id, event_type, payload, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, user_id
+		)
+		SELECT id, pg_notify('` + EventsChannel + `', json_build_object('id', id, 'user_id', user_id)::text)
+		FROM inserted
+	`
+
+	event.CreatedAt = time.Now()
+
+	var notified interface{}
+	return r.db.QueryRowContext(ctx, query,
+		event.UserID,
+		event.WorkspaceID,
+		event.EventType,
+		[]byte(event.Payload),
+		event.CreatedAt,
+	).Scan(&event.ID, &notified)
+}
+
+func (r *EventRepository) GetByID(ctx context.Context, id int64) (*models.StoredEvent, error) {
+	query := `SELECT id, user_id, workspace_id, event_type, payload, created_at FROM todo_events WHERE id = $1`
+
+	event := &models.StoredEvent{}
+	var payload []byte
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&event.ID, &event.UserID, &event.WorkspaceID, &event.EventType, &payload, &event.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	event.Payload = payload
+
+	return event, nil
+}
+
+// GetSince returns up to limit events of the user in the context's
+// workspace after the given event ID.
+func (r *EventRepository) GetSince(ctx context.Context, userID uuid.UUID, afterID int64, limit int) ([]*models.StoredEvent, error) {
+	query := `
+		SELECT id, user_id, workspace_id, event_type, payload, created_at
+		FROM todo_events
+		WHERE user_id = $1 AND ($2::uuid IS NULL OR workspace_id = $2) AND id > $3
+		ORDER BY id
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, workspaceArg(ctx), afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*models.StoredEvent{}
+	for rows.Next() {
+		event := &models.StoredEvent{}
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.UserID, &event.WorkspaceID, &event.EventType, &payload, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *EventRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM todo_events WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Listen delivers event notifications to handle until ctx is cancelled. After
+// a reconnect, handle receives a zero notification so that callers can catch
+// up on anything missed while disconnected.
+func (r *EventRepository) Listen(ctx context.Context, handle func(EventNotification)) error {
+	listener, err := r.db.NewListener(EventsChannel)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			if n == nil {
+				// Connection was re-established.
+				handle(EventNotification{})
+				continue
+			}
+			var notification EventNotification
+			if err := json.Unmarshal([]byte(n.Extra), &notification); err != nil {
+				continue
+			}
+			handle(notification)
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+type FeedRepository struct {
+	db *database.DB
+}
+
+func NewFeedRepository(db *database.DB) *FeedRepository {
+	return &FeedRepository{db: db}
+}
+
+const feedColumns = `id, workspace_id, user_id, name, tag, token_hash, created_at, updated_at`
+
+func scanFeed(row rowScanner) (*models.CalendarFeed, error) {
+	feed := &models.CalendarFeed{}
+	err := row.Scan(
+		&feed.ID,
+		&feed.WorkspaceID,
+		&feed.UserID,
+		&feed.Name,
+		&feed.Tag,
+		&feed.TokenHash,
+		&feed.CreatedAt,
+		&feed.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+// Create stores the feed in the context's workspace, or the owner's personal
+// workspace when there is none.
+func (r *FeedRepository) Create(ctx context.Context, feed *models.CalendarFeed) error {
+	query := `
+		INSERT INTO calendar_feeds (id, workspace_id, user_id, name, tag, token_hash, created_at, updated_at)
+		VALUES ($1, COALESCE($8::uuid, (SELECT id FROM workspaces WHERE personal_user_id = $2)), $2, $3, $4, $5, $6, $7)
+		RETURNING workspace_id
+	`
+
+	feed.ID = uuid.New()
+	now := time.Now()
+	feed.CreatedAt = now
+	feed.UpdatedAt = now
+
+	return r.db.QueryRowContext(ctx, query,
+		feed.ID,
+		feed.UserID,
+		feed.Name,
+		feed.Tag,
+		feed.TokenHash,
+		feed.CreatedAt,
+		feed.UpdatedAt,
+		workspaceArg(ctx),
+	).Scan(&feed.WorkspaceID)
+}
+
+func (r *FeedRepository) GetAll(ctx context.Context, userID uuid.UUID) ([]*models.CalendarFeed, error) {
+	query := `SELECT ` + feedColumns + ` FROM calendar_feeds
+		WHERE user_id = $1 AND ($2::uuid IS NULL OR workspace_id = $2)
+		ORDER BY created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, workspaceArg(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := []*models.CalendarFeed{}
+	for rows.Next() {
+		feed, err := scanFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, feed)
+	}
+
+	return feeds, rows.Err()
+}
+
+func (r *FeedRepository) GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.CalendarFeed, error) {
+	query := `SELECT ` + feedColumns + ` FROM calendar_feeds
+		WHERE id = $1 AND user_id = $2 AND ($3::uuid IS NULL OR workspace_id = $3)`
+
+	feed, err := scanFeed(r.db.QueryRowContext(ctx, query, id, userID, workspaceArg(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+func (r *FeedRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	query := `SELECT ` + feedColumns + ` FROM calendar_feeds WHERE token_hash = $1`
+
+	feed, err := scanFeed(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+func (r *FeedRepository) UpdateTokenHash(ctx context.Context, id uuid.UUID, userID uuid.UUID, tokenHash string) error {
+	query := `UPDATE calendar_feeds SET token_hash = $1, updated_at = $2
+		WHERE id = $3 AND user_id = $4 AND ($5::uuid IS NULL OR workspace_id = $5)`
+
+	result, err := r.db.ExecContext(ctx, query, tokenHash, time.Now(), id, userID, workspaceArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *FeedRepository) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	query := `DELETE FROM calendar_feeds WHERE id = $1 AND user_id = $2 AND ($3::uuid IS NULL OR workspace_id = $3)`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID, workspaceArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+// inboxLimit caps how many items a user's inbox lists.
+const inboxLimit = 200
+
+type InboxRepository struct {
+	db *database.DB
+}
+
+func NewInboxRepository(db *database.DB) *InboxRepository {
+	return &InboxRepository{db: db}
+}
+
+func (r *InboxRepository) Create(ctx context.Context, item *models.InboxItem) error {
+	query := `
+		INSERT INTO inbox_items (user_id, type, todo_id, actor_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		item.UserID,
+		item.Type,
+		item.TodoID,
+		item.ActorID,
+	).Scan(&item.ID, &item.CreatedAt)
+}
+
+// GetAll lists the user's newest items, optionally only unread ones. Like
+// the other queries here, it only sees items whose todo is in the workspace
+// of ctx.
+func (r *InboxRepository) GetAll(ctx context.Context, userID uuid.UUID, unreadOnly bool) ([]*models.InboxItem, error) {
+	query := `
+		SELECT i.id, i.user_id, i.type, i.todo_id, t.workspace_id, t.title, i.actor_id, i.read_at, i.created_at
+		FROM inbox_items i
+		JOIN todos t ON t.id = i.todo_id
+		WHERE i.user_id = $1 AND (NOT $2 OR i.read_at IS NULL) AND ($4::uuid IS NULL OR t.workspace_id = $4)
+		ORDER BY i.created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, unreadOnly, inboxLimit, workspaceArg(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*models.InboxItem{}
+	for rows.Next() {
+		item := &models.InboxItem{}
+		if err := rows.Scan(
+			&item.ID,
+			&item.UserID,
+			&item.Type,
+			&item.TodoID,
+			&item.WorkspaceID,
+			&item.TodoTitle,
+			&item.ActorID,
+			&item.ReadAt,
+			&item.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *InboxRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM inbox_items i
+		JOIN todos t ON t.id = i.todo_id
+		WHERE i.user_id = $1 AND i.read_at IS NULL AND ($2::uuid IS NULL OR t.workspace_id = $2)
+	`, userID, workspaceArg(ctx)).Scan(&count)
+	return count, err
+}
+
+// MarkRead marks one item as read; reading it again keeps the first time.
+func (r *InboxRepository) MarkRead(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	query := `
+		UPDATE inbox_items i SET read_at = COALESCE(i.read_at, NOW())
+		FROM todos t
+		WHERE i.id = $1 AND i.user_id = $2 AND t.id = i.todo_id AND ($3::uuid IS NULL OR t.workspace_id = $3)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID, workspaceArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// MarkAllRead marks every unread item as read and reports how many there
+// were.
+func (r *InboxRepository) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE inbox_items i SET read_at = NOW()
+		FROM todos t
+		WHERE i.user_id = $1 AND i.read_at IS NULL AND t.id = i.todo_id AND ($2::uuid IS NULL OR t.workspace_id = $2)
+	`, userID, workspaceArg(ctx))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
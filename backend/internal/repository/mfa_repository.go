@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+// MFARepository stores TOTP secrets, recovery codes and pending sign-in
+// challenges.
+type MFARepository struct {
+	db *database.DB
+}
+
+func NewMFARepository(db *database.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// GetTOTPSecret returns the user's TOTP secret, enrolled or enabled, or an
+// empty string when there is none.
+func (r *MFARepository) GetTOTPSecret(ctx context.Context, userID uuid.UUID) (string, error) {
+	var secret sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT totp_secret FROM users WHERE id = $1`, userID).Scan(&secret)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return secret.String, err
+}
+
+// SetPendingTOTP stores a new secret that takes effect once EnableTOTP
+// confirms it. It returns sql.ErrNoRows when TOTP is already enabled.
+func (r *MFARepository) SetPendingTOTP(ctx context.Context, userID uuid.UUID, secret string) error {
+	query := `
+		UPDATE users
+		SET totp_secret = $1, totp_last_step = NULL, updated_at = $2
+		WHERE id = $3 AND totp_enabled_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, secret, time.Now(), userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// EnableTOTP turns on the pending secret and replaces the user's recovery
+// codes. It returns sql.ErrNoRows when there is no pending secret.
+func (r *MFARepository) EnableTOTP(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET totp_enabled_at = $1, totp_last_step = $2, updated_at = $1
+		WHERE id = $3 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL
+	`, now, step, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DisableTOTP removes the user's secret and recovery codes.
+func (r *MFARepository) DisableTOTP(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = $1
+		WHERE id = $2
+	`, time.Now(), userID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep records that the code of the time step was used. It reports
+// false when that step or a later one was used before, so that a code
+// cannot be replayed.
+func (r *MFARepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	query := `
+		UPDATE users
+		SET totp_last_step = $1
+		WHERE id = $2 AND (totp_last_step IS NULL OR totp_last_step < $1)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 1, nil
+}
+
+// ReplaceRecoveryCodes swaps the user's recovery codes for new ones.
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID uuid.UUID, codeHashes []string, now time.Time) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO mfa_recovery_codes (id, user_id, code_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, hash := range codeHashes {
+		if _, err := stmt.ExecContext(ctx, uuid.New(), userID, hash, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode marks the user's unused recovery code with the hash used
+// and reports whether there was one.
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	query := `
+		UPDATE mfa_recovery_codes
+		SET used_at = $1
+		WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now().UTC(), userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// CreateChallenge stores a pending sign-in. It replaces the user's other
+// challenges, so that only the latest sign-in can be completed.
+func (r *MFARepository) CreateChallenge(ctx context.Context, c *models.MFAChallenge) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_challenges WHERE user_id = $1`, c.UserID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO mfa_challenges (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	c.ID = uuid.New()
+	c.CreatedAt = time.Now().UTC()
+
+	if _, err := tx.ExecContext(ctx, query, c.ID, c.UserID, c.TokenHash, c.ExpiresAt.UTC(), c.CreatedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReserveChallengeAttempt counts an attempt against the unexpired challenge
+// with the hash before its code is checked and returns it, or nil when there
+// is none or it already had maxAttempts. Reserving in one statement keeps
+// parallel requests from getting past the limit.
+func (r *MFARepository) ReserveChallengeAttempt(ctx context.Context, tokenHash string, maxAttempts int) (*models.MFAChallenge, error) {
+	query := `
+		UPDATE mfa_challenges
+		SET attempts = attempts + 1
+		WHERE token_hash = $1 AND expires_at > $2 AND attempts < $3
+		RETURNING id, user_id, token_hash, attempts, expires_at, created_at
+	`
+
+	c := &models.MFAChallenge{}
+	err := r.db.QueryRowContext(ctx, query, tokenHash, time.Now().UTC(), maxAttempts).Scan(
+		&c.ID,
+		&c.UserID,
+		&c.TokenHash,
+		&c.Attempts,
+		&c.ExpiresAt,
+		&c.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// ReserveUserAttempt counts a two-factor attempt against the user before
+// its code is checked. The attempt that reaches maxFailures, and every one
+// after it until ClearUserAttempts, locks the user out until lockedUntil.
+// It reports false while the user is locked out.
+func (r *MFARepository) ReserveUserAttempt(ctx context.Context, userID uuid.UUID, maxFailures int, lockedUntil time.Time) (bool, error) {
+	query := `
+		UPDATE users
+		SET mfa_failures = mfa_failures + 1,
+			mfa_locked_until = CASE WHEN mfa_failures + 1 >= $1 THEN $2 ELSE mfa_locked_until END
+		WHERE id = $3 AND (mfa_locked_until IS NULL OR mfa_locked_until <= $4)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, maxFailures, lockedUntil.UTC(), userID, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// ClearUserAttempts forgets the user's wrong two-factor codes after a
+// correct one.
+func (r *MFARepository) ClearUserAttempts(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET mfa_failures = 0, mfa_locked_until = NULL WHERE id = $1`, userID)
+	return err
+}
+
+// DeleteChallenge removes the challenge and reports whether it still
+// existed, so that only one request can complete it.
+func (r *MFARepository) DeleteChallenge(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM mfa_challenges WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+// OIDCRepository stores pending single sign-ons and the provider accounts
+// linked to users.
+type OIDCRepository struct {
+	db *database.DB
+}
+
+func NewOIDCRepository(db *database.DB) *OIDCRepository {
+	return &OIDCRepository{db: db}
+}
+
+// CreateState stores a pending sign-on, dropping expired ones on the way.
+func (r *OIDCRepository) CreateState(ctx context.Context, s *models.OIDCState) error {
+	now := time.Now().UTC()
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM oidc_states WHERE expires_at < $1`, now); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO oidc_states (state_hash, nonce, code_verifier, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	s.CreatedAt = now
+
+	_, err := r.db.ExecContext(ctx, query, s.StateHash, s.Nonce, s.CodeVerifier, s.ExpiresAt.UTC(), s.CreatedAt)
+	return err
+}
+
+// ConsumeState deletes the unexpired pending sign-on with the hash and
+// returns it, or nil when there is none.
+func (r *OIDCRepository) ConsumeState(ctx context.Context, stateHash string) (*models.OIDCState, error) {
+	query := `
+		DELETE FROM oidc_states
+		WHERE state_hash = $1 AND expires_at > $2
+		RETURNING state_hash, nonce, code_verifier, expires_at, created_at
+	`
+
+	s := &models.OIDCState{}
+	err := r.db.QueryRowContext(ctx, query, stateHash, time.Now().UTC()).Scan(
+		&s.StateHash,
+		&s.Nonce,
+		&s.CodeVerifier,
+		&s.ExpiresAt,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// GetIdentity returns the identity with the issuer and subject, or nil.
+func (r *OIDCRepository) GetIdentity(ctx context.Context, issuer, subject string) (*models.UserIdentity, error) {
+	query := `
+		SELECT id, user_id, issuer, subject, COALESCE(email, ''), created_at
+		FROM user_identities
+		WHERE issuer = $1 AND subject = $2
+	`
+
+	i := &models.UserIdentity{}
+	err := r.db.QueryRowContext(ctx, query, issuer, subject).Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Issuer,
+		&i.Subject,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// CreateIdentity links the provider account to the user.
+func (r *OIDCRepository) CreateIdentity(ctx context.Context, i *models.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (id, user_id, issuer, subject, email, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+	`
+
+	i.ID = uuid.New()
+	i.CreatedAt = time.Now().UTC()
+
+	_, err := r.db.ExecContext(ctx, query, i.ID, i.UserID, i.Issuer, i.Subject, i.Email, i.CreatedAt)
+	return err
+}
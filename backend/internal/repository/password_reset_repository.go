@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+type PasswordResetRepository struct {
+	db *database.DB
+}
+
+func NewPasswordResetRepository(db *database.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+// Create stores a new reset token for the user unless dailyLimit tokens
+// were sent in the last 24 hours or one within interval, and reports whether
+// it did. The user's row stays locked until then, so that parallel requests
+// are counted one after another. Earlier tokens are marked used rather than
+// deleted, so that they still count.
+func (r *PasswordResetRepository) Create(ctx context.Context, t *models.PasswordResetToken, dailyLimit int, interval time.Duration) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, t.UserID); err != nil {
+		return false, err
+	}
+
+	now := time.Now().UTC()
+	since := now.Add(-24 * time.Hour)
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM password_reset_tokens WHERE user_id = $1 AND created_at < $2
+	`, t.UserID, since); err != nil {
+		return false, err
+	}
+
+	var count int
+	var last *time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*), MAX(created_at)
+		FROM password_reset_tokens
+		WHERE user_id = $1
+	`, t.UserID).Scan(&count, &last)
+	if err != nil {
+		return false, err
+	}
+	if count >= dailyLimit || (last != nil && now.Sub(*last) < interval) {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE password_reset_tokens SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL
+	`, now, t.UserID); err != nil {
+		return false, err
+	}
+
+	query := `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	t.ID = uuid.New()
+	t.CreatedAt = now
+
+	if _, err := tx.ExecContext(ctx, query, t.ID, t.UserID, t.TokenHash, t.ExpiresAt.UTC(), t.CreatedAt); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Consume marks the unused, unexpired token with the hash used and returns
+// it, or nil when there is none.
+func (r *PasswordResetRepository) Consume(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = $1
+		WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $1
+		RETURNING id, user_id, token_hash, expires_at, used_at, created_at
+	`
+
+	t := &models.PasswordResetToken{}
+	err := r.db.QueryRowContext(ctx, query, time.Now().UTC(), tokenHash).Scan(
+		&t.ID,
+		&t.UserID,
+		&t.TokenHash,
+		&t.ExpiresAt,
+		&t.UsedAt,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// InvalidateForUser marks the user's unused reset tokens used. They are
+// kept so that Create still counts them.
+func (r *PasswordResetRepository) InvalidateForUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE password_reset_tokens SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL
+	`, time.Now().UTC(), userID)
+	return err
+}
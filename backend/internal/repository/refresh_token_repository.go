@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+// ErrRefreshTokenReused is returned when a refresh token that was already
+// rotated is presented again. Its family has been revoked by then.
+var ErrRefreshTokenReused = errors.New("refresh token was already used")
+
+type RefreshTokenRepository struct {
+	db *database.DB
+}
+
+func NewRefreshTokenRepository(db *database.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+const refreshTokenColumns = `id, user_id, family_id, token_hash, workspace_id, expires_at, used_at, revoked_at, created_at`
+
+func scanRefreshToken(row rowScanner) (*models.RefreshToken, error) {
+	t := &models.RefreshToken{}
+	err := row.Scan(
+		&t.ID,
+		&t.UserID,
+		&t.FamilyID,
+		&t.TokenHash,
+		&t.WorkspaceID,
+		&t.ExpiresAt,
+		&t.UsedAt,
+		&t.RevokedAt,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertRefreshToken(ctx context.Context, db execer, t *models.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, workspace_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	t.ID = uuid.New()
+	t.CreatedAt = time.Now().UTC()
+
+	_, err := db.ExecContext(ctx, query,
+		t.ID,
+		t.UserID,
+		t.FamilyID,
+		t.TokenHash,
+		t.WorkspaceID,
+		t.ExpiresAt.UTC(),
+		t.CreatedAt,
+	)
+	return err
+}
+
+// Create stores the first token of a new family and drops the user's
+// expired tokens on the way.
+func (r *RefreshTokenRepository) Create(ctx context.Context, t *models.RefreshToken) error {
+	now := time.Now().UTC()
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE user_id = $1 AND expires_at < $2`, t.UserID, now); err != nil {
+		return err
+	}
+
+	t.FamilyID = uuid.New()
+	return insertRefreshToken(ctx, r.db, t)
+}
+
+// Rotate marks the token with the hash used and stores next as its successor
+// in the same family, for the same user and workspace. It returns the used
+// token, or nil when the token is unknown, expired or revoked. A token that
+// was used before revokes its whole family and returns ErrRefreshTokenReused
+// with the token. check is called with the token's user before anything is
+// stored; an error from it leaves the family unchanged.
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, tokenHash string, next *models.RefreshToken, check func(userID uuid.UUID) error) (*models.RefreshToken, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	query := `
+		SELECT ` + refreshTokenColumns + `
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`
+
+	current, err := scanRefreshToken(tx.QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if current.RevokedAt != nil || !current.ExpiresAt.After(now) {
+		return nil, nil
+	}
+
+	if current.UsedAt != nil {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL
+		`, now, current.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return current, ErrRefreshTokenReused
+	}
+
+	if err := check(current.UserID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET used_at = $1 WHERE id = $2`, now, current.ID); err != nil {
+		return nil, err
+	}
+
+	next.UserID = current.UserID
+	next.FamilyID = current.FamilyID
+	next.WorkspaceID = current.WorkspaceID
+	if err := insertRefreshToken(ctx, tx, next); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// RevokeForUser revokes all of the user's refresh tokens.
+func (r *RefreshTokenRepository) RevokeForUser(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, time.Now().UTC(), userID)
+	return err
+}
+
+// RevokeFamily revokes the refresh token with the hash, if it belongs to the
+// user, along with the rest of its family.
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, userID uuid.UUID, tokenHash string) error {
+	query := `
+		UPDATE refresh_tokens SET revoked_at = $1
+		WHERE family_id = (SELECT family_id FROM refresh_tokens WHERE token_hash = $2 AND user_id = $3)
+			AND revoked_at IS NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, time.Now().UTC(), tokenHash, userID)
+	return err
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+)
+
+// RevokedTokenRepository stores the IDs of access tokens signed out before
+// they expire. Entries are dropped once the token would have expired anyway.
+type RevokedTokenRepository struct {
+	db *database.DB
+}
+
+func NewRevokedTokenRepository(db *database.DB) *RevokedTokenRepository {
+	return &RevokedTokenRepository{db: db}
+}
+
+func (r *RevokedTokenRepository) Revoke(ctx context.Context, jti uuid.UUID, userID uuid.UUID, expiresAt time.Time) error {
+	now := time.Now().UTC()
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < $1`, now); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (jti) DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, jti, userID, expiresAt.UTC(), now)
+	return err
+}
+
+func (r *RevokedTokenRepository) IsRevoked(ctx context.Context, jti uuid.UUID) (bool, error) {
+	var revoked bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`, jti).Scan(&revoked)
+	return revoked, err
+}
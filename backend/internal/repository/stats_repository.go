@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+// ErrInvalidTimezone is returned when Postgres does not know a timezone.
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// StatsRepository computes productivity statistics over the todos table.
+// Timestamps are stored without a zone in UTC, so local dates are derived
+// with (ts AT TIME ZONE 'UTC') AT TIME ZONE <tz>.
+type StatsRepository struct {
+	db *database.DB
+}
+
+func NewStatsRepository(db *database.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+func (r *StatsRepository) Get(ctx context.Context, userID uuid.UUID, opts models.StatsOptions) (*models.TodoStats, error) {
+	stats := &models.TodoStats{
+		Timezone:   opts.Timezone,
+		Window:     models.StatsWindow{Days: opts.Days},
+		ByStatus:   map[string]int{},
+		ByPriority: map[string]int{},
+		ByTag:      []models.TagCount{},
+	}
+
+	steps := []func(context.Context, uuid.UUID, models.StatsOptions, *models.TodoStats) error{
+		r.summary,
+		r.breakdown,
+		r.dailyCompletions,
+		r.streaks,
+	}
+	for _, step := range steps {
+		if err := step(ctx, userID, opts, stats); err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "22023" {
+				return nil, ErrInvalidTimezone
+			}
+			return nil, err
+		}
+	}
+
+	if stats.Completion.Created > 0 {
+		stats.Completion.Rate = float64(stats.Completion.Completed) / float64(stats.Completion.Created)
+	}
+	return stats, nil
+}
+
+// summary computes the totals, the window bounds and the window's completion
+// rate and median completion time.
+func (r *StatsRepository) summary(ctx context.Context, userID uuid.UUID, opts models.StatsOptions, stats *models.TodoStats) error {
+	query := `
+		WITH bounds AS (
+			SELECT
+				(NOW() AT TIME ZONE $2)::date - ($3::int - 1) AS from_date,
+				(NOW() AT TIME ZONE $2)::date AS to_date
+		), window_start AS (
+			SELECT from_date, to_date,
+				(from_date::timestamp AT TIME ZONE $2) AT TIME ZONE 'UTC' AS start_utc
+			FROM bounds
+		)
+		SELECT
+			to_char(w.from_date, 'YYYY-MM-DD'),
+			to_char(w.to_date, 'YYYY-MM-DD'),
+			w.start_utc,
+			COUNT(t.id),
+			COUNT(t.id) FILTER (WHERE NOT t.completed AND t.due_date < NOW() AT TIME ZONE 'UTC'),
+			COUNT(t.id) FILTER (WHERE t.created_at >= w.start_utc),
+			COUNT(t.id) FILTER (WHERE t.created_at >= w.start_utc AND t.completed),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM t.completed_at - t.created_at))
+				FILTER (WHERE t.completed AND t.completed_at >= w.start_utc)
+		FROM window_start w
+		LEFT JOIN todos t ON t.user_id = $1 AND ($4::uuid IS NULL OR t.workspace_id = $4)
+		GROUP BY w.from_date, w.to_date, w.start_utc
+	`
+
+	var median sql.NullFloat64
+	err := r.db.QueryRowContext(ctx, query, userID, opts.Timezone, opts.Days, workspaceArg(ctx)).Scan(
+		&stats.Window.From,
+		&stats.Window.To,
+		&stats.Window.Start,
+		&stats.Total,
+		&stats.Overdue,
+		&stats.Completion.Created,
+		&stats.Completion.Completed,
+		&median,
+	)
+	if err != nil {
+		return err
+	}
+	if median.Valid {
+		stats.MedianCompletionSeconds = &median.Float64
+	}
+	return nil
+}
+
+// breakdown counts todos by status, priority and tag in one pass.
+func (r *StatsRepository) breakdown(ctx context.Context, userID uuid.UUID, _ models.StatsOptions, stats *models.TodoStats) error {
+	query := `
+		WITH scoped AS (
+			SELECT status, priority, tags FROM todos
+			WHERE user_id = $1 AND ($2::uuid IS NULL OR workspace_id = $2)
+		)
+		SELECT 'status', status, COUNT(*) FROM scoped GROUP BY status
+		UNION ALL
+		SELECT 'priority', priority, COUNT(*) FROM scoped GROUP BY priority
+		UNION ALL
+		SELECT 'tag', tag, COUNT(*) FROM scoped, unnest(tags) AS tag GROUP BY tag
+		ORDER BY 1, 3 DESC, 2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, workspaceArg(ctx))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kind, key string
+		var count int
+		if err := rows.Scan(&kind, &key, &count); err != nil {
+			return err
+		}
+		switch kind {
+		case "status":
+			stats.ByStatus[key] = count
+		case "priority":
+			stats.ByPriority[key] = count
+		case "tag":
+			stats.ByTag = append(stats.ByTag, models.TagCount{Tag: key, Count: count})
+		}
+	}
+	return rows.Err()
+}
+
+// dailyCompletions returns one entry per local day of the window, including
+// days without completions.
+func (r *StatsRepository) dailyCompletions(ctx context.Context, userID uuid.UUID, opts models.StatsOptions, stats *models.TodoStats) error {
+	query := `
+		WITH days AS (
+			SELECT day::date
+			FROM generate_series(
+				(NOW() AT TIME ZONE $2)::date - ($3::int - 1),
+				(NOW() AT TIME ZONE $2)::date,
+				INTERVAL '1 day'
+			) AS day
+		), completions AS (
+			SELECT ((completed_at AT TIME ZONE 'UTC') AT TIME ZONE $2)::date AS day, COUNT(*) AS count
+			FROM todos
+			WHERE user_id = $1 AND ($4::uuid IS NULL OR workspace_id = $4) AND completed AND completed_at IS NOT NULL
+			GROUP BY 1
+		)
+		SELECT to_char(d.day, 'YYYY-MM-DD'), COALESCE(c.count, 0)
+		FROM days d
+		LEFT JOIN completions c ON c.day = d.day
+		ORDER BY d.day
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, opts.Timezone, opts.Days, workspaceArg(ctx))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	stats.DailyCompletions = make([]models.DailyCount, 0, opts.Days)
+	for rows.Next() {
+		var day models.DailyCount
+		if err := rows.Scan(&day.Date, &day.Count); err != nil {
+			return err
+		}
+		stats.DailyCompletions = append(stats.DailyCompletions, day)
+	}
+	return rows.Err()
+}
+
+// streaks finds runs of consecutive completion days over the whole history
+// (gaps and islands: consecutive dates share date - row_number).
+func (r *StatsRepository) streaks(ctx context.Context, userID uuid.UUID, opts models.StatsOptions, stats *models.TodoStats) error {
+	query := `
+		WITH days AS (
+			SELECT DISTINCT ((completed_at AT TIME ZONE 'UTC') AT TIME ZONE $2)::date AS day
+			FROM todos
+			WHERE user_id = $1 AND ($3::uuid IS NULL OR workspace_id = $3) AND completed AND completed_at IS NOT NULL
+		), islands AS (
+			SELECT day, day - (ROW_NUMBER() OVER (ORDER BY day))::int AS island
+			FROM days
+		), runs AS (
+			SELECT MAX(day) AS last_day, COUNT(*) AS length
+			FROM islands
+			GROUP BY island
+		)
+		SELECT
+			COALESCE(MAX(length) FILTER (WHERE last_day >= (NOW() AT TIME ZONE $2)::date - 1), 0),
+			COALESCE(MAX(length), 0)
+		FROM runs
+	`
+
+	return r.db.QueryRowContext(ctx, query, userID, opts.Timezone, workspaceArg(ctx)).Scan(
+		&stats.Streaks.Current,
+		&stats.Streaks.Longest,
+	)
+}
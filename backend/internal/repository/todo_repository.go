@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -11,8 +12,12 @@ import (
 	"github.com/lib/pq"
 	"github.com/yourusername/todogo-backend/internal/database"
 	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/tenant"
 )
 
+// ErrTodoIDTaken is returned when a client-chosen todo ID already exists.
+var ErrTodoIDTaken = errors.New("todo id already in use")
+
 type TodoRepository struct {
 	db *database.DB
 }
@@ -21,14 +26,73 @@ func NewTodoRepository(db *database.DB) *TodoRepository {
 	return &TodoRepository{db: db}
 }
 
+const todoColumns = `id, workspace_id, title, description, completed, status, priority, user_id, assignee_id, created_at, updated_at, completed_at, archived_at, due_date, tags, ical_uid, sync_seq, version, field_versions`
+
+// workspaceArg returns the workspace that scopes the request in ctx, or nil
+// when ctx was marked with tenant.AllWorkspaces. Queries compare it with
+// "($n::uuid IS NULL OR workspace_id = $n)". Without either, it returns the
+// nil UUID, which matches no workspace, so that a path that forgot to set the
+// scope sees nothing rather than everything.
+func workspaceArg(ctx context.Context) interface{} {
+	if id, ok := tenant.WorkspaceID(ctx); ok {
+		return id
+	}
+	if tenant.IsAllWorkspaces(ctx) {
+		return nil
+	}
+	return uuid.Nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTodo(row rowScanner) (*models.Todo, error) {
+	todo := &models.Todo{}
+	var fieldVersions []byte
+	err := row.Scan(
+		&todo.ID,
+		&todo.WorkspaceID,
+		&todo.Title,
+		&todo.Description,
+		&todo.Completed,
+		&todo.Status,
+		&todo.Priority,
+		&todo.UserID,
+		&todo.AssigneeID,
+		&todo.CreatedAt,
+		&todo.UpdatedAt,
+		&todo.CompletedAt,
+		&todo.ArchivedAt,
+		&todo.DueDate,
+		&todo.Tags,
+		&todo.ICalUID,
+		&todo.SyncSeq,
+		&todo.Version,
+		&fieldVersions,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(fieldVersions, &todo.FieldVersions); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
 func (r *TodoRepository) Create(ctx context.Context, todo *models.Todo) error {
 	query := `
-		INSERT INTO todos (id, title, description, completed, status, priority, user_id, created_at, updated_at, completed_at, due_date, tags)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-		RETURNING id, created_at, updated_at
+		INSERT INTO todos (id, title, description, completed, status, priority, user_id, created_at, updated_at, completed_at, due_date, tags, ical_uid, workspace_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
+			COALESCE($14::uuid, (SELECT id FROM workspaces WHERE personal_user_id = $7)))
+		RETURNING id, workspace_id, created_at, updated_at, sync_seq, version
 	`
 
-	todo.ID = uuid.New()
+	// Offline clients choose the IDs of todos they create.
+	if todo.ID == uuid.Nil {
+		todo.ID = uuid.New()
+	}
 	now := time.Now()
 	todo.CreatedAt = now
 	todo.UpdatedAt = now
@@ -58,34 +122,36 @@ func (r *TodoRepository) Create(ctx context.Context, todo *models.Todo) error {
 		todo.CompletedAt,
 		todo.DueDate,
 		todo.Tags,
-	).Scan(&todo.ID, &todo.CreatedAt, &todo.UpdatedAt)
+		todo.ICalUID,
+		workspaceArg(ctx),
+	).Scan(&todo.ID, &todo.WorkspaceID, &todo.CreatedAt, &todo.UpdatedAt, &todo.SyncSeq, &todo.Version)
 
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrTodoIDTaken
+	}
 	return err
 }
 
 func (r *TodoRepository) GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
-	query := `
-		SELECT id, title, description, completed, status, priority, user_id, created_at, updated_at, completed_at, due_date, tags
-		FROM todos
-		WHERE id = $1 AND user_id = $2
-	`
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE id = $1 AND user_id = $2 AND ($3::uuid IS NULL OR workspace_id = $3)`
 
-	todo := &models.Todo{}
-	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(
-		&todo.ID,
-		&todo.Title,
-		&todo.Description,
-		&todo.Completed,
-		&todo.Status,
-		&todo.Priority,
-		&todo.UserID,
-		&todo.CreatedAt,
-		&todo.UpdatedAt,
-		&todo.CompletedAt,
-		&todo.DueDate,
-		&todo.Tags,
-	)
+	todo, err := scanTodo(r.db.QueryRowContext(ctx, query, id, userID, workspaceArg(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// GetAccessible returns a todo the user owns or is assigned to.
+func (r *TodoRepository) GetAccessible(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE id = $1 AND (user_id = $2 OR assignee_id = $2) AND ($3::uuid IS NULL OR workspace_id = $3)`
 
+	todo, err := scanTodo(r.db.QueryRowContext(ctx, query, id, userID, workspaceArg(ctx)))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -96,15 +162,68 @@ func (r *TodoRepository) GetByID(ctx context.Context, id uuid.UUID, userID uuid.
 	return todo, nil
 }
 
+// GetByIDs loads several of the user's todos in one query. Unknown IDs are
+// left out.
+func (r *TodoRepository) GetByIDs(ctx context.Context, ids []uuid.UUID, userID uuid.UUID) ([]*models.Todo, error) {
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE id = ANY($1) AND user_id = $2 AND ($3::uuid IS NULL OR workspace_id = $3)`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids), userID, workspaceArg(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	todos := []*models.Todo{}
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+
+	return todos, rows.Err()
+}
+
+// GetPendingDueBefore lists the user's active pending todos due before the given
+// time, soonest first. Due dates are stored as UTC.
+func (r *TodoRepository) GetPendingDueBefore(ctx context.Context, userID uuid.UUID, before time.Time) ([]*models.Todo, error) {
+	query := `SELECT ` + todoColumns + ` FROM todos
+		WHERE user_id = $1 AND NOT completed AND archived_at IS NULL AND due_date IS NOT NULL AND due_date < $2 AND ($3::uuid IS NULL OR workspace_id = $3)
+		ORDER BY due_date, created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, before.UTC(), workspaceArg(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	todos := []*models.Todo{}
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+
+	return todos, rows.Err()
+}
+
 func (r *TodoRepository) GetAll(ctx context.Context, userID uuid.UUID, filters models.TodoFilters) ([]*models.Todo, error) {
-	query := `
-		SELECT id, title, description, completed, status, priority, user_id, created_at, updated_at, completed_at, due_date, tags
-		FROM todos
-		WHERE user_id = $1
-	`
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE user_id = $1`
+	if filters.AssignedToMe {
+		query = `SELECT ` + todoColumns + ` FROM todos WHERE assignee_id = $1`
+	}
+	query += " AND ($2::uuid IS NULL OR workspace_id = $2)"
+	if filters.Archived {
+		query += " AND archived_at IS NOT NULL"
+	} else {
+		query += " AND archived_at IS NULL"
+	}
 
-	args := []interface{}{userID}
-	argCount := 1
+	args := []interface{}{userID, workspaceArg(ctx)}
+	argCount := 2
 
 	// Apply filters
 	if filters.Status != nil {
@@ -132,7 +251,22 @@ func (r *TodoRepository) GetAll(ctx context.Context, userID uuid.UUID, filters m
 		args = append(args, pq.Array(filters.Tags))
 	}
 
-	query += " ORDER BY created_at DESC"
+	if filters.NoDueDate {
+		query += " AND due_date IS NULL"
+	} else {
+		if filters.DueFrom != nil {
+			argCount++
+			query += fmt.Sprintf(" AND due_date >= $%d", argCount)
+			args = append(args, filters.DueFrom.UTC())
+		}
+		if filters.DueBefore != nil {
+			argCount++
+			query += fmt.Sprintf(" AND due_date < $%d", argCount)
+			args = append(args, filters.DueBefore.UTC())
+		}
+	}
+
+	query += " ORDER BY " + todoOrder(filters.Sort, filters.Direction)
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -142,21 +276,7 @@ func (r *TodoRepository) GetAll(ctx context.Context, userID uuid.UUID, filters m
 
 	todos := []*models.Todo{}
 	for rows.Next() {
-		todo := &models.Todo{}
-		err := rows.Scan(
-			&todo.ID,
-			&todo.Title,
-			&todo.Description,
-			&todo.Completed,
-			&todo.Status,
-			&todo.Priority,
-			&todo.UserID,
-			&todo.CreatedAt,
-			&todo.UpdatedAt,
-			&todo.CompletedAt,
-			&todo.DueDate,
-			&todo.Tags,
-		)
+		todo, err := scanTodo(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -166,16 +286,41 @@ func (r *TodoRepository) GetAll(ctx context.Context, userID uuid.UUID, filters m
 	return todos, rows.Err()
 }
 
+// todoSortColumns maps sort fields to SQL expressions; priority sorts by
+// rank rather than alphabetically.
+var todoSortColumns = map[models.ViewSortField]string{
+	models.SortCreatedAt: "created_at",
+	models.SortUpdatedAt: "updated_at",
+	models.SortDueDate:   "due_date",
+	models.SortPriority:  "CASE priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 ELSE 1 END",
+	models.SortTitle:     "LOWER(title)",
+}
+
+func todoOrder(field models.ViewSortField, direction models.SortDirection) string {
+	column, ok := todoSortColumns[field]
+	if !ok {
+		return "created_at DESC"
+	}
+	dir := "ASC"
+	if direction == models.SortDesc {
+		dir = "DESC"
+	}
+	// Todos without a due date come last either way
+	return column + " " + dir + " NULLS LAST, created_at DESC"
+}
+
 func (r *TodoRepository) Update(ctx context.Context, todo *models.Todo) error {
 	query := `
 		UPDATE todos
 		SET title = $1, description = $2, priority = $3, due_date = $4, tags = $5, updated_at = $6
-		WHERE id = $7 AND user_id = $8
+		WHERE id = $7 AND user_id = $8 AND ($9::uuid IS NULL OR workspace_id = $9)
+		RETURNING version, sync_seq
 	`
 
 	todo.UpdatedAt = time.Now()
 
-	result, err := r.db.ExecContext(
+	// sql.ErrNoRows when the todo does not exist
+	return r.db.QueryRowContext(
 		ctx,
 		query,
 		todo.Title,
@@ -186,22 +331,60 @@ func (r *TodoRepository) Update(ctx context.Context, todo *models.Todo) error {
 		todo.UpdatedAt,
 		todo.ID,
 		todo.UserID,
-	)
+		workspaceArg(ctx),
+	).Scan(&todo.Version, &todo.SyncSeq)
+}
 
+// Duplicate copies a todo into a new one with the given ID in a single
+// statement. The copy is never archived and has no calendar UID. It returns
+// nil when the original does not exist.
+func (r *TodoRepository) Duplicate(ctx context.Context, id uuid.UUID, userID uuid.UUID, newID uuid.UUID, req models.DuplicateTodoRequest) (*models.Todo, error) {
+	query := `
+		INSERT INTO todos (id, workspace_id, title, description, completed, status, priority, user_id, created_at, updated_at, completed_at, due_date, tags)
+		SELECT $3, workspace_id, COALESCE($4::text, title), description,
+			CASE WHEN $5::boolean THEN false ELSE completed END,
+			CASE WHEN $5::boolean THEN 'pending' ELSE status END,
+			priority, user_id, $6, $6,
+			CASE WHEN $5::boolean THEN NULL ELSE completed_at END,
+			due_date + make_interval(days => $7::int),
+			CASE WHEN $8::boolean THEN tags ELSE '{}' END
+		FROM todos
+		WHERE id = $1 AND user_id = $2 AND ($9::uuid IS NULL OR workspace_id = $9)
+		RETURNING ` + todoColumns
+
+	todo, err := scanTodo(r.db.QueryRowContext(ctx, query,
+		id,
+		userID,
+		newID,
+		req.Title,
+		req.ResetCompletion == nil || *req.ResetCompletion,
+		time.Now(),
+		req.DueOffsetDays,
+		req.KeepTags == nil || *req.KeepTags,
+		workspaceArg(ctx),
+	))
 	if err != nil {
-		return err
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
 	}
+	return todo, nil
+}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
+// Snapshot returns the todo's stored row as JSON for undo, or nil when the
+// todo does not exist.
+func (r *TodoRepository) Snapshot(ctx context.Context, id uuid.UUID, userID uuid.UUID) (json.RawMessage, error) {
+	query := `SELECT to_jsonb(t) FROM todos t WHERE id = $1 AND user_id = $2 AND ($3::uuid IS NULL OR workspace_id = $3)`
 
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+	var row []byte
+	if err := r.db.QueryRowContext(ctx, query, id, userID, workspaceArg(ctx)).Scan(&row); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
 	}
-
-	return nil
+	return row, nil
 }
 
 func (r *TodoRepository) UpdateStatus(ctx context.Context, id uuid.UUID, userID uuid.UUID, completed bool) error {
@@ -213,16 +396,16 @@ func (r *TodoRepository) UpdateStatus(ctx context.Context, id uuid.UUID, userID
 		query = `
 			UPDATE todos
 			SET completed = true, status = $1, completed_at = $2, updated_at = $3
-			WHERE id = $4 AND user_id = $5
+			WHERE id = $4 AND user_id = $5 AND ($6::uuid IS NULL OR workspace_id = $6)
 		`
-		args = []interface{}{models.StatusCompleted, now, now, id, userID}
+		args = []interface{}{models.StatusCompleted, now, now, id, userID, workspaceArg(ctx)}
 	} else {
 		query = `
 			UPDATE todos
 			SET completed = false, status = $1, completed_at = NULL, updated_at = $2
-			WHERE id = $3 AND user_id = $4
+			WHERE id = $3 AND user_id = $4 AND ($5::uuid IS NULL OR workspace_id = $5)
 		`
-		args = []interface{}{models.StatusPending, time.Now(), id, userID}
+		args = []interface{}{models.StatusPending, time.Now(), id, userID, workspaceArg(ctx)}
 	}
 
 	result, err := r.db.ExecContext(ctx, query, args...)
@@ -242,10 +425,205 @@ func (r *TodoRepository) UpdateStatus(ctx context.Context, id uuid.UUID, userID
 	return nil
 }
 
+// SetArchived archives the todo at the given time, or restores it when
+// archivedAt is nil.
+func (r *TodoRepository) SetArchived(ctx context.Context, id uuid.UUID, userID uuid.UUID, archivedAt *time.Time) error {
+	query := `UPDATE todos SET archived_at = $1, updated_at = $2 WHERE id = $3 AND user_id = $4 AND ($5::uuid IS NULL OR workspace_id = $5)`
+
+	result, err := r.db.ExecContext(ctx, query, archivedAt, time.Now(), id, userID, workspaceArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// SetAssignee assigns the todo, or unassigns it when assigneeID is nil.
+func (r *TodoRepository) SetAssignee(ctx context.Context, id uuid.UUID, userID uuid.UUID, assigneeID *uuid.UUID) error {
+	query := `UPDATE todos SET assignee_id = $1, updated_at = $2 WHERE id = $3 AND user_id = $4 AND ($5::uuid IS NULL OR workspace_id = $5)`
+
+	result, err := r.db.ExecContext(ctx, query, assigneeID, time.Now(), id, userID, workspaceArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ArchiveByRules archives up to limit completed todos whose owner's archive
+// rule has expired them, and returns them. Concurrent workers never archive
+// the same todo twice.
+func (r *TodoRepository) ArchiveByRules(ctx context.Context, now time.Time, limit int) ([]*models.Todo, error) {
+	query := `
+		UPDATE todos
+		SET archived_at = $1
+		WHERE id IN (
+			SELECT t.id FROM todos t
+			JOIN archive_rules ar ON ar.user_id = t.user_id
+			WHERE ar.enabled AND t.completed AND t.archived_at IS NULL
+				AND t.completed_at < $1::timestamp - make_interval(days => ar.completed_after_days)
+			LIMIT $2
+			FOR UPDATE OF t SKIP LOCKED
+		)
+		RETURNING ` + todoColumns
+
+	rows, err := r.db.QueryContext(ctx, query, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	todos := []*models.Todo{}
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+
+	return todos, rows.Err()
+}
+
+// GetByICalUID looks up a todo previously imported from an iCalendar file.
+func (r *TodoRepository) GetByICalUID(ctx context.Context, uid string, userID uuid.UUID) (*models.Todo, error) {
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE ical_uid = $1 AND user_id = $2 AND ($3::uuid IS NULL OR workspace_id = $3)`
+
+	todo, err := scanTodo(r.db.QueryRowContext(ctx, query, uid, userID, workspaceArg(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// SetCompletedAt sets the completion state from an external source, keeping
+// the given completion time instead of stamping the current one.
+func (r *TodoRepository) SetCompletedAt(ctx context.Context, id uuid.UUID, userID uuid.UUID, completedAt *time.Time) error {
+	status := models.StatusPending
+	if completedAt != nil {
+		status = models.StatusCompleted
+	}
+
+	query := `
+		UPDATE todos
+		SET completed = $1, status = $2, completed_at = $3, updated_at = $4
+		WHERE id = $5 AND user_id = $6 AND ($7::uuid IS NULL OR workspace_id = $7)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, completedAt != nil, status, completedAt, time.Now(), id, userID, workspaceArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateIfVersion writes every editable field and the completion state of a
+// todo, provided it is still at the given version. It returns sql.ErrNoRows
+// when the todo is gone or was written concurrently.
+func (r *TodoRepository) UpdateIfVersion(ctx context.Context, todo *models.Todo, version int) error {
+	query := `
+		UPDATE todos
+		SET title = $1, description = $2, priority = $3, due_date = $4, tags = $5,
+			completed = $6, status = $7, completed_at = $8, updated_at = $9
+		WHERE id = $10 AND user_id = $11 AND version = $12 AND ($13::uuid IS NULL OR workspace_id = $13)
+	`
+
+	todo.Status = models.StatusPending
+	if todo.Completed {
+		todo.Status = models.StatusCompleted
+	} else {
+		todo.CompletedAt = nil
+	}
+	todo.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, query,
+		todo.Title,
+		todo.Description,
+		todo.Priority,
+		todo.DueDate,
+		todo.Tags,
+		todo.Completed,
+		todo.Status,
+		todo.CompletedAt,
+		todo.UpdatedAt,
+		todo.ID,
+		todo.UserID,
+		version,
+		workspaceArg(ctx),
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteIfVersion deletes a todo provided it is still at the given version.
+func (r *TodoRepository) DeleteIfVersion(ctx context.Context, id uuid.UUID, userID uuid.UUID, version int) error {
+	query := `DELETE FROM todos WHERE id = $1 AND user_id = $2 AND version = $3 AND ($4::uuid IS NULL OR workspace_id = $4)`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID, version, workspaceArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
 func (r *TodoRepository) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
-	query := `DELETE FROM todos WHERE id = $1 AND user_id = $2`
+	query := `DELETE FROM todos WHERE id = $1 AND user_id = $2 AND ($3::uuid IS NULL OR workspace_id = $3)`
 	
-	result, err := r.db.ExecContext(ctx, query, id, userID)
+	result, err := r.db.ExecContext(ctx, query, id, userID, workspaceArg(ctx))
 	if err != nil {
 		return err
 	}
@@ -261,3 +639,98 @@ func (r *TodoRepository) Delete(ctx context.Context, id uuid.UUID, userID uuid.U
 
 	return nil
 }
+
+// GetChangedSince returns the todos written at or after the sync position,
+// as returned by SyncPosition.
+func (r *TodoRepository) GetChangedSince(ctx context.Context, userID uuid.UUID, since int64) ([]*models.Todo, error) {
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE user_id = $1 AND sync_xid >= $2::text::xid8 AND ($3::uuid IS NULL OR workspace_id = $3) ORDER BY sync_seq`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, since, workspaceArg(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	todos := []*models.Todo{}
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+
+	return todos, rows.Err()
+}
+
+// GetDeletedSince returns tombstones of todos deleted at or after the sync
+// position.
+func (r *TodoRepository) GetDeletedSince(ctx context.Context, userID uuid.UUID, since int64) ([]*models.TodoTombstone, error) {
+	query := `
+		SELECT todo_id, user_id, ical_uid, deleted_at, sync_seq
+		FROM todo_tombstones
+		WHERE user_id = $1 AND sync_xid >= $2::text::xid8 AND ($3::uuid IS NULL OR workspace_id = $3)
+		ORDER BY sync_seq
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, since, workspaceArg(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tombstones := []*models.TodoTombstone{}
+	for rows.Next() {
+		t := &models.TodoTombstone{}
+		if err := rows.Scan(&t.TodoID, &t.UserID, &t.ICalUID, &t.DeletedAt, &t.SyncSeq); err != nil {
+			return nil, err
+		}
+		tombstones = append(tombstones, t)
+	}
+
+	return tombstones, rows.Err()
+}
+
+// GetTombstone returns the tombstone of a deleted todo, or nil if the todo
+// was never deleted.
+func (r *TodoRepository) GetTombstone(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.TodoTombstone, error) {
+	query := `
+		SELECT todo_id, user_id, ical_uid, deleted_at, sync_seq
+		FROM todo_tombstones
+		WHERE todo_id = $1 AND user_id = $2 AND ($3::uuid IS NULL OR workspace_id = $3)
+	`
+
+	t := &models.TodoTombstone{}
+	err := r.db.QueryRowContext(ctx, query, id, userID, workspaceArg(ctx)).Scan(&t.TodoID, &t.UserID, &t.ICalUID, &t.DeletedAt, &t.SyncSeq)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// SyncPosition returns the position a client that read the user's todos now
+// resumes from: a transaction ID such that every write by an older
+// transaction is visible already. Writes still in flight have a newer ID and
+// are returned by the next GetChangedSince, even when they commit after
+// writes with higher sync sequence numbers. The position stays put while
+// nothing older than the user's last write is running, so it doubles as a
+// change tag.
+func (r *TodoRepository) SyncPosition(ctx context.Context, userID uuid.UUID) (int64, error) {
+	query := `
+		SELECT LEAST(
+			pg_snapshot_xmin(pg_current_snapshot())::text::bigint,
+			GREATEST(
+				COALESCE((SELECT MAX(sync_xid::text::bigint) FROM todos WHERE user_id = $1 AND ($2::uuid IS NULL OR workspace_id = $2)), 0),
+				COALESCE((SELECT MAX(sync_xid::text::bigint) FROM todo_tombstones WHERE user_id = $1 AND ($2::uuid IS NULL OR workspace_id = $2)), 0)
+			) + 1
+		)
+	`
+
+	var pos int64
+	err := r.db.QueryRowContext(ctx, query, userID, workspaceArg(ctx)).Scan(&pos)
+	return pos, err
+}
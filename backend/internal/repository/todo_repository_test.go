@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/tenant"
+)
+
+// testDB connects to the database in TEST_DATABASE_URL, skipping the test
+// when it is not set.
+func testDB(t *testing.T) *database.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := database.NewDB(dsn)
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func containsTodo(todos []*models.Todo, id uuid.UUID) bool {
+	for _, todo := range todos {
+		if todo.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// TestSyncPositionOverlappingWrites commits a write after one that drew a
+// higher sync sequence number, and checks that a sync in between still gets
+// the first write next time.
+func TestSyncPositionOverlappingWrites(t *testing.T) {
+	db := testDB(t)
+	ctx := tenant.AllWorkspaces(context.Background())
+	users := NewUserRepository(db)
+	todos := NewTodoRepository(db)
+
+	user := &models.User{Name: "Sync Test", Email: uuid.NewString() + "@example.com", Password: "x"}
+	if err := users.Create(ctx, user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM users WHERE id = $1`, user.ID)
+		db.Exec(`DELETE FROM todo_tombstones WHERE user_id = $1`, user.ID)
+	})
+
+	first := &models.Todo{Title: "first", UserID: user.ID}
+	second := &models.Todo{Title: "second", UserID: user.ID}
+	for _, todo := range []*models.Todo{first, second} {
+		if err := todos.Create(ctx, todo); err != nil {
+			t.Fatalf("create todo: %v", err)
+		}
+	}
+
+	start, err := todos.SyncPosition(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("SyncPosition: %v", err)
+	}
+
+	txA, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer txA.Rollback()
+	var seqA int64
+	if err := txA.QueryRowContext(ctx, `UPDATE todos SET title = 'first, changed' WHERE id = $1 RETURNING sync_seq`, first.ID).Scan(&seqA); err != nil {
+		t.Fatalf("update first: %v", err)
+	}
+
+	var seqB int64
+	if err := db.QueryRowContext(ctx, `UPDATE todos SET title = 'second, changed' WHERE id = $1 RETURNING sync_seq`, second.ID).Scan(&seqB); err != nil {
+		t.Fatalf("update second: %v", err)
+	}
+	if seqA >= seqB {
+		t.Fatalf("sync_seq of the open write = %d, want below %d", seqA, seqB)
+	}
+
+	// A sync while the first write is still open
+	pos, err := todos.SyncPosition(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("SyncPosition: %v", err)
+	}
+	changed, err := todos.GetChangedSince(ctx, user.ID, start)
+	if err != nil {
+		t.Fatalf("GetChangedSince: %v", err)
+	}
+	if !containsTodo(changed, second.ID) {
+		t.Errorf("committed write missing from changes")
+	}
+
+	if err := txA.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err = todos.GetChangedSince(ctx, user.ID, pos)
+	if err != nil {
+		t.Fatalf("GetChangedSince: %v", err)
+	}
+	if !containsTodo(changed, first.ID) {
+		t.Errorf("write committed after the sync is missing from the next one")
+	}
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+// ErrUndoConflict is returned when a todo changed again after the action
+// being undone, or a deleted todo's ID was reused.
+var ErrUndoConflict = errors.New("todo changed since, cannot undo")
+
+type UndoRepository struct {
+	db *database.DB
+}
+
+func NewUndoRepository(db *database.DB) *UndoRepository {
+	return &UndoRepository{db: db}
+}
+
+// Create stores an action under the hash of its token and drops expired
+// ones on the way.
+func (r *UndoRepository) Create(ctx context.Context, tokenHash string, action *models.UndoAction) error {
+	entries, err := json.Marshal(action.Entries)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	action.CreatedAt = now
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM undo_actions WHERE expires_at <= $1`, now); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO undo_actions (token_hash, user_id, action, entries, expires_at, created_at, workspace_id)
+		VALUES ($1, $2, $3, $4, $5, $6,
+			COALESCE($7::uuid, (SELECT id FROM workspaces WHERE personal_user_id = $2)))
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		tokenHash,
+		action.UserID,
+		action.Action,
+		entries,
+		action.ExpiresAt.UTC(),
+		action.CreatedAt,
+		workspaceArg(ctx),
+	)
+	return err
+}
+
+// Undo consumes an unexpired token of the workspace in ctx and reverts its
+// entries, newest first, in one transaction. It returns nil when the token is
+// unknown or expired and
+// ErrUndoConflict, leaving everything unchanged, when any todo was written
+// after the action. check is called for every entry before anything is
+// reverted; an error from it leaves the token and the todos unchanged.
+func (r *UndoRepository) Undo(ctx context.Context, tokenHash string, userID uuid.UUID, check func(models.UndoEntry) error) (*models.UndoResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var entriesJSON []byte
+	result := &models.UndoResult{
+		Restored: []*models.Todo{},
+		Reverted: []*models.Todo{},
+		Removed:  []*models.Todo{},
+	}
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM undo_actions
+		WHERE token_hash = $1 AND user_id = $2 AND expires_at > $3 AND ($4::uuid IS NULL OR workspace_id = $4)
+		RETURNING action, entries
+	`, tokenHash, userID, time.Now().UTC(), workspaceArg(ctx)).Scan(&result.Action, &entriesJSON)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entries []models.UndoEntry
+	if err := json.Unmarshal(entriesJSON, &entries); err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if err := check(entry); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		switch {
+		case len(entry.Before) == 0:
+			todo, err := undoCreate(ctx, tx, entry, userID)
+			if err != nil {
+				return nil, err
+			}
+			result.Removed = append(result.Removed, todo)
+		case entry.Version == 0:
+			todo, err := undoDelete(ctx, tx, entry, userID)
+			if err != nil {
+				return nil, err
+			}
+			result.Restored = append(result.Restored, todo)
+		default:
+			todo, err := undoUpdate(ctx, tx, entry, userID)
+			if err != nil {
+				return nil, err
+			}
+			result.Reverted = append(result.Reverted, todo)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func undoCreate(ctx context.Context, tx *sql.Tx, entry models.UndoEntry, userID uuid.UUID) (*models.Todo, error) {
+	query := `DELETE FROM todos WHERE id = $1 AND user_id = $2 AND version = $3 AND ($4::uuid IS NULL OR workspace_id = $4)
+		RETURNING ` + todoColumns
+
+	todo, err := scanTodo(tx.QueryRowContext(ctx, query, entry.TodoID, userID, entry.Version, workspaceArg(ctx)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUndoConflict
+	}
+	return todo, err
+}
+
+// undoDelete reinserts the deleted row with every column as it was, except
+// for a fresh sync position so that sync clients pick it up again. The
+// insert trigger clears the tombstone.
+func undoDelete(ctx context.Context, tx *sql.Tx, entry models.UndoEntry, userID uuid.UUID) (*models.Todo, error) {
+	query := `
+		INSERT INTO todos
+		SELECT * FROM jsonb_populate_record(NULL::todos, $1::jsonb || jsonb_build_object('sync_seq', nextval('todo_sync_seq')))
+		WHERE ($1::jsonb ->> 'user_id')::uuid = $2
+			AND ($3::uuid IS NULL OR ($1::jsonb ->> 'workspace_id')::uuid = $3)
+		RETURNING ` + todoColumns
+
+	todo, err := scanTodo(tx.QueryRowContext(ctx, query, []byte(entry.Before), userID, workspaceArg(ctx)))
+	var pqErr *pq.Error
+	// A taken ID or a since deleted assignee
+	if errors.As(err, &pqErr) && (pqErr.Code == "23505" || pqErr.Code == "23503") {
+		return nil, ErrUndoConflict
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUndoConflict
+	}
+	return todo, err
+}
+
+// undoUpdate writes back every column an action can change, provided the
+// todo is still at the version the action left it at. Assignees can undo
+// their own changes, which only ever touch completion.
+func undoUpdate(ctx context.Context, tx *sql.Tx, entry models.UndoEntry, userID uuid.UUID) (*models.Todo, error) {
+	query := `
+		UPDATE todos
+		SET (title, description, completed, status, priority, assignee_id, due_date, tags, completed_at, archived_at, updated_at) = (
+			SELECT b.title, b.description, b.completed, b.status, b.priority, b.assignee_id, b.due_date, b.tags, b.completed_at, b.archived_at, b.updated_at
+			FROM jsonb_populate_record(NULL::todos, $1) b
+		)
+		WHERE id = $2 AND (user_id = $3 OR assignee_id = $3) AND version = $4 AND ($5::uuid IS NULL OR workspace_id = $5)
+		RETURNING ` + todoColumns
+
+	todo, err := scanTodo(tx.QueryRowContext(ctx, query, []byte(entry.Before), entry.TodoID, userID, entry.Version, workspaceArg(ctx)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUndoConflict
+	}
+	return todo, err
+}
@@ -4,13 +4,43 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/yourusername/todogo-backend/internal/database"
 	"github.com/yourusername/todogo-backend/internal/models"
 )
 
+// ErrLastAdmin is returned when a change would leave no active admin.
+var ErrLastAdmin = errors.New("cannot remove the last admin")
+
+const userColumns = `u.id, u.name, u.email, u.password, u.role, u.disabled_at, u.password_reset_required, u.password_changed_at, u.email_verified_at, u.totp_enabled_at, u.token_generation, u.created_at, u.updated_at`
+
+func scanUser(row rowScanner, extra ...interface{}) (*models.User, error) {
+	user := &models.User{}
+	dest := []interface{}{
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Password,
+		&user.Role,
+		&user.DisabledAt,
+		&user.PasswordResetRequired,
+		&user.PasswordChangedAt,
+		&user.EmailVerifiedAt,
+		&user.TOTPEnabledAt,
+		&user.TokenGeneration,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 type UserRepository struct {
 	db *database.DB
 }
@@ -19,17 +49,31 @@ func NewUserRepository(db *database.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// Create stores the user together with their personal workspace.
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	query := `
-		INSERT INTO users (id, name, email, password, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at, updated_at
+		WITH u AS (
+			INSERT INTO users (id, name, email, password, role, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $7, $5, $6)
+			RETURNING id, created_at, updated_at
+		), w AS (
+			INSERT INTO workspaces (name, personal_user_id, created_by, created_at, updated_at)
+			SELECT 'Personal', id, id, created_at, updated_at FROM u
+			RETURNING id, personal_user_id
+		), m AS (
+			INSERT INTO workspace_members (workspace_id, user_id, role, joined_at)
+			SELECT id, personal_user_id, 'owner', $5 FROM w
+		)
+		SELECT id, created_at, updated_at FROM u
 	`
 
 	user.ID = uuid.New()
 	now := time.Now()
 	user.CreatedAt = now
 	user.UpdatedAt = now
+	if user.Role == "" {
+		user.Role = models.RoleMember
+	}
 
 	err := r.db.QueryRowContext(
 		ctx,
@@ -40,6 +84,7 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 		user.Password,
 		user.CreatedAt,
 		user.UpdatedAt,
+		user.Role,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
@@ -51,21 +96,12 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, name, email, password, created_at, updated_at
-		FROM users
-		WHERE email = $1
+		SELECT ` + userColumns + `
+		FROM users u
+		WHERE u.email = $1
 	`
 
-	user := &models.User{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID,
-		&user.Name,
-		&user.Email,
-		&user.Password,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, email))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -78,21 +114,12 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	query := `
-		SELECT id, name, email, password, created_at, updated_at
-		FROM users
-		WHERE id = $1
+		SELECT ` + userColumns + `
+		FROM users u
+		WHERE u.id = $1
 	`
 
-	user := &models.User{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID,
-		&user.Name,
-		&user.Email,
-		&user.Password,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -103,29 +130,275 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 	return user, nil
 }
 
+// GetByIDs loads several users in one query. Unknown IDs are left out.
+func (r *UserRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users u
+		WHERE u.id = ANY($1)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// todoCounts is appended to userColumns for the admin listings.
+const todoCounts = `
+	(SELECT COUNT(*) FROM todos t WHERE t.user_id = u.id),
+	(SELECT COUNT(*) FROM todos t WHERE t.user_id = u.id AND t.completed)
+`
+
+// List returns users matching the filters, newest first, with their todo
+// counts.
+func (r *UserRepository) List(ctx context.Context, filters models.UserFilters) ([]*models.AdminUser, error) {
+	query := `SELECT ` + userColumns + `, ` + todoCounts + ` FROM users u WHERE 1=1`
+	args := []interface{}{}
+	argCount := 1
+
+	if filters.Search != nil && *filters.Search != "" {
+		query += fmt.Sprintf(" AND (u.name ILIKE $%d OR u.email ILIKE $%d)", argCount, argCount)
+		args = append(args, "%"+*filters.Search+"%")
+		argCount++
+	}
+
+	if filters.Role != nil {
+		query += fmt.Sprintf(" AND u.role = $%d", argCount)
+		args = append(args, *filters.Role)
+		argCount++
+	}
+
+	if filters.Disabled != nil {
+		if *filters.Disabled {
+			query += " AND u.disabled_at IS NOT NULL"
+		} else {
+			query += " AND u.disabled_at IS NULL"
+		}
+	}
+
+	query += fmt.Sprintf(" ORDER BY u.created_at DESC, u.id LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, filters.Limit, filters.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*models.AdminUser{}
+	for rows.Next() {
+		u := &models.AdminUser{}
+		user, err := scanUser(rows, &u.TodoCount, &u.CompletedCount)
+		if err != nil {
+			return nil, err
+		}
+		u.User = *user
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
+
+// GetWithCounts returns the user with their todo counts, or nil when unknown.
+func (r *UserRepository) GetWithCounts(ctx context.Context, id uuid.UUID) (*models.AdminUser, error) {
+	query := `SELECT ` + userColumns + `, ` + todoCounts + ` FROM users u WHERE u.id = $1`
+
+	u := &models.AdminUser{}
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id), &u.TodoCount, &u.CompletedCount)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	u.User = *user
+
+	return u, nil
+}
+
+// Update saves the user's profile, password and account state.
 func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	query := `
 		UPDATE users
-		SET name = $1, email = $2, updated_at = $3
-		WHERE id = $4
+		SET name = $1, email = $2, password = $3, disabled_at = $4,
+			password_reset_required = $5, password_changed_at = $6,
+			email_verified_at = $7, updated_at = $8
+		WHERE id = $9
 	`
 
 	user.UpdatedAt = time.Now()
 
-	_, err := r.db.ExecContext(
+	result, err := r.db.ExecContext(
 		ctx,
 		query,
 		user.Name,
 		user.Email,
+		user.Password,
+		user.DisabledAt,
+		user.PasswordResetRequired,
+		user.PasswordChangedAt,
+		user.EmailVerifiedAt,
 		user.UpdatedAt,
 		user.ID,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
 }
 
+// IncrementTokenGeneration bumps the user's token generation and returns the
+// new one.
+func (r *UserRepository) IncrementTokenGeneration(ctx context.Context, id uuid.UUID) (int, error) {
+	query := `
+		UPDATE users
+		SET token_generation = token_generation + 1, updated_at = $1
+		WHERE id = $2
+		RETURNING token_generation
+	`
+
+	var generation int
+	err := r.db.QueryRowContext(ctx, query, time.Now(), id).Scan(&generation)
+	return generation, err
+}
+
+// Delete removes the user with everything they own. Shared workspaces they
+// own pass to their longest-standing member, or are removed when nobody
+// else is left.
 func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM users WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE workspace_members m SET role = 'owner'
+		FROM (
+			SELECT DISTINCT ON (o.workspace_id) o.workspace_id, n.user_id
+			FROM workspace_members o
+			JOIN workspace_members n ON n.workspace_id = o.workspace_id AND n.user_id <> o.user_id
+			WHERE o.user_id = $1 AND o.role = 'owner'
+				AND NOT EXISTS (
+					SELECT 1 FROM workspace_members x
+					WHERE x.workspace_id = o.workspace_id AND x.role = 'owner' AND x.user_id <> $1
+				)
+			ORDER BY o.workspace_id, n.joined_at, n.user_id
+		) heir
+		WHERE m.workspace_id = heir.workspace_id AND m.user_id = heir.user_id
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM workspaces w
+		WHERE w.personal_user_id IS NULL
+			AND EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = w.id AND m.user_id = $1)
+			AND NOT EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = w.id AND m.user_id <> $1)
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit()
+}
+
+// GetRole returns the user's role, or an empty role for unknown and
+// disabled users.
+func (r *UserRepository) GetRole(ctx context.Context, id uuid.UUID) (models.Role, error) {
+	var role models.Role
+	err := r.db.QueryRowContext(ctx, `SELECT role FROM users WHERE id = $1 AND disabled_at IS NULL`, id).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return role, err
+}
+
+// SetRole changes the user's role and returns the previous one. It returns
+// ErrLastAdmin instead of demoting the only admin.
+func (r *UserRepository) SetRole(ctx context.Context, id uuid.UUID, role models.Role) (models.Role, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	// Admin changes are serialized so that two demotions cannot both pass
+	// the check below
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return "", err
+	}
+
+	var previous models.Role
+	err = tx.QueryRowContext(ctx, `SELECT role FROM users WHERE id = $1`, id).Scan(&previous)
+	if err != nil {
+		return "", err
+	}
+
+	if previous == models.RoleAdmin && role != models.RoleAdmin {
+		var others int
+		err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM users WHERE role = $1 AND disabled_at IS NULL AND id <> $2
+		`, models.RoleAdmin, id).Scan(&others)
+		if err != nil {
+			return "", err
+		}
+		if others == 0 {
+			return "", ErrLastAdmin
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET role = $1, updated_at = $2 WHERE id = $3`, role, time.Now(), id); err != nil {
+		return "", err
+	}
+
+	return previous, tx.Commit()
+}
+
+// PromoteAdmins makes the users with the given verified emails admins.
+func (r *UserRepository) PromoteAdmins(ctx context.Context, emails []string) error {
+	query := `
+		UPDATE users SET role = $1, updated_at = $2
+		WHERE lower(email) = ANY($3) AND email_verified_at IS NOT NULL AND role <> $1
+	`
+	_, err := r.db.ExecContext(ctx, query, models.RoleAdmin, time.Now(), pq.Array(emails))
 	return err
 }
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+type ViewRepository struct {
+	db *database.DB
+}
+
+func NewViewRepository(db *database.DB) *ViewRepository {
+	return &ViewRepository{db: db}
+}
+
+const viewColumns = `id, name, filter, sort, direction, group_by, created_at, updated_at`
+
+func scanView(row rowScanner) (*models.View, error) {
+	view := &models.View{}
+	var filter []byte
+	err := row.Scan(
+		&view.ID,
+		&view.Name,
+		&filter,
+		&view.Sort,
+		&view.Direction,
+		&view.GroupBy,
+		&view.CreatedAt,
+		&view.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filter, &view.Filter); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+func (r *ViewRepository) Create(ctx context.Context, view *models.View, userID uuid.UUID) error {
+	query := `
+		INSERT INTO views (id, user_id, name, filter, sort, direction, group_by, created_at, updated_at, workspace_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9,
+			COALESCE($10::uuid, (SELECT id FROM workspaces WHERE personal_user_id = $2)))
+	`
+
+	filter, err := json.Marshal(view.Filter)
+	if err != nil {
+		return err
+	}
+
+	view.ID = uuid.New().String()
+	now := time.Now()
+	view.CreatedAt = &now
+	view.UpdatedAt = &now
+
+	_, err = r.db.ExecContext(ctx, query,
+		view.ID,
+		userID,
+		view.Name,
+		filter,
+		view.Sort,
+		view.Direction,
+		view.GroupBy,
+		now,
+		now,
+		workspaceArg(ctx),
+	)
+	return err
+}
+
+func (r *ViewRepository) GetAll(ctx context.Context, userID uuid.UUID) ([]*models.View, error) {
+	query := `SELECT ` + viewColumns + ` FROM views
+		WHERE user_id = $1 AND ($2::uuid IS NULL OR workspace_id = $2)
+		ORDER BY created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, workspaceArg(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := []*models.View{}
+	for rows.Next() {
+		view, err := scanView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+
+	return views, rows.Err()
+}
+
+func (r *ViewRepository) GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.View, error) {
+	query := `SELECT ` + viewColumns + ` FROM views
+		WHERE id = $1 AND user_id = $2 AND ($3::uuid IS NULL OR workspace_id = $3)`
+
+	view, err := scanView(r.db.QueryRowContext(ctx, query, id, userID, workspaceArg(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return view, nil
+}
+
+func (r *ViewRepository) Update(ctx context.Context, view *models.View, userID uuid.UUID) error {
+	query := `
+		UPDATE views
+		SET name = $1, filter = $2, sort = $3, direction = $4, group_by = $5, updated_at = $6
+		WHERE id = $7 AND user_id = $8 AND ($9::uuid IS NULL OR workspace_id = $9)
+	`
+
+	filter, err := json.Marshal(view.Filter)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	view.UpdatedAt = &now
+
+	result, err := r.db.ExecContext(ctx, query,
+		view.Name,
+		filter,
+		view.Sort,
+		view.Direction,
+		view.GroupBy,
+		now,
+		view.ID,
+		userID,
+		workspaceArg(ctx),
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *ViewRepository) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	query := `DELETE FROM views WHERE id = $1 AND user_id = $2 AND ($3::uuid IS NULL OR workspace_id = $3)`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID, workspaceArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,382 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+type WebhookRepository struct {
+	db *database.DB
+}
+
+func NewWebhookRepository(db *database.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const webhookColumns = `id, user_id, url, events, secret, active, failure_count, disabled_at, created_at, updated_at`
+
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	hook := &models.Webhook{}
+	err := row.Scan(
+		&hook.ID,
+		&hook.UserID,
+		&hook.URL,
+		&hook.Events,
+		&hook.Secret,
+		&hook.Active,
+		&hook.FailureCount,
+		&hook.DisabledAt,
+		&hook.CreatedAt,
+		&hook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at`
+
+func scanDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	d := &models.WebhookDelivery{}
+	var payload []byte
+	err := row.Scan(
+		&d.ID,
+		&d.WebhookID,
+		&d.EventID,
+		&d.EventType,
+		&payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastStatusCode,
+		&d.LastError,
+		&d.CreatedAt,
+		&d.DeliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	return d, nil
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, hook *models.Webhook) error {
+	query := `
+		INSERT INTO webhooks (id, user_id, url, events, secret, active, failure_count, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	hook.ID = uuid.New()
+	now := time.Now()
+	hook.CreatedAt = now
+	hook.UpdatedAt = now
+	hook.Active = true
+
+	_, err := r.db.ExecContext(ctx, query,
+		hook.ID,
+		hook.UserID,
+		hook.URL,
+		hook.Events,
+		hook.Secret,
+		hook.Active,
+		hook.FailureCount,
+		hook.CreatedAt,
+		hook.UpdatedAt,
+	)
+	return err
+}
+
+func (r *WebhookRepository) GetAll(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = $1 ORDER BY created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hooks := []*models.Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+
+	return hooks, rows.Err()
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1 AND user_id = $2`
+
+	hook, err := scanWebhook(r.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return hook, nil
+}
+
+// GetSubscribed returns the user's active webhooks subscribed to an event.
+func (r *WebhookRepository) GetSubscribed(ctx context.Context, userID uuid.UUID, eventType string) ([]*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = $1 AND active AND $2 = ANY(events)`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, eventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hooks := []*models.Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+
+	return hooks, rows.Err()
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, hook *models.Webhook) error {
+	query := `
+		UPDATE webhooks
+		SET url = $1, events = $2, active = $3, failure_count = $4, disabled_at = $5, updated_at = $6
+		WHERE id = $7 AND user_id = $8
+	`
+
+	hook.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, query,
+		hook.URL,
+		hook.Events,
+		hook.Active,
+		hook.FailureCount,
+		hook.DisabledAt,
+		hook.UpdatedAt,
+		hook.ID,
+		hook.UserID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	query := `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RecordSuccess resets the consecutive failure counter of an endpoint.
+func (r *WebhookRepository) RecordSuccess(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE webhooks SET failure_count = 0 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// RecordFailure increments the consecutive failure counter and disables the
+// endpoint once it reaches disableAfter. It reports whether the endpoint was
+// disabled by this call.
+func (r *WebhookRepository) RecordFailure(ctx context.Context, id uuid.UUID, disableAfter int) (bool, error) {
+	query := `
+		UPDATE webhooks
+		SET failure_count = failure_count + 1,
+			active = CASE WHEN failure_count + 1 >= $1 THEN FALSE ELSE active END,
+			disabled_at = CASE WHEN failure_count + 1 >= $1 AND active THEN NOW() ELSE disabled_at END
+		WHERE id = $2
+		RETURNING failure_count, active
+	`
+
+	var failures int
+	var active bool
+	if err := r.db.QueryRowContext(ctx, query, disableAfter, id).Scan(&failures, &active); err != nil {
+		return false, err
+	}
+	justDisabled := !active && failures == disableAfter
+	return justDisabled, nil
+}
+
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	d.ID = uuid.New()
+	now := time.Now()
+	d.CreatedAt = now
+	d.Status = models.DeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = &now
+
+	_, err := r.db.ExecContext(ctx, query,
+		d.ID,
+		d.WebhookID,
+		d.EventID,
+		d.EventType,
+		[]byte(d.Payload),
+		d.Status,
+		d.Attempts,
+		d.NextAttemptAt,
+		d.CreatedAt,
+	)
+	return err
+}
+
+// GetDeliveries returns the most recent deliveries of a webhook owned by the
+// user.
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, webhookID uuid.UUID, userID uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	query := `
+		SELECT d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at, d.last_status_code, d.last_error, d.created_at, d.delivered_at
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.webhook_id = $1 AND w.user_id = $2
+		ORDER BY d.created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, webhookID, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID, webhookID uuid.UUID, userID uuid.UUID) (*models.WebhookDelivery, error) {
+	query := `
+		SELECT d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at, d.last_status_code, d.last_error, d.created_at, d.delivered_at
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.id = $1 AND d.webhook_id = $2 AND w.user_id = $3
+	`
+
+	d, err := scanDelivery(r.db.QueryRowContext(ctx, query, id, webhookID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// ClaimDueDeliveries leases up to limit pending deliveries whose next attempt
+// is due by pushing their next attempt into the future. Concurrent workers,
+// also on other instances, never claim the same delivery.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $2
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+
+	now := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, now.Add(lease), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// GetWebhookForDelivery loads the endpoint of a claimed delivery regardless of
+// owner; it is only used by the background dispatcher.
+func (r *WebhookRepository) GetWebhookForDelivery(ctx context.Context, webhookID uuid.UUID) (*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1`
+
+	hook, err := scanWebhook(r.db.QueryRowContext(ctx, query, webhookID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return hook, nil
+}
+
+// SaveAttempt stores the outcome of a delivery attempt.
+func (r *WebhookRepository) SaveAttempt(ctx context.Context, d *models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_status_code = $4, last_error = $5, delivered_at = $6
+		WHERE id = $7
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		d.Status,
+		d.Attempts,
+		d.NextAttemptAt,
+		d.LastStatusCode,
+		d.LastError,
+		d.DeliveredAt,
+		d.ID,
+	)
+	return err
+}
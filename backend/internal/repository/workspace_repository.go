@@ -0,0 +1,394 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/database"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+// ErrInvitationEmailMismatch is returned when a user accepts an invitation
+// that was sent to another address.
+var ErrInvitationEmailMismatch = errors.New("invitation was sent to another email address")
+
+type WorkspaceRepository struct {
+	db *database.DB
+}
+
+func NewWorkspaceRepository(db *database.DB) *WorkspaceRepository {
+	return &WorkspaceRepository{db: db}
+}
+
+const workspaceColumns = `w.id, w.name, w.personal_user_id IS NOT NULL, m.role, w.created_at, w.updated_at`
+
+func scanWorkspace(row rowScanner) (*models.Workspace, error) {
+	ws := &models.Workspace{}
+	err := row.Scan(
+		&ws.ID,
+		&ws.Name,
+		&ws.Personal,
+		&ws.Role,
+		&ws.CreatedAt,
+		&ws.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+const invitationColumns = `id, workspace_id, email, token_hash, invited_by, expires_at, accepted_at, created_at`
+
+func scanInvitation(row rowScanner) (*models.WorkspaceInvitation, error) {
+	inv := &models.WorkspaceInvitation{}
+	err := row.Scan(
+		&inv.ID,
+		&inv.WorkspaceID,
+		&inv.Email,
+		&inv.TokenHash,
+		&inv.InvitedBy,
+		&inv.ExpiresAt,
+		&inv.AcceptedAt,
+		&inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// Create stores a shared workspace with its creator as owner.
+func (r *WorkspaceRepository) Create(ctx context.Context, ws *models.Workspace, ownerID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ws.ID = uuid.New()
+	now := time.Now().UTC()
+	ws.CreatedAt = now
+	ws.UpdatedAt = now
+	ws.Role = models.WorkspaceRoleOwner
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO workspaces (id, name, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, ws.ID, ws.Name, ownerID, ws.CreatedAt, ws.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, $4)
+	`, ws.ID, ownerID, ws.Role, now)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListForUser returns the workspaces the user is a member of, personal first.
+func (r *WorkspaceRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]*models.Workspace, error) {
+	query := `
+		SELECT ` + workspaceColumns + `
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $1
+		ORDER BY w.personal_user_id IS NULL, w.name, w.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workspaces := []*models.Workspace{}
+	for rows.Next() {
+		ws, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, ws)
+	}
+
+	return workspaces, rows.Err()
+}
+
+// GetForUser returns the workspace with the user's role in it, or nil when
+// the user is not a member.
+func (r *WorkspaceRepository) GetForUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Workspace, error) {
+	query := `
+		SELECT ` + workspaceColumns + `
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $2
+		WHERE w.id = $1
+	`
+
+	ws, err := scanWorkspace(r.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// GetPersonal returns the user's personal workspace.
+func (r *WorkspaceRepository) GetPersonal(ctx context.Context, userID uuid.UUID) (*models.Workspace, error) {
+	query := `
+		SELECT ` + workspaceColumns + `
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $1
+		WHERE w.personal_user_id = $1
+	`
+
+	ws, err := scanWorkspace(r.db.QueryRowContext(ctx, query, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// IsMember reports whether the user belongs to the workspace.
+func (r *WorkspaceRepository) IsMember(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	var member bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM workspace_members WHERE workspace_id = $1 AND user_id = $2)
+	`, id, userID).Scan(&member)
+	return member, err
+}
+
+// Rename changes the name of a shared workspace.
+func (r *WorkspaceRepository) Rename(ctx context.Context, id uuid.UUID, name string) error {
+	query := `UPDATE workspaces SET name = $1, updated_at = $2 WHERE id = $3 AND personal_user_id IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, name, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Delete removes a shared workspace together with its todos, feeds and
+// invitations.
+func (r *WorkspaceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM workspaces WHERE id = $1 AND personal_user_id IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *WorkspaceRepository) Members(ctx context.Context, id uuid.UUID) ([]*models.WorkspaceMember, error) {
+	query := `
+		SELECT m.user_id, u.name, u.email, m.role, m.joined_at
+		FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY m.role = 'owner' DESC, u.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*models.WorkspaceMember{}
+	for rows.Next() {
+		m := &models.WorkspaceMember{}
+		if err := rows.Scan(&m.UserID, &m.Name, &m.Email, &m.Role, &m.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// RemoveMember takes the user out of the workspace and unassigns the
+// workspace's todos from them. Todos they own stay in the workspace.
+func (r *WorkspaceRepository) RemoveMember(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE todos SET assignee_id = NULL, updated_at = $3 WHERE workspace_id = $1 AND assignee_id = $2
+	`, id, userID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *WorkspaceRepository) CreateInvitation(ctx context.Context, inv *models.WorkspaceInvitation) error {
+	query := `
+		INSERT INTO workspace_invitations (id, workspace_id, email, token_hash, invited_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	inv.ID = uuid.New()
+	inv.CreatedAt = time.Now().UTC()
+
+	_, err := r.db.ExecContext(ctx, query,
+		inv.ID,
+		inv.WorkspaceID,
+		inv.Email,
+		inv.TokenHash,
+		inv.InvitedBy,
+		inv.ExpiresAt.UTC(),
+		inv.CreatedAt,
+	)
+	return err
+}
+
+// GetInvitations returns the workspace's pending invitations.
+func (r *WorkspaceRepository) GetInvitations(ctx context.Context, workspaceID uuid.UUID) ([]*models.WorkspaceInvitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM workspace_invitations
+		WHERE workspace_id = $1 AND accepted_at IS NULL AND expires_at > $2
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, workspaceID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []*models.WorkspaceInvitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, inv)
+	}
+
+	return invitations, rows.Err()
+}
+
+func (r *WorkspaceRepository) DeleteInvitation(ctx context.Context, id uuid.UUID, workspaceID uuid.UUID) error {
+	query := `DELETE FROM workspace_invitations WHERE id = $1 AND workspace_id = $2 AND accepted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// AcceptInvitation adds the user to the workspace of a pending invitation
+// sent to their email and marks it accepted. It returns nil when the token
+// is unknown, expired or already used.
+func (r *WorkspaceRepository) AcceptInvitation(ctx context.Context, tokenHash string, user *models.User) (*models.WorkspaceInvitation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM workspace_invitations
+		WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > $2
+		FOR UPDATE
+	`
+
+	inv, err := scanInvitation(tx.QueryRowContext(ctx, query, tokenHash, now))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if !strings.EqualFold(inv.Email, user.Email) {
+		return nil, ErrInvitationEmailMismatch
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (workspace_id, user_id) DO NOTHING
+	`, inv.WorkspaceID, user.ID, models.WorkspaceRoleMember, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE workspace_invitations SET accepted_at = $1 WHERE id = $2`, now, inv.ID); err != nil {
+		return nil, err
+	}
+	inv.AcceptedAt = &now
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
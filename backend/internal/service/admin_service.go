@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+)
+
+// Page size bounds of the admin user listing.
+const (
+	defaultUserPageSize = 50
+	maxUserPageSize     = 200
+)
+
+var ErrCannotTargetSelf = errors.New("admins cannot disable or delete their own account")
+
+// AdminService lets admins operate user accounts. Every change is audited.
+type AdminService struct {
+	userRepo *repository.UserRepository
+	sessions *SessionCache
+	policy   *PolicyService
+}
+
+func NewAdminService(userRepo *repository.UserRepository, sessions *SessionCache, policy *PolicyService) *AdminService {
+	return &AdminService{
+		userRepo: userRepo,
+		sessions: sessions,
+		policy:   policy,
+	}
+}
+
+func (s *AdminService) List(ctx context.Context, filters models.UserFilters) ([]*models.AdminUser, error) {
+	if filters.Limit <= 0 {
+		filters.Limit = defaultUserPageSize
+	}
+	if filters.Limit > maxUserPageSize {
+		filters.Limit = maxUserPageSize
+	}
+	if filters.Offset < 0 {
+		filters.Offset = 0
+	}
+	return s.userRepo.List(ctx, filters)
+}
+
+func (s *AdminService) Get(ctx context.Context, id uuid.UUID) (*models.AdminUser, error) {
+	user, err := s.userRepo.GetWithCounts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// Disable blocks the user from signing in and invalidates their tokens until
+// they are enabled again.
+func (s *AdminService) Disable(ctx context.Context, id uuid.UUID, actorID uuid.UUID) (*models.User, error) {
+	if id == actorID {
+		return nil, ErrCannotTargetSelf
+	}
+	return s.change(ctx, id, actorID, models.AuditUserDisabled, func(user *models.User) bool {
+		if user.DisabledAt != nil {
+			return false
+		}
+		now := time.Now().UTC()
+		user.DisabledAt = &now
+		return true
+	})
+}
+
+func (s *AdminService) Enable(ctx context.Context, id uuid.UUID, actorID uuid.UUID) (*models.User, error) {
+	return s.change(ctx, id, actorID, models.AuditUserEnabled, func(user *models.User) bool {
+		if user.DisabledAt == nil {
+			return false
+		}
+		user.DisabledAt = nil
+		return true
+	})
+}
+
+// ForcePasswordReset signs the user out and makes them choose a new password
+// before they can sign in again.
+func (s *AdminService) ForcePasswordReset(ctx context.Context, id uuid.UUID, actorID uuid.UUID) (*models.User, error) {
+	return s.change(ctx, id, actorID, models.AuditPasswordReset, func(user *models.User) bool {
+		if user.PasswordResetRequired {
+			return false
+		}
+		user.PasswordResetRequired = true
+		return true
+	})
+}
+
+// Delete removes the user and everything they own.
+func (s *AdminService) Delete(ctx context.Context, id uuid.UUID, actorID uuid.UUID) error {
+	if id == actorID {
+		return ErrCannotTargetSelf
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if err := s.userRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	s.sessions.Forget(id)
+
+	s.policy.audit(ctx, actorID, models.AuditUserDeleted, "user:"+id.String(), map[string]string{
+		"email": user.Email,
+	})
+	return nil
+}
+
+// change applies apply to the user and saves and audits it when apply
+// reports a change.
+func (s *AdminService) change(ctx context.Context, id uuid.UUID, actorID uuid.UUID, action models.AuditAction, apply func(*models.User) bool) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if !apply(user) {
+		return user, nil
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	s.sessions.Forget(id)
+
+	s.policy.audit(ctx, actorID, action, "user:"+id.String(), nil)
+	return user, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/config"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+	"github.com/yourusername/todogo-backend/internal/tenant"
+)
+
+const archiveBatchSize = 500
+
+var ErrArchiveRuleNotFound = errors.New("archive rule not found")
+
+type ArchiveService struct {
+	archiveRepo *repository.ArchiveRepository
+	todoService *TodoService
+	cfg         config.ArchiveConfig
+}
+
+func NewArchiveService(archiveRepo *repository.ArchiveRepository, todoService *TodoService, cfg config.ArchiveConfig) *ArchiveService {
+	return &ArchiveService{
+		archiveRepo: archiveRepo,
+		todoService: todoService,
+		cfg:         cfg,
+	}
+}
+
+func (s *ArchiveService) GetRule(ctx context.Context, userID uuid.UUID) (*models.ArchiveRule, error) {
+	rule, err := s.archiveRepo.GetRule(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, ErrArchiveRuleNotFound
+	}
+	return rule, nil
+}
+
+func (s *ArchiveService) UpdateRule(ctx context.Context, req models.UpdateArchiveRuleRequest, userID uuid.UUID) (*models.ArchiveRule, error) {
+	rule := &models.ArchiveRule{
+		UserID:             userID,
+		CompletedAfterDays: req.CompletedAfterDays,
+		Enabled:            true,
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if err := s.archiveRepo.UpsertRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *ArchiveService) DeleteRule(ctx context.Context, userID uuid.UUID) error {
+	if err := s.archiveRepo.DeleteRule(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrArchiveRuleNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Run applies the archive rules periodically until ctx is cancelled.
+func (s *ArchiveService) Run(ctx context.Context) {
+	ctx = tenant.AllWorkspaces(ctx)
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.archiveDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ArchiveService) archiveDue(ctx context.Context) {
+	total := 0
+	for {
+		n, err := s.todoService.ArchiveByRules(ctx, archiveBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error().Err(err).Msg("Failed to archive todos")
+			}
+			return
+		}
+		total += n
+
+		if n < archiveBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Info().Int("count", total).Msg("Archived completed todos")
+	}
+}
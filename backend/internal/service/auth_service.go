@@ -2,33 +2,69 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/config"
 	"github.com/yourusername/todogo-backend/internal/models"
 	"github.com/yourusername/todogo-backend/internal/repository"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrAccountDisabled       = errors.New("account is disabled")
+	ErrPasswordResetRequired = errors.New("password reset required")
+	ErrSessionExpired        = errors.New("session is no longer valid, sign in again")
+	ErrInvalidRefreshToken   = errors.New("invalid or expired refresh token")
+	ErrEmailNotVerified      = errors.New("email address is not verified")
+	ErrEmailTaken            = errors.New("email address is already in use")
+	ErrMFARequired           = errors.New("two-factor authentication is enabled, sign in with a token")
+	ErrMFACodeRequired       = errors.New("two-factor authentication is enabled, a code is required")
+)
+
 type AuthService struct {
-	userRepo  *repository.UserRepository
-	jwtSecret string
-	jwtExpiry time.Duration
+	userRepo      *repository.UserRepository
+	refreshRepo   *repository.RefreshTokenRepository
+	sessions      *SessionCache
+	verifier      *EmailVerificationService
+	mfa           *MFAService
+	policy        *PolicyService
+	jwtSecret     string
+	jwtExpiry     time.Duration
+	refreshExpiry time.Duration
 }
 
 type Claims struct {
 	UserID uuid.UUID `json:"user_id"`
 	Email  string    `json:"email"`
+	// Role is the user's role when the token was issued; permissions are
+	// checked against the current one.
+	Role models.Role `json:"role"`
+	// WorkspaceID is the workspace requests with the token work in when they
+	// do not name one; the user's personal workspace when nil.
+	WorkspaceID *uuid.UUID `json:"workspace_id,omitempty"`
+	// Generation is the user's token generation at issue; signing out
+	// everywhere bumps it. The token's own ID is the jti claim.
+	Generation int `json:"gen"`
 	jwt.RegisteredClaims
 }
 
-func NewAuthService(userRepo *repository.UserRepository, jwtSecret string, jwtExpiry time.Duration) *AuthService {
+func NewAuthService(userRepo *repository.UserRepository, refreshRepo *repository.RefreshTokenRepository, sessions *SessionCache, verifier *EmailVerificationService, mfa *MFAService, policy *PolicyService, cfg config.JWTConfig) *AuthService {
 	return &AuthService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
-		jwtExpiry: jwtExpiry,
+		userRepo:      userRepo,
+		refreshRepo:   refreshRepo,
+		sessions:      sessions,
+		verifier:      verifier,
+		mfa:           mfa,
+		policy:        policy,
+		jwtSecret:     cfg.Secret,
+		jwtExpiry:     cfg.Expiration,
+		refreshExpiry: cfg.RefreshExpiration,
 	}
 }
 
@@ -53,27 +89,72 @@ func (s *AuthService) Register(ctx context.Context, req models.RegisterRequest)
 		Name:     req.Name,
 		Email:    req.Email,
 		Password: string(hashedPassword),
+		Role:     s.policy.RoleForNewUser(req.Email, false),
 	}
 
 	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, err
 	}
 
-	// Generate token
-	token, err := s.generateToken(user)
+	s.verifier.Send(ctx, user)
+	if s.verifier.Required() {
+		return &models.LoginResponse{User: *user}, nil
+	}
+
+	return s.issue(ctx, user, nil)
+}
+
+// Login checks the credentials and signs the user in, or asks for a second
+// factor when they have two-factor authentication.
+func (s *AuthService) Login(ctx context.Context, req models.LoginRequest) (*models.LoginResponse, error) {
+	user, err := s.checkPassword(ctx, req.Email, req.Password)
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.LoginResponse{
-		Token: token,
-		User:  *user,
-	}, nil
+	return s.signIn(ctx, user)
 }
 
-func (s *AuthService) Login(ctx context.Context, req models.LoginRequest) (*models.LoginResponse, error) {
+// VerifyMFA completes a sign-in that asked for a second factor.
+func (s *AuthService) VerifyMFA(ctx context.Context, req models.MFAVerifyRequest) (*models.LoginResponse, error) {
+	user, err := s.mfa.Redeem(ctx, req.MFAToken, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAccount(user); err != nil {
+		return nil, err
+	}
+
+	return s.issue(ctx, user, nil)
+}
+
+// SignInExternal signs in a user who authenticated elsewhere, such as at a
+// single sign-on provider. Two-factor authentication still applies.
+func (s *AuthService) SignInExternal(ctx context.Context, user *models.User) (*models.LoginResponse, error) {
+	if err := s.checkAccount(user); err != nil {
+		return nil, err
+	}
+
+	return s.signIn(ctx, user)
+}
+
+// Authenticate checks an email and password pair without issuing a token.
+// Clients that cannot carry a JWT, such as CalDAV apps, use it per request.
+// Users with two-factor authentication have to use a token instead.
+func (s *AuthService) Authenticate(ctx context.Context, email, password string) (*models.User, error) {
+	user, err := s.checkPassword(ctx, email, password)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrMFARequired
+	}
+	return user, nil
+}
+
+func (s *AuthService) checkPassword(ctx context.Context, email, password string) (*models.User, error) {
 	// Get user by email
-	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
@@ -82,35 +163,299 @@ func (s *AuthService) Login(ctx context.Context, req models.LoginRequest) (*mode
 	}
 
 	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, errors.New("invalid credentials")
+	}
+
+	if err := s.checkAccount(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// checkAccount refuses users who may not sign in.
+func (s *AuthService) checkAccount(user *models.User) error {
+	if user.DisabledAt != nil {
+		return ErrAccountDisabled
+	}
+	if user.PasswordResetRequired {
+		return ErrPasswordResetRequired
+	}
+	if s.verifier.Required() && user.EmailVerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// ChangePassword replaces the user's password after checking the current one
+// and, with two-factor authentication, a TOTP or recovery code, and signs them
+// in. Tokens issued before the change stop working.
+func (s *AuthService) ChangePassword(ctx context.Context, req models.ChangePasswordRequest) (*models.LoginResponse, error) {
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("invalid credentials")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
 		return nil, errors.New("invalid credentials")
 	}
+	if user.DisabledAt != nil {
+		return nil, ErrAccountDisabled
+	}
+	if user.TOTPEnabledAt != nil {
+		if req.Code == "" {
+			return nil, ErrMFACodeRequired
+		}
+		if err := s.mfa.checkCode(ctx, user.ID, req.Code, true); err != nil {
+			return nil, err
+		}
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	user.Password = string(hashedPassword)
+	user.PasswordResetRequired = false
+	user.PasswordChangedAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.refreshRepo.RevokeForUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	s.sessions.Forget(user.ID)
+
+	// The second factor, if any, was checked above
+	return s.issue(ctx, user, nil)
+}
+
+// ChangeEmail moves the user to a new address after checking their password
+// and sends a verification link there. The new address is unverified until
+// the link is followed.
+func (s *AuthService) ChangeEmail(ctx context.Context, userID uuid.UUID, req models.ChangeEmailRequest) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return nil, errors.New("invalid credentials")
+	}
+	if strings.EqualFold(user.Email, req.Email) {
+		return user, nil
+	}
+
+	existing, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrEmailTaken
+	}
+
+	user.Email = req.Email
+	user.EmailVerifiedAt = nil
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	s.sessions.Forget(user.ID)
+
+	s.verifier.Send(ctx, user)
+	return user, nil
+}
+
+// Logout revokes the access token with the claims, and the refresh token
+// with its family when one is given.
+func (s *AuthService) Logout(ctx context.Context, claims *Claims, refreshToken string) error {
+	jti, err := uuid.Parse(claims.ID)
+	if err != nil || claims.ExpiresAt == nil {
+		return ErrSessionExpired
+	}
+	if err := s.sessions.Revoke(ctx, jti, claims.UserID, claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	return s.refreshRepo.RevokeFamily(ctx, claims.UserID, hashSecret(refreshToken))
+}
+
+// LogoutEverywhere revokes every access and refresh token the user holds.
+func (s *AuthService) LogoutEverywhere(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.userRepo.IncrementTokenGeneration(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	s.sessions.Forget(userID)
+
+	return s.refreshRepo.RevokeForUser(ctx, userID)
+}
+
+func (s *AuthService) GetUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+// GetUsersByIDs loads the given users; unknown IDs are left out.
+func (s *AuthService) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	return s.userRepo.GetByIDs(ctx, ids)
+}
+
+// IssueWorkspaceToken issues a token for the user that defaults to the given
+// workspace. Callers check the user's membership.
+func (s *AuthService) IssueWorkspaceToken(ctx context.Context, userID uuid.UUID, workspaceID uuid.UUID) (*models.LoginResponse, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issue(ctx, user, &workspaceID)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new
+// refresh token. Presenting a token that was already exchanged revokes every
+// token descended from the same sign-in, since either the client or an
+// attacker holds a stolen copy.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*models.LoginResponse, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	next := &models.RefreshToken{
+		TokenHash: hashSecret(secret),
+		ExpiresAt: time.Now().Add(s.refreshExpiry),
+	}
+
+	// The account is checked before the successor is stored, so that a
+	// refused refresh leaves no new token behind.
+	var user *models.User
+	check := func(userID uuid.UUID) error {
+		u, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if u == nil {
+			return ErrInvalidRefreshToken
+		}
+		if err := s.checkAccount(u); err != nil {
+			return err
+		}
+		user = u
+		return nil
+	}
+
+	current, err := s.refreshRepo.Rotate(ctx, hashSecret(refreshToken), next, check)
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenReused) {
+			log.Warn().
+				Str("user_id", current.UserID.String()).
+				Str("family_id", current.FamilyID.String()).
+				Msg("Refresh token reused, revoked its family")
+			s.policy.audit(ctx, current.UserID, models.AuditTokenReused, "token_family:"+current.FamilyID.String(), nil)
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return s.respond(user, current.WorkspaceID, secret)
+}
+
+// signIn issues tokens for the user, or starts a challenge for the second
+// factor when they have two-factor authentication.
+func (s *AuthService) signIn(ctx context.Context, user *models.User) (*models.LoginResponse, error) {
+	if user.TOTPEnabledAt == nil {
+		return s.issue(ctx, user, nil)
+	}
 
-	// Generate token
-	token, err := s.generateToken(user)
+	token, _, err := s.mfa.Challenge(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResponse{
+		MFARequired: true,
+		MFAToken:    token,
+		User:        *user,
+	}, nil
+}
+
+// issue signs the user in: it starts a new refresh token family and returns
+// it with an access token.
+func (s *AuthService) issue(ctx context.Context, user *models.User, workspaceID *uuid.UUID) (*models.LoginResponse, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	refresh := &models.RefreshToken{
+		UserID:      user.ID,
+		TokenHash:   hashSecret(secret),
+		WorkspaceID: workspaceID,
+		ExpiresAt:   time.Now().Add(s.refreshExpiry),
+	}
+	if err := s.refreshRepo.Create(ctx, refresh); err != nil {
+		return nil, err
+	}
+
+	return s.respond(user, workspaceID, secret)
+}
+
+func (s *AuthService) respond(user *models.User, workspaceID *uuid.UUID, refreshToken string) (*models.LoginResponse, error) {
+	token, expiresAt, err := s.generateToken(user, workspaceID)
 	if err != nil {
 		return nil, err
 	}
 
 	return &models.LoginResponse{
-		Token: token,
-		User:  *user,
+		Token:        token,
+		ExpiresAt:    &expiresAt,
+		RefreshToken: refreshToken,
+		User:         *user,
 	}, nil
 }
 
-func (s *AuthService) generateToken(user *models.User) (string, error) {
+func (s *AuthService) generateToken(user *models.User, workspaceID *uuid.UUID) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.jwtExpiry)
 	claims := &Claims{
-		UserID: user.ID,
-		Email:  user.Email,
+		UserID:      user.ID,
+		Email:       user.Email,
+		Role:        user.Role,
+		WorkspaceID: workspaceID,
+		Generation:  user.TokenGeneration,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.jwtExpiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtSecret))
+	signed, err := token.SignedString([]byte(s.jwtSecret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt.UTC().Truncate(time.Second), nil
 }
 
 func (s *AuthService) ValidateToken(tokenString string) (*Claims, error) {
@@ -128,3 +473,47 @@ func (s *AuthService) ValidateToken(tokenString string) (*Claims, error) {
 
 	return nil, errors.New("invalid token")
 }
+
+// ValidateSession validates the token and checks that its user may still
+// use it: the token was not signed out, and the account exists, is enabled,
+// needs no password reset and has not changed its password or signed out
+// everywhere since the token was issued. The checks are served from the
+// session cache.
+func (s *AuthService) ValidateSession(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	jti, err := uuid.Parse(claims.ID)
+	if err != nil || claims.ExpiresAt == nil {
+		return nil, ErrSessionExpired
+	}
+	revoked, err := s.sessions.Revoked(ctx, jti, claims.ExpiresAt.Time)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrSessionExpired
+	}
+
+	user, err := s.sessions.User(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrSessionExpired
+	}
+	if err := s.checkAccount(user); err != nil {
+		return nil, err
+	}
+	if user.PasswordChangedAt != nil && claims.IssuedAt != nil &&
+		claims.IssuedAt.Time.Before(user.PasswordChangedAt.Truncate(time.Second)) {
+		return nil, ErrSessionExpired
+	}
+	if claims.Generation < user.TokenGeneration {
+		return nil, ErrSessionExpired
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/pkg/ical"
+)
+
+const (
+	calendarProdID = "-//Todogo//Todogo API//EN"
+
+	// uidDomain suffixes the UIDs of todos that originate in Todogo so that
+	// re-importing our own export maps back onto the same rows.
+	uidDomain = "@todogo"
+	dueSuffix = "-due"
+)
+
+var ErrInvalidCalendar = errors.New("invalid calendar file")
+
+type CalendarService struct {
+	todoService *TodoService
+}
+
+type ImportResult struct {
+	Created int      `json:"created"`
+	Updated int      `json:"updated"`
+	Skipped int      `json:"skipped"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+func NewCalendarService(todoService *TodoService) *CalendarService {
+	return &CalendarService{
+		todoService: todoService,
+	}
+}
+
+// Export renders the user's todos as a VCALENDAR containing one VTODO per todo
+// and an additional VEVENT for every todo that has a due date.
+func (s *CalendarService) Export(ctx context.Context, userID uuid.UUID, filters models.TodoFilters) ([]byte, error) {
+	todos, err := s.todoService.GetAll(ctx, userID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return EncodeCalendar(todos, "Todogo")
+}
+
+// EncodeCalendar renders todos as an iCalendar document with the given name.
+func EncodeCalendar(todos []*models.Todo, name string) ([]byte, error) {
+	cal := ical.NewComponent("VCALENDAR")
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", calendarProdID)
+	cal.Add("CALSCALE", "GREGORIAN")
+	cal.AddText("X-WR-CALNAME", name)
+
+	now := time.Now()
+	for _, todo := range todos {
+		cal.AddChild(TodoToVTodo(todo, now))
+		if todo.DueDate != nil {
+			cal.AddChild(todoToVEvent(todo, now))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, cal); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodeVTodo renders a single todo as a calendar object resource, i.e. a
+// VCALENDAR holding one VTODO, as served by CalDAV.
+func EncodeVTodo(todo *models.Todo) ([]byte, error) {
+	cal := ical.NewComponent("VCALENDAR")
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", calendarProdID)
+	cal.AddChild(TodoToVTodo(todo, time.Now()))
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, cal); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Import creates or updates todos from the VTODO components of an iCalendar
+// stream. Components are matched by UID so repeated imports are idempotent.
+// VEVENTs are ignored; they only mirror due dates on export.
+func (s *CalendarService) Import(ctx context.Context, userID uuid.UUID, r io.Reader) (*ImportResult, error) {
+	roots, err := ical.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+
+	var vtodos []*ical.Component
+	for _, root := range roots {
+		if root.Name == "VCALENDAR" {
+			vtodos = append(vtodos, root.Find("VTODO")...)
+		}
+	}
+	if len(roots) == 0 {
+		return nil, ErrInvalidCalendar
+	}
+
+	result := &ImportResult{}
+	for _, vtodo := range vtodos {
+		_, created, err := s.SaveVTodo(ctx, userID, vtodo)
+		if err != nil {
+			uid, _ := vtodo.Text("UID")
+			result.Skipped++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", uid, err))
+			continue
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+// SaveVTodo creates or replaces the todo identified by the component's UID
+// and reports whether it was created.
+func (s *CalendarService) SaveVTodo(ctx context.Context, userID uuid.UUID, vtodo *ical.Component) (*models.Todo, bool, error) {
+	uid, _ := vtodo.Text("UID")
+	uid = strings.TrimSpace(uid)
+	if uid == "" {
+		return nil, false, errors.New("missing UID")
+	}
+
+	parsed, err := VTodoToTodo(vtodo)
+	if err != nil {
+		return nil, false, err
+	}
+
+	existing, err := s.todoService.GetByICalUID(ctx, uid, userID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if existing == nil {
+		req := models.CreateTodoRequest{
+			Title:       parsed.Title,
+			Description: parsed.Description,
+			Priority:    &parsed.Priority,
+			DueDate:     parsed.DueDate,
+			Tags:        parsed.Tags,
+			ICalUID:     &uid,
+		}
+		todo, err := s.todoService.Create(ctx, req, userID)
+		if err != nil {
+			return nil, false, err
+		}
+		if parsed.Completed {
+			todo, err = s.todoService.SetCompletedAt(ctx, todo.ID, userID, parsed.CompletedAt)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+		return todo, true, nil
+	}
+
+	existing.Title = parsed.Title
+	existing.Description = parsed.Description
+	existing.Priority = parsed.Priority
+	existing.DueDate = parsed.DueDate
+	existing.Tags = parsed.Tags
+	existing.Completed = parsed.Completed
+	existing.CompletedAt = parsed.CompletedAt
+
+	todo, err := s.todoService.Replace(ctx, existing)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return todo, false, nil
+}
+
+// TodoUID returns the iCalendar UID of a todo.
+func TodoUID(todo *models.Todo) string {
+	if todo.ICalUID != nil && *todo.ICalUID != "" {
+		return *todo.ICalUID
+	}
+	return todo.ID.String() + uidDomain
+}
+
+// TombstoneUID returns the iCalendar UID a deleted todo had.
+func TombstoneUID(t *models.TodoTombstone) string {
+	if t.ICalUID != nil && *t.ICalUID != "" {
+		return *t.ICalUID
+	}
+	return t.TodoID.String() + uidDomain
+}
+
+func parseTodoUID(uid string) (uuid.UUID, bool) {
+	if !strings.HasSuffix(uid, uidDomain) {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(strings.TrimSuffix(uid, uidDomain))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// TodoToVTodo maps a todo onto a VTODO component.
+func TodoToVTodo(todo *models.Todo, stamp time.Time) *ical.Component {
+	c := ical.NewComponent("VTODO")
+	c.AddText("UID", TodoUID(todo))
+	c.AddTime("DTSTAMP", stamp)
+	c.AddTime("CREATED", todo.CreatedAt)
+	c.AddTime("LAST-MODIFIED", todo.UpdatedAt)
+	c.AddText("SUMMARY", todo.Title)
+	if todo.Description != nil && *todo.Description != "" {
+		c.AddText("DESCRIPTION", *todo.Description)
+	}
+	c.Add("PRIORITY", fmt.Sprint(priorityToICal(todo.Priority)))
+	if todo.DueDate != nil {
+		c.AddTime("DUE", *todo.DueDate)
+	}
+	if len(todo.Tags) > 0 {
+		c.AddList("CATEGORIES", todo.Tags)
+	}
+	if todo.Completed {
+		c.Add("STATUS", "COMPLETED")
+		c.Add("PERCENT-COMPLETE", "100")
+		if todo.CompletedAt != nil {
+			c.AddTime("COMPLETED", *todo.CompletedAt)
+		}
+	} else {
+		c.Add("STATUS", "NEEDS-ACTION")
+	}
+	return c
+}
+
+// todoToVEvent produces a zero-duration event at the todo's due date so that
+// calendar apps without task support still show the deadline.
+func todoToVEvent(todo *models.Todo, stamp time.Time) *ical.Component {
+	c := ical.NewComponent("VEVENT")
+	c.AddText("UID", todo.ID.String()+dueSuffix+uidDomain)
+	c.AddTime("DTSTAMP", stamp)
+	c.AddTime("DTSTART", *todo.DueDate)
+	c.AddTime("DTEND", *todo.DueDate)
+	c.AddText("SUMMARY", "Due: "+todo.Title)
+	if todo.Description != nil && *todo.Description != "" {
+		c.AddText("DESCRIPTION", *todo.Description)
+	}
+	if len(todo.Tags) > 0 {
+		c.AddList("CATEGORIES", todo.Tags)
+	}
+	c.Add("TRANSP", "TRANSPARENT")
+	c.AddText("RELATED-TO", TodoUID(todo))
+	return c
+}
+
+// VTodoToTodo maps a VTODO component onto an unsaved todo. Completion is
+// reported through Completed and CompletedAt.
+func VTodoToTodo(c *ical.Component) (*models.Todo, error) {
+	title, _ := c.Text("SUMMARY")
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, errors.New("missing SUMMARY")
+	}
+	if runes := []rune(title); len(runes) > 200 {
+		title = string(runes[:200])
+	}
+
+	todo := &models.Todo{
+		Title:    title,
+		Priority: models.PriorityMedium,
+		Tags:     c.List("CATEGORIES"),
+	}
+
+	if desc, ok := c.Text("DESCRIPTION"); ok && desc != "" {
+		todo.Description = &desc
+	}
+
+	if p := c.Get("PRIORITY"); p != nil {
+		var n int
+		if _, err := fmt.Sscan(p.Value, &n); err == nil {
+			todo.Priority = priorityFromICal(n)
+		}
+	}
+
+	due, err := c.Time("DUE")
+	if err != nil {
+		return nil, fmt.Errorf("invalid DUE: %w", err)
+	}
+	todo.DueDate = due
+
+	status, _ := c.Text("STATUS")
+	completedAt, err := c.Time("COMPLETED")
+	if err != nil {
+		return nil, fmt.Errorf("invalid COMPLETED: %w", err)
+	}
+	if strings.EqualFold(status, "COMPLETED") || completedAt != nil {
+		if completedAt == nil {
+			now := time.Now()
+			completedAt = &now
+		}
+		todo.Completed = true
+		todo.CompletedAt = completedAt
+	}
+
+	return todo, nil
+}
+
+// priorityToICal maps onto the RFC 5545 1-9 scale (1 highest, 0 undefined).
+func priorityToICal(p models.TodoPriority) int {
+	switch p {
+	case models.PriorityHigh:
+		return 1
+	case models.PriorityLow:
+		return 9
+	default:
+		return 5
+	}
+}
+
+func priorityFromICal(n int) models.TodoPriority {
+	switch {
+	case n >= 1 && n <= 4:
+		return models.PriorityHigh
+	case n >= 6 && n <= 9:
+		return models.PriorityLow
+	default:
+		return models.PriorityMedium
+	}
+}
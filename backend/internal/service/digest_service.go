@@ -0,0 +1,347 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/config"
+	"github.com/yourusername/todogo-backend/internal/mailer"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+	"github.com/yourusername/todogo-backend/internal/tenant"
+)
+
+const (
+	digestBatchSize = 20
+	// digestLease is how long a claimed schedule stays hidden from other
+	// workers; a failed send is retried once it expires.
+	digestLease = 10 * time.Minute
+	// maxDigestAttempts is how often one run is tried before it is skipped.
+	maxDigestAttempts = 3
+)
+
+var (
+	ErrDigestNotFound        = errors.New("digest schedule not found")
+	ErrInvalidDigestSchedule = errors.New("weekday is required for weekly digests")
+)
+
+//go:embed templates/digest.html templates/digest.txt
+var digestTemplates embed.FS
+
+// digestFuncs is shared by the HTML and text templates.
+var digestFuncs = map[string]interface{}{
+	"section": func(title, color string, items []digestItem) digestSection {
+		return digestSection{Title: title, Color: color, Items: items}
+	},
+}
+
+var (
+	digestHTML = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(digestFuncs).ParseFS(digestTemplates, "templates/digest.html"))
+	digestText = texttemplate.Must(texttemplate.New("digest.txt").Funcs(digestFuncs).ParseFS(digestTemplates, "templates/digest.txt"))
+)
+
+type digestItem struct {
+	Title    string
+	Due      string
+	Priority models.TodoPriority
+	Tags     []string
+}
+
+type digestSection struct {
+	Title string
+	Color string
+	Items []digestItem
+}
+
+type digestData struct {
+	Subject      string
+	Name         string
+	Frequency    models.DigestFrequency
+	Date         string
+	Overdue      []digestItem
+	Today        []digestItem
+	Upcoming     []digestItem
+	UpcomingDays int
+	AppURL       string
+}
+
+func (d *digestData) Empty() bool {
+	return len(d.Overdue) == 0 && len(d.Today) == 0 && len(d.Upcoming) == 0
+}
+
+type DigestService struct {
+	digestRepo *repository.DigestRepository
+	todoRepo   *repository.TodoRepository
+	userRepo   *repository.UserRepository
+	mailer     mailer.Mailer
+	cfg        config.DigestConfig
+	appURL     string
+}
+
+func NewDigestService(digestRepo *repository.DigestRepository, todoRepo *repository.TodoRepository, userRepo *repository.UserRepository, m mailer.Mailer, cfg config.DigestConfig, appURL string) *DigestService {
+	return &DigestService{
+		digestRepo: digestRepo,
+		todoRepo:   todoRepo,
+		userRepo:   userRepo,
+		mailer:     m,
+		cfg:        cfg,
+		appURL:     appURL,
+	}
+}
+
+func (s *DigestService) Get(ctx context.Context, userID uuid.UUID) (*models.DigestSchedule, error) {
+	schedule, err := s.digestRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, ErrDigestNotFound
+	}
+	return schedule, nil
+}
+
+// Update creates or replaces the user's schedule; the next run is the first
+// matching send time from now on.
+func (s *DigestService) Update(ctx context.Context, req models.UpdateDigestRequest, userID uuid.UUID) (*models.DigestSchedule, error) {
+	schedule := &models.DigestSchedule{
+		UserID:    userID,
+		Frequency: req.Frequency,
+		SendTime:  req.SendTime,
+		Timezone:  req.Timezone,
+		Enabled:   true,
+	}
+	if req.Frequency == models.DigestWeekly {
+		if req.Weekday == nil {
+			return nil, ErrInvalidDigestSchedule
+		}
+		schedule.Weekday = req.Weekday
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+
+	next, err := nextDigestRun(schedule, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	schedule.NextRunAt = next
+
+	if err := s.digestRepo.Upsert(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *DigestService) Delete(ctx context.Context, userID uuid.UUID) error {
+	if err := s.digestRepo.Delete(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrDigestNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// SendNow emails the user's digest right away, even when nothing is due, so
+// the setup can be checked. The regular schedule is left untouched.
+func (s *DigestService) SendNow(ctx context.Context, userID uuid.UUID) error {
+	schedule, err := s.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	data, err := s.collect(ctx, schedule, user.Name, time.Now())
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, user.Email, data)
+}
+
+// Run sends due digests until ctx is cancelled.
+func (s *DigestService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sendDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *DigestService) sendDue(ctx context.Context) {
+	for {
+		recipients, err := s.digestRepo.ClaimDue(ctx, digestBatchSize, digestLease)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error().Err(err).Msg("Failed to claim digest schedules")
+			}
+			return
+		}
+
+		for _, recipient := range recipients {
+			s.deliver(ctx, recipient)
+		}
+
+		if len(recipients) < digestBatchSize {
+			return
+		}
+	}
+}
+
+// deliver sends one claimed digest. A failed send keeps the lease so the run
+// is retried after it expires, up to maxDigestAttempts; digests with nothing
+// to report are skipped.
+func (s *DigestService) deliver(ctx context.Context, recipient *models.DigestRecipient) {
+	schedule := recipient.Schedule
+	logger := log.With().Str("user_id", schedule.UserID.String()).Logger()
+
+	now := time.Now()
+	next, err := nextDigestRun(schedule, now)
+	if err != nil {
+		logger.Error().Err(err).Msg("Invalid digest schedule")
+		return
+	}
+
+	data, err := s.collect(ctx, schedule, recipient.Name, now)
+	if err == nil && !data.Empty() {
+		err = s.send(ctx, recipient.Email, data)
+	}
+	if err != nil {
+		if schedule.Attempts < maxDigestAttempts {
+			logger.Warn().Err(err).Int("attempt", schedule.Attempts).Msg("Failed to send digest, will retry")
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to send digest, skipping this run")
+		if err := s.digestRepo.Reschedule(ctx, schedule.UserID, next, nil); err != nil {
+			logger.Error().Err(err).Msg("Failed to reschedule digest")
+		}
+		return
+	}
+
+	var sentAt *time.Time
+	if !data.Empty() {
+		sentAt = &now
+	}
+	if err := s.digestRepo.Reschedule(ctx, schedule.UserID, next, sentAt); err != nil {
+		logger.Error().Err(err).Msg("Failed to reschedule digest")
+	}
+}
+
+// collect splits the user's pending todos into overdue, due today and
+// upcoming, using calendar days in the schedule's timezone.
+func (s *DigestService) collect(ctx context.Context, schedule *models.DigestSchedule, name string, now time.Time) (*digestData, error) {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	tomorrow := today.AddDate(0, 0, 1)
+	horizon := tomorrow.AddDate(0, 0, s.cfg.UpcomingDays)
+
+	// A digest covers the user's todos in every workspace
+	todos, err := s.todoRepo.GetPendingDueBefore(tenant.AllWorkspaces(ctx), schedule.UserID, horizon)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &digestData{
+		Name:         name,
+		Frequency:    schedule.Frequency,
+		Date:         local.Format("Monday, January 2"),
+		Overdue:      []digestItem{},
+		Today:        []digestItem{},
+		Upcoming:     []digestItem{},
+		UpcomingDays: s.cfg.UpcomingDays,
+		AppURL:       s.appURL,
+	}
+	for _, todo := range todos {
+		due := todo.DueDate.In(loc)
+		item := digestItem{
+			Title:    todo.Title,
+			Priority: todo.Priority,
+			Tags:     todo.Tags,
+		}
+		switch {
+		case due.Before(today):
+			item.Due = "due " + due.Format("Mon, Jan 2")
+			data.Overdue = append(data.Overdue, item)
+		case due.Before(tomorrow):
+			item.Due = "due " + due.Format("15:04")
+			data.Today = append(data.Today, item)
+		default:
+			item.Due = "due " + due.Format("Mon, Jan 2 15:04")
+			data.Upcoming = append(data.Upcoming, item)
+		}
+	}
+
+	data.Subject = fmt.Sprintf("Your Todogo digest: %d overdue, %d due today", len(data.Overdue), len(data.Today))
+	return data, nil
+}
+
+func (s *DigestService) send(ctx context.Context, to string, data *digestData) error {
+	var html, text bytes.Buffer
+	if err := digestHTML.Execute(&html, data); err != nil {
+		return err
+	}
+	if err := digestText.Execute(&text, data); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      to,
+		Subject: data.Subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	})
+}
+
+// nextDigestRun returns the first send time of the schedule strictly after
+// the given time, in UTC. A send time that a DST change skips resolves to an
+// adjacent instant, as time.Date does.
+func nextDigestRun(schedule *models.DigestSchedule, after time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	clock, err := time.Parse("15:04", strings.TrimSpace(schedule.SendTime))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	local := after.In(loc)
+	for day := 0; day <= 7; day++ {
+		candidate := time.Date(local.Year(), local.Month(), local.Day()+day, clock.Hour(), clock.Minute(), 0, 0, loc)
+		if !candidate.After(after) {
+			continue
+		}
+		if schedule.Frequency == models.DigestWeekly && schedule.Weekday != nil && int(candidate.Weekday()) != *schedule.Weekday {
+			continue
+		}
+		return candidate.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("no run time found for schedule")
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+func TestNextDigestRun(t *testing.T) {
+	monday, sunday := int(time.Monday), int(time.Sunday)
+	utc := func(value string) time.Time {
+		t.Helper()
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name      string
+		frequency models.DigestFrequency
+		weekday   *int
+		timezone  string
+		sendTime  string
+		after     string
+		want      string
+	}{
+		{
+			name:      "later the same day",
+			frequency: models.DigestDaily, timezone: "Europe/Berlin", sendTime: "08:00",
+			after: "2024-01-15T05:00:00Z", want: "2024-01-15T07:00:00Z",
+		},
+		{
+			name:      "send time itself is not after",
+			frequency: models.DigestDaily, timezone: "Europe/Berlin", sendTime: "08:00",
+			after: "2024-01-15T07:00:00Z", want: "2024-01-16T07:00:00Z",
+		},
+		{
+			name:      "zone without DST",
+			frequency: models.DigestDaily, timezone: "Asia/Kolkata", sendTime: "09:00",
+			after: "2024-06-01T04:00:00Z", want: "2024-06-02T03:30:00Z",
+		},
+		{
+			name:      "local date ahead of UTC",
+			frequency: models.DigestDaily, timezone: "Pacific/Auckland", sendTime: "07:00",
+			after: "2024-01-15T17:00:00Z", want: "2024-01-15T18:00:00Z",
+		},
+		{
+			name:      "spring forward keeps the local time",
+			frequency: models.DigestDaily, timezone: "Europe/Berlin", sendTime: "08:00",
+			after: "2024-03-30T07:00:00Z", want: "2024-03-31T06:00:00Z",
+		},
+		{
+			name:      "fall back keeps the local time",
+			frequency: models.DigestDaily, timezone: "Europe/Berlin", sendTime: "08:00",
+			after: "2024-10-26T06:00:00Z", want: "2024-10-27T07:00:00Z",
+		},
+		{
+			name:      "send time skipped by spring forward",
+			frequency: models.DigestDaily, timezone: "Europe/Berlin", sendTime: "02:30",
+			after: "2024-03-30T12:00:00Z", want: "2024-03-31T01:30:00Z",
+		},
+		{
+			name:      "send time repeated by fall back",
+			frequency: models.DigestDaily, timezone: "Europe/Berlin", sendTime: "02:30",
+			after: "2024-10-26T12:00:00Z", want: "2024-10-27T01:30:00Z",
+		},
+		{
+			name:      "repeated send time runs once",
+			frequency: models.DigestDaily, timezone: "Europe/Berlin", sendTime: "02:30",
+			after: "2024-10-27T01:30:00Z", want: "2024-10-28T01:30:00Z",
+		},
+		{
+			name:      "weekly across spring forward",
+			frequency: models.DigestWeekly, weekday: &monday, timezone: "America/New_York", sendTime: "09:00",
+			after: "2024-03-05T15:00:00Z", want: "2024-03-11T13:00:00Z",
+		},
+		{
+			name:      "weekly on the day after the send time",
+			frequency: models.DigestWeekly, weekday: &sunday, timezone: "America/New_York", sendTime: "09:00",
+			after: "2024-11-03T15:00:00Z", want: "2024-11-10T14:00:00Z",
+		},
+		{
+			name:      "weekly on the day of fall back",
+			frequency: models.DigestWeekly, weekday: &sunday, timezone: "America/New_York", sendTime: "09:00",
+			after: "2024-11-02T12:00:00Z", want: "2024-11-03T14:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &models.DigestSchedule{
+				Frequency: tt.frequency,
+				Weekday:   tt.weekday,
+				Timezone:  tt.timezone,
+				SendTime:  tt.sendTime,
+			}
+			got, err := nextDigestRun(schedule, utc(tt.after))
+			if err != nil {
+				t.Fatalf("nextDigestRun: %v", err)
+			}
+			if want := utc(tt.want); !got.Equal(want) || got.Location() != time.UTC {
+				t.Errorf("nextDigestRun after %s = %s, want %s", tt.after, got, want)
+			}
+		})
+	}
+}
+
+func TestNextDigestRunInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		sendTime string
+	}{
+		{"unknown timezone", "Mars/Olympus_Mons", "08:00"},
+		{"malformed send time", "UTC", "8 am"},
+		{"send time out of range", "UTC", "24:00"},
+	}
+
+	for _, tt := range tests {
+		schedule := &models.DigestSchedule{Frequency: models.DigestDaily, Timezone: tt.timezone, SendTime: tt.sendTime}
+		if _, err := nextDigestRun(schedule, time.Now()); err == nil {
+			t.Errorf("%s: nextDigestRun succeeded, want an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"net/url"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/config"
+	"github.com/yourusername/todogo-backend/internal/mailer"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+)
+
+// verificationSendTimeout bounds the background work of sending a
+// verification email.
+const verificationSendTimeout = time.Minute
+
+var ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+
+//go:embed templates/verify_email.html templates/verify_email.txt
+var verifyEmailTemplates embed.FS
+
+var (
+	verifyEmailHTML = htmltemplate.Must(htmltemplate.ParseFS(verifyEmailTemplates, "templates/verify_email.html"))
+	verifyEmailText = texttemplate.Must(texttemplate.ParseFS(verifyEmailTemplates, "templates/verify_email.txt"))
+)
+
+type verifyEmailData struct {
+	Subject   string
+	Name      string
+	Email     string
+	VerifyURL string
+	Expires   string
+}
+
+// EmailVerificationService confirms that users own their email address by
+// sending them a link.
+type EmailVerificationService struct {
+	userRepo         *repository.UserRepository
+	verificationRepo *repository.EmailVerificationRepository
+	sessions         *SessionCache
+	policy           *PolicyService
+	mailer           mailer.Mailer
+	cfg              config.AuthConfig
+	appURL           string
+}
+
+func NewEmailVerificationService(userRepo *repository.UserRepository, verificationRepo *repository.EmailVerificationRepository, sessions *SessionCache, policy *PolicyService, m mailer.Mailer, cfg config.AuthConfig, appURL string) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+		sessions:         sessions,
+		policy:           policy,
+		mailer:           m,
+		cfg:              cfg,
+		appURL:           strings.TrimRight(appURL, "/"),
+	}
+}
+
+// Required reports whether users must verify their address before they can
+// sign in.
+func (s *EmailVerificationService) Required() bool {
+	return s.cfg.RequireVerifiedEmail
+}
+
+// Send emails a verification link to the user's current address in the
+// background. Failures are logged.
+func (s *EmailVerificationService) Send(ctx context.Context, user *models.User) {
+	userID, name, email := user.ID, user.Name, user.Email
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), verificationSendTimeout)
+		defer cancel()
+
+		if err := s.send(ctx, userID, name, email); err != nil {
+			log.Error().Err(err).Str("user_id", userID.String()).Msg("Failed to send verification email")
+		}
+	}()
+}
+
+// Resend emails a new verification link to the unverified account with the
+// email, unless the account was sent one within the resend interval or
+// reached the daily limit. Like Send it works in the background, so that the
+// response does not tell whether the account exists.
+func (s *EmailVerificationService) Resend(ctx context.Context, email string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), verificationSendTimeout)
+		defer cancel()
+
+		if err := s.resend(ctx, email); err != nil {
+			log.Error().Err(err).Msg("Failed to resend verification email")
+		}
+	}()
+}
+
+func (s *EmailVerificationService) resend(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.DisabledAt != nil || user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	count, last, err := s.verificationRepo.CountSince(ctx, user.ID, now.Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+	if count >= s.cfg.VerificationDailyLimit || (last != nil && now.Sub(*last) < s.cfg.VerificationResendInterval) {
+		log.Info().Str("user_id", user.ID.String()).Msg("Verification email not resent, rate limit reached")
+		return nil
+	}
+
+	return s.send(ctx, user.ID, user.Name, user.Email)
+}
+
+func (s *EmailVerificationService) send(ctx context.Context, userID uuid.UUID, name, email string) error {
+	secret, err := generateSecret()
+	if err != nil {
+		return err
+	}
+	token := &models.EmailVerificationToken{
+		UserID:    userID,
+		Email:     email,
+		TokenHash: hashSecret(secret),
+		ExpiresAt: time.Now().Add(s.cfg.VerificationExpiration),
+	}
+	if err := s.verificationRepo.Create(ctx, token); err != nil {
+		return err
+	}
+
+	data := verifyEmailData{
+		Subject:   "Verify your email address for Todogo",
+		Name:      name,
+		Email:     email,
+		VerifyURL: s.appURL + "/verify-email?token=" + url.QueryEscape(secret),
+		Expires:   token.ExpiresAt.Format("Mon, Jan 2 2006 15:04 MST"),
+	}
+
+	var html, text bytes.Buffer
+	if err := verifyEmailHTML.Execute(&html, data); err != nil {
+		return err
+	}
+	if err := verifyEmailText.Execute(&text, data); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: data.Subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	})
+}
+
+// Verify marks the address the token was sent to as verified, provided it is
+// still the user's address.
+func (s *EmailVerificationService) Verify(ctx context.Context, token string) (*models.User, error) {
+	t, err := s.verificationRepo.Consume(ctx, hashSecret(token))
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, t.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !strings.EqualFold(user.Email, t.Email) {
+		return nil, ErrInvalidVerificationToken
+	}
+	if user.EmailVerifiedAt != nil {
+		return user, nil
+	}
+
+	now := time.Now().UTC()
+	user.EmailVerifiedAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.verificationRepo.DeleteForUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	if err := s.policy.PromoteVerified(ctx, user); err != nil {
+		return nil, err
+	}
+	s.sessions.Forget(user.ID)
+
+	return user, nil
+}
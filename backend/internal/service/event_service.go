@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+	"github.com/yourusername/todogo-backend/internal/tenant"
+)
+
+const (
+	// eventRetention is how far back a reconnecting client can resume.
+	eventRetention = 24 * time.Hour
+	eventBacklog   = 500
+	// subscriberBuffer bounds how far a slow stream may fall behind before it
+	// is closed; the client then resumes from the event log.
+	subscriberBuffer = 64
+)
+
+// EventService fans todo events out to live streams. Events are written to
+// the todo_events table, and a Postgres NOTIFY tells every instance to
+// forward them to its local subscribers.
+type EventService struct {
+	eventRepo *repository.EventRepository
+
+	mu   sync.RWMutex
+	subs map[uuid.UUID]map[*Subscription]struct{}
+}
+
+// Subscription receives the events of one user in one workspace. Events is
+// closed when the subscriber has to resume from the log, e.g. after falling
+// behind.
+type Subscription struct {
+	UserID      uuid.UUID
+	WorkspaceID uuid.UUID
+	Events      chan *models.StoredEvent
+	closed      bool
+	all         bool
+}
+
+func (sub *Subscription) wants(event *models.StoredEvent) bool {
+	return sub.all || event.WorkspaceID == sub.WorkspaceID
+}
+
+func NewEventService(eventRepo *repository.EventRepository) *EventService {
+	return &EventService{
+		eventRepo: eventRepo,
+		subs:      make(map[uuid.UUID]map[*Subscription]struct{}),
+	}
+}
+
+// OnTodoEvent persists the event and announces it to all instances.
+func (s *EventService) OnTodoEvent(ctx context.Context, event TodoEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode todo event")
+		return
+	}
+
+	stored := &models.StoredEvent{
+		UserID:    event.UserID,
+		EventType: string(event.Type),
+		Payload:   payload,
+	}
+	if event.Todo != nil {
+		stored.WorkspaceID = event.Todo.WorkspaceID
+	}
+	if err := s.eventRepo.Create(context.WithoutCancel(ctx), stored); err != nil {
+		log.Error().Err(err).Str("event", string(event.Type)).Msg("Failed to store todo event")
+	}
+}
+
+// Subscribe streams the user's events in the workspace of ctx. Without a
+// workspace nothing is delivered.
+func (s *EventService) Subscribe(ctx context.Context, userID uuid.UUID) *Subscription {
+	workspaceID, _ := tenant.WorkspaceID(ctx)
+	sub := &Subscription{
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		Events:      make(chan *models.StoredEvent, subscriberBuffer),
+		all:         tenant.IsAllWorkspaces(ctx),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subs[userID] == nil {
+		s.subs[userID] = make(map[*Subscription]struct{})
+	}
+	s.subs[userID][sub] = struct{}{}
+
+	return sub
+}
+
+func (s *EventService) Unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeLocked(sub)
+}
+
+func (s *EventService) closeLocked(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.Events)
+	delete(s.subs[sub.UserID], sub)
+	if len(s.subs[sub.UserID]) == 0 {
+		delete(s.subs, sub.UserID)
+	}
+}
+
+// Since returns stored events of the user in the workspace of ctx after the
+// given event ID.
+func (s *EventService) Since(ctx context.Context, userID uuid.UUID, afterID int64) ([]*models.StoredEvent, error) {
+	return s.eventRepo.GetSince(ctx, userID, afterID, eventBacklog)
+}
+
+// Run listens for event notifications and prunes the event log until ctx is
+// cancelled.
+func (s *EventService) Run(ctx context.Context) {
+	go s.prune(ctx)
+
+	for {
+		if err := s.eventRepo.Listen(ctx, s.dispatch); err != nil {
+			log.Error().Err(err).Msg("Todo event listener failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			s.closeAll()
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (s *EventService) dispatch(n repository.EventNotification) {
+	if n.ID == 0 {
+		// Notifications may have been lost while reconnecting; make every
+		// stream resume from the log.
+		s.closeAll()
+		return
+	}
+
+	s.mu.RLock()
+	interested := len(s.subs[n.UserID]) > 0
+	s.mu.RUnlock()
+	if !interested {
+		return
+	}
+
+	event, err := s.eventRepo.GetByID(context.Background(), n.ID)
+	if err != nil || event == nil {
+		log.Error().Err(err).Int64("event_id", n.ID).Msg("Failed to load todo event")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subs[n.UserID] {
+		if !sub.wants(event) {
+			continue
+		}
+		select {
+		case sub.Events <- event:
+		default:
+			s.closeLocked(sub)
+		}
+	}
+}
+
+func (s *EventService) closeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, subs := range s.subs {
+		for sub := range subs {
+			s.closeLocked(sub)
+		}
+	}
+}
+
+func (s *EventService) prune(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.eventRepo.DeleteOlderThan(ctx, time.Now().Add(-eventRetention)); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to prune todo events")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
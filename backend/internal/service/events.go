@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+type TodoEventType string
+
+const (
+	EventTodoCreated   TodoEventType = "todo.created"
+	EventTodoUpdated   TodoEventType = "todo.updated"
+	EventTodoCompleted TodoEventType = "todo.completed"
+	EventTodoDeleted   TodoEventType = "todo.deleted"
+	EventTodoAssigned  TodoEventType = "todo.assigned"
+)
+
+// TodoEvent describes a change made through TodoService. For deletions Todo
+// holds the state just before the todo was removed; for assignments
+// PreviousAssigneeID is who the todo was assigned to before, if anyone.
+type TodoEvent struct {
+	ID                 uuid.UUID     `json:"id"`
+	Type               TodoEventType `json:"type"`
+	UserID             uuid.UUID     `json:"user_id"`
+	Todo               *models.Todo  `json:"todo"`
+	PreviousAssigneeID *uuid.UUID    `json:"previous_assignee_id,omitempty"`
+	OccurredAt         time.Time     `json:"occurred_at"`
+}
+
+// TodoEventListener is notified after a todo change has been committed.
+// Listeners run synchronously on the request path and must not block.
+type TodoEventListener interface {
+	OnTodoEvent(ctx context.Context, event TodoEvent)
+}
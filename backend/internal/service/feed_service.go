@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+	"github.com/yourusername/todogo-backend/internal/tenant"
+)
+
+var (
+	ErrFeedNotFound = errors.New("feed not found")
+)
+
+type FeedService struct {
+	feedRepo      *repository.FeedRepository
+	todoRepo      *repository.TodoRepository
+	userRepo      *repository.UserRepository
+	workspaceRepo *repository.WorkspaceRepository
+	publicURL     string
+}
+
+func NewFeedService(feedRepo *repository.FeedRepository, todoRepo *repository.TodoRepository, userRepo *repository.UserRepository, workspaceRepo *repository.WorkspaceRepository, publicURL string) *FeedService {
+	return &FeedService{
+		feedRepo:      feedRepo,
+		todoRepo:      todoRepo,
+		userRepo:      userRepo,
+		workspaceRepo: workspaceRepo,
+		publicURL:     strings.TrimRight(publicURL, "/"),
+	}
+}
+
+func (s *FeedService) Create(ctx context.Context, req models.CreateFeedRequest, userID uuid.UUID) (*models.CalendarFeed, error) {
+	token, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &models.CalendarFeed{
+		UserID:    userID,
+		Name:      req.Name,
+		Tag:       req.Tag,
+		TokenHash: hashSecret(token),
+	}
+
+	if err := s.feedRepo.Create(ctx, feed); err != nil {
+		return nil, err
+	}
+
+	feed.URL = s.feedURL(token)
+	return feed, nil
+}
+
+func (s *FeedService) GetAll(ctx context.Context, userID uuid.UUID) ([]*models.CalendarFeed, error) {
+	return s.feedRepo.GetAll(ctx, userID)
+}
+
+// Regenerate replaces the feed token. The previous URL stops working as soon
+// as the new hash is stored.
+func (s *FeedService) Regenerate(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.CalendarFeed, error) {
+	feed, err := s.feedRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil {
+		return nil, ErrFeedNotFound
+	}
+
+	token, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.feedRepo.UpdateTokenHash(ctx, id, userID, hashSecret(token)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFeedNotFound
+		}
+		return nil, err
+	}
+
+	feed.UpdatedAt = time.Now()
+	feed.URL = s.feedURL(token)
+	return feed, nil
+}
+
+func (s *FeedService) Revoke(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	if err := s.feedRepo.Delete(ctx, id, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrFeedNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Render resolves a feed token and renders the owner's current todos. Feeds
+// stop working while the owner is disabled or once they leave the workspace.
+func (s *FeedService) Render(ctx context.Context, token string) ([]byte, error) {
+	feed, err := s.feedRepo.GetByTokenHash(ctx, hashSecret(token))
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil {
+		return nil, ErrFeedNotFound
+	}
+
+	owner, err := s.userRepo.GetByID(ctx, feed.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil || owner.DisabledAt != nil {
+		return nil, ErrFeedNotFound
+	}
+	member, err := s.workspaceRepo.IsMember(ctx, feed.WorkspaceID, feed.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, ErrFeedNotFound
+	}
+
+	// Feeds show the workspace they were created in
+	ctx = tenant.WithWorkspace(ctx, feed.WorkspaceID)
+
+	filters := models.TodoFilters{}
+	if feed.Tag != nil {
+		filters.Tags = []string{*feed.Tag}
+	}
+
+	todos, err := s.todoRepo.GetAll(ctx, feed.UserID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return EncodeCalendar(todos, feed.Name)
+}
+
+func (s *FeedService) feedURL(token string) string {
+	return s.publicURL + "/api/v1/feeds/ical/" + token + ".ics"
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+)
+
+var ErrInboxItemNotFound = errors.New("inbox item not found")
+
+// InboxService keeps each user's assignment notifications.
+type InboxService struct {
+	inboxRepo *repository.InboxRepository
+}
+
+func NewInboxService(inboxRepo *repository.InboxRepository) *InboxService {
+	return &InboxService{
+		inboxRepo: inboxRepo,
+	}
+}
+
+// OnTodoEvent notifies the new and the previous assignee of an assignment.
+func (s *InboxService) OnTodoEvent(ctx context.Context, event TodoEvent) {
+	if event.Type != EventTodoAssigned {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	if event.Todo.AssigneeID != nil {
+		s.notify(ctx, *event.Todo.AssigneeID, models.InboxAssigned, event)
+	}
+	if event.PreviousAssigneeID != nil {
+		s.notify(ctx, *event.PreviousAssigneeID, models.InboxUnassigned, event)
+	}
+}
+
+func (s *InboxService) notify(ctx context.Context, userID uuid.UUID, itemType models.InboxItemType, event TodoEvent) {
+	item := &models.InboxItem{
+		UserID:  userID,
+		Type:    itemType,
+		TodoID:  event.Todo.ID,
+		ActorID: &event.UserID,
+	}
+	if err := s.inboxRepo.Create(ctx, item); err != nil {
+		log.Error().Err(err).Str("todo_id", event.Todo.ID.String()).Msg("Failed to create inbox item")
+	}
+}
+
+func (s *InboxService) GetAll(ctx context.Context, userID uuid.UUID, unreadOnly bool) (*models.InboxResult, error) {
+	items, err := s.inboxRepo.GetAll(ctx, userID, unreadOnly)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.inboxRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.InboxResult{Unread: unread, Items: items}, nil
+}
+
+func (s *InboxService) MarkRead(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	if err := s.inboxRepo.MarkRead(ctx, id, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInboxItemNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *InboxService) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.inboxRepo.MarkAllRead(ctx, userID)
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/config"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+	"github.com/yourusername/todogo-backend/pkg/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// recoveryCodeCount is how many recovery codes are generated at a time.
+	recoveryCodeCount = 10
+	// totpSkew accepts codes one step either side of the server clock.
+	totpSkew = 1
+	// maxMFAAttempts is how many codes a sign-in challenge accepts, and how
+	// many wrong codes in a row lock the user out of two-factor checks.
+	maxMFAAttempts = 5
+	// mfaLockout is how long a lockout lasts. Each wrong code after it
+	// starts another one until a correct code is entered.
+	mfaLockout = 15 * time.Minute
+)
+
+var (
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnrolled    = errors.New("start two-factor enrollment first")
+	ErrTOTPNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrInvalidMFACode     = errors.New("invalid two-factor code")
+	ErrInvalidMFAToken    = errors.New("invalid or expired two-factor challenge, sign in again")
+	ErrMFALocked          = errors.New("too many wrong two-factor codes, try again later")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFAService manages TOTP two-factor authentication: enrollment, recovery
+// codes and the challenges sign-ins pass through when it is enabled.
+type MFAService struct {
+	userRepo *repository.UserRepository
+	mfaRepo  *repository.MFARepository
+	policy   *PolicyService
+	issuer   string
+	expiry   time.Duration
+}
+
+func NewMFAService(userRepo *repository.UserRepository, mfaRepo *repository.MFARepository, policy *PolicyService, cfg config.AuthConfig) *MFAService {
+	return &MFAService{
+		userRepo: userRepo,
+		mfaRepo:  mfaRepo,
+		policy:   policy,
+		issuer:   cfg.TOTPIssuer,
+		expiry:   cfg.MFAChallengeExpiration,
+	}
+}
+
+// Enroll generates a new TOTP secret for the user. It is enforced once
+// Confirm checks a code from it.
+func (s *MFAService) Enroll(ctx context.Context, userID uuid.UUID) (*models.TOTPEnrollment, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.SetPendingTOTP(ctx, userID, secret); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTOTPAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	return &models.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables TOTP with a code from the enrolled secret and returns the
+// user's recovery codes.
+func (s *MFAService) Confirm(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodes, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := s.mfaRepo.GetTOTPSecret(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		return nil, ErrTOTPNotEnrolled
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.EnableTOTP(ctx, userID, step, hashes); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTOTPNotEnrolled
+		}
+		return nil, err
+	}
+
+	s.policy.audit(ctx, userID, models.AuditMFAEnabled, "user:"+userID.String(), nil)
+	return &models.RecoveryCodes{Codes: codes}, nil
+}
+
+// Disable turns TOTP off after checking the password and a TOTP or recovery
+// code.
+func (s *MFAService) Disable(ctx context.Context, userID uuid.UUID, req models.DisableTOTPRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return errors.New("invalid credentials")
+	}
+	if user.TOTPEnabledAt == nil {
+		return ErrTOTPNotEnabled
+	}
+
+	if err := s.checkCode(ctx, userID, req.Code, true); err != nil {
+		return err
+	}
+	if err := s.mfaRepo.DisableTOTP(ctx, userID); err != nil {
+		return err
+	}
+
+	s.policy.audit(ctx, userID, models.AuditMFADisabled, "user:"+userID.String(), nil)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking
+// a TOTP code.
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodes, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.TOTPEnabledAt == nil {
+		return nil, ErrTOTPNotEnabled
+	}
+
+	if err := s.checkCode(ctx, userID, code, false); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return &models.RecoveryCodes{Codes: codes}, nil
+}
+
+// Challenge starts a sign-in that needs a second factor and returns the
+// token to complete it with.
+func (s *MFAService) Challenge(ctx context.Context, userID uuid.UUID) (string, time.Time, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	challenge := &models.MFAChallenge{
+		UserID:    userID,
+		TokenHash: hashSecret(secret),
+		ExpiresAt: time.Now().Add(s.expiry),
+	}
+	if err := s.mfaRepo.CreateChallenge(ctx, challenge); err != nil {
+		return "", time.Time{}, err
+	}
+	return secret, challenge.ExpiresAt, nil
+}
+
+// Redeem completes a challenge with a TOTP or recovery code and returns the
+// user who signed in. A challenge accepts maxMFAAttempts codes, counted
+// before each code is checked.
+func (s *MFAService) Redeem(ctx context.Context, token, code string) (*models.User, error) {
+	challenge, err := s.mfaRepo.ReserveChallengeAttempt(ctx, hashSecret(token), maxMFAAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	if err := s.checkCode(ctx, challenge.UserID, code, true); err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.mfaRepo.DeleteChallenge(ctx, challenge.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidMFAToken
+	}
+	return user, nil
+}
+
+// checkCode accepts a TOTP code that was not used before or, when
+// allowRecovery is set, an unused recovery code, which is spent. Every code
+// counts against the user's lockout until one is accepted.
+func (s *MFAService) checkCode(ctx context.Context, userID uuid.UUID, code string, allowRecovery bool) error {
+	secret, err := s.mfaRepo.GetTOTPSecret(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		return ErrTOTPNotEnabled
+	}
+
+	reserved, err := s.mfaRepo.ReserveUserAttempt(ctx, userID, maxMFAAttempts, time.Now().Add(mfaLockout))
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		return ErrMFALocked
+	}
+
+	ok, err := s.matchCode(ctx, userID, secret, code, allowRecovery)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	return s.mfaRepo.ClearUserAttempts(ctx, userID)
+}
+
+// matchCode reports whether the code is a fresh TOTP code for the secret
+// or, when allowRecovery is set, an unused recovery code, spending it.
+func (s *MFAService) matchCode(ctx context.Context, userID uuid.UUID, secret, code string, allowRecovery bool) (bool, error) {
+	if step, ok := totp.Validate(secret, code, time.Now(), totpSkew); ok {
+		return s.mfaRepo.UseTOTPStep(ctx, userID, step)
+	}
+
+	if allowRecovery {
+		return s.mfaRepo.UseRecoveryCode(ctx, userID, hashSecret(normalizeRecoveryCode(code)))
+	}
+	return false, nil
+}
+
+// generateRecoveryCodes returns new recovery codes, formatted for reading
+// as xxxxx-xxxxx, and the hashes to store.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashSecret(raw)
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode ignores case, dashes and spaces in a typed code.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/config"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/oidc"
+	"github.com/yourusername/todogo-backend/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// OIDCStateExpiry is how long the user has to sign in at the provider.
+const OIDCStateExpiry = 10 * time.Minute
+
+var (
+	ErrSSODisabled      = errors.New("single sign-on is not configured")
+	ErrInvalidOIDCState = errors.New("invalid or expired sign-in, start again")
+	ErrSSOFailed        = errors.New("single sign-on failed")
+	ErrSSOUnavailable   = errors.New("single sign-on provider is unavailable")
+	ErrSSONoEmail       = errors.New("the provider did not share an email address")
+	ErrSSOLinkRefused   = errors.New("an account with this email address exists but cannot be linked, sign in with your password")
+	ErrSSOSignupClosed  = errors.New("no account is linked to this sign-in and sign-up through single sign-on is disabled")
+)
+
+// OIDCService signs users in through an OpenID Connect provider. Users are
+// found by their linked provider account, linked by verified email address or
+// created on first sign-in.
+type OIDCService struct {
+	provider      *oidc.Provider
+	oidcRepo      *repository.OIDCRepository
+	userRepo      *repository.UserRepository
+	authService   *AuthService
+	verifier      *EmailVerificationService
+	policy        *PolicyService
+	autoProvision bool
+}
+
+func NewOIDCService(oidcRepo *repository.OIDCRepository, userRepo *repository.UserRepository, authService *AuthService, verifier *EmailVerificationService, policy *PolicyService, cfg config.OIDCConfig) *OIDCService {
+	s := &OIDCService{
+		oidcRepo:      oidcRepo,
+		userRepo:      userRepo,
+		authService:   authService,
+		verifier:      verifier,
+		policy:        policy,
+		autoProvision: cfg.AutoProvision,
+	}
+	if cfg.Enabled() {
+		s.provider = oidc.NewProvider(cfg)
+	}
+	return s
+}
+
+// Authorize starts a sign-in and returns the provider URL to send the user
+// to. The state, nonce and PKCE verifier stay on the server until Callback.
+func (s *OIDCService) Authorize(ctx context.Context) (*models.OIDCAuthorization, error) {
+	if s.provider == nil {
+		return nil, ErrSSODisabled
+	}
+
+	state, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, oidc.Challenge(verifier))
+	if err != nil {
+		log.Error().Err(err).Msg("OpenID provider discovery failed")
+		return nil, ErrSSOUnavailable
+	}
+
+	if err := s.oidcRepo.CreateState(ctx, &models.OIDCState{
+		StateHash:    hashSecret(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(OIDCStateExpiry),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCAuthorization{AuthorizationURL: authURL, State: state}, nil
+}
+
+// Callback completes a sign-in with the code and state the provider
+// redirected back with. boundState is the state Authorize bound to the
+// browser; it must match, so that nobody can complete their own sign-in in
+// someone else's browser. Each state works once.
+func (s *OIDCService) Callback(ctx context.Context, req models.OIDCCallbackRequest, boundState string) (*models.LoginResponse, error) {
+	if s.provider == nil {
+		return nil, ErrSSODisabled
+	}
+	if boundState == "" || subtle.ConstantTimeCompare([]byte(boundState), []byte(req.State)) != 1 {
+		return nil, ErrInvalidOIDCState
+	}
+
+	state, err := s.oidcRepo.ConsumeState(ctx, hashSecret(req.State))
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, ErrInvalidOIDCState
+	}
+
+	rawIDToken, err := s.provider.Exchange(ctx, req.Code, state.CodeVerifier)
+	if err != nil {
+		log.Warn().Err(err).Msg("OpenID code exchange failed")
+		return nil, ErrSSOFailed
+	}
+	claims, err := s.provider.VerifyIDToken(ctx, rawIDToken, state.Nonce)
+	if err != nil {
+		log.Warn().Err(err).Msg("OpenID ID token rejected")
+		return nil, ErrSSOFailed
+	}
+
+	user, err := s.resolveUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.authService.SignInExternal(ctx, user)
+}
+
+// resolveUser finds the user the provider account belongs to, linking or
+// creating one when it signs in for the first time.
+func (s *OIDCService) resolveUser(ctx context.Context, claims *oidc.Claims) (*models.User, error) {
+	issuer := s.provider.Issuer()
+
+	identity, err := s.oidcRepo.GetIdentity(ctx, issuer, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		user, err := s.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrSSOFailed
+		}
+		return user, nil
+	}
+
+	email := strings.TrimSpace(claims.Email)
+	if email == "" {
+		return nil, ErrSSONoEmail
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		// Both sides must have verified the address, otherwise whoever
+		// controls one of them could take over the other's account
+		if !claims.EmailVerified || user.EmailVerifiedAt == nil {
+			return nil, ErrSSOLinkRefused
+		}
+		if err := s.link(ctx, user, claims); err != nil {
+			return nil, err
+		}
+		s.policy.audit(ctx, user.ID, models.AuditSSOLinked, "user:"+user.ID.String(), map[string]string{
+			"issuer":  issuer,
+			"subject": claims.Subject,
+		})
+		return user, nil
+	}
+
+	if !s.autoProvision {
+		return nil, ErrSSOSignupClosed
+	}
+	return s.provision(ctx, email, claims)
+}
+
+// provision creates a user for the provider account. The account gets a
+// random password, so that it can only sign in through the provider until
+// the user resets it.
+func (s *OIDCService) provision(ctx context.Context, email string, claims *oidc.Claims) (*models.User, error) {
+	password, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Name:     displayName(claims, email),
+		Email:    email,
+		Password: string(hashedPassword),
+		Role:     s.policy.RoleForNewUser(email, claims.EmailVerified),
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if claims.EmailVerified {
+		now := time.Now().UTC()
+		user.EmailVerifiedAt = &now
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+	} else {
+		s.verifier.Send(ctx, user)
+	}
+
+	if err := s.link(ctx, user, claims); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *OIDCService) link(ctx context.Context, user *models.User, claims *oidc.Claims) error {
+	err := s.oidcRepo.CreateIdentity(ctx, &models.UserIdentity{
+		UserID:  user.ID,
+		Issuer:  s.provider.Issuer(),
+		Subject: claims.Subject,
+		Email:   claims.Email,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+	return nil
+}
+
+// displayName picks a name for a new user from the ID token, falling back to
+// the local part of their email address.
+func displayName(claims *oidc.Claims, email string) string {
+	for _, name := range []string{claims.Name, claims.PreferredUsername, strings.SplitN(email, "@", 2)[0]} {
+		runes := []rune(strings.TrimSpace(name))
+		if len(runes) >= 2 {
+			if len(runes) > 255 {
+				runes = runes[:255]
+			}
+			return string(runes)
+		}
+	}
+	return email
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"net/url"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/config"
+	"github.com/yourusername/todogo-backend/internal/mailer"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordResetSendTimeout bounds the background work of a reset request.
+const passwordResetSendTimeout = time.Minute
+
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+//go:embed templates/password_reset.html templates/password_reset.txt
+var passwordResetTemplates embed.FS
+
+var (
+	passwordResetHTML = htmltemplate.Must(htmltemplate.ParseFS(passwordResetTemplates, "templates/password_reset.html"))
+	passwordResetText = texttemplate.Must(texttemplate.ParseFS(passwordResetTemplates, "templates/password_reset.txt"))
+)
+
+type passwordResetData struct {
+	Subject  string
+	Name     string
+	ResetURL string
+	Expires  string
+}
+
+// PasswordResetService lets users who forgot their password set a new one
+// through a link sent to their email address.
+type PasswordResetService struct {
+	userRepo    *repository.UserRepository
+	resetRepo   *repository.PasswordResetRepository
+	authService *AuthService
+	mailer      mailer.Mailer
+	cfg         config.AuthConfig
+	appURL      string
+}
+
+func NewPasswordResetService(userRepo *repository.UserRepository, resetRepo *repository.PasswordResetRepository, authService *AuthService, m mailer.Mailer, cfg config.AuthConfig, appURL string) *PasswordResetService {
+	return &PasswordResetService{
+		userRepo:    userRepo,
+		resetRepo:   resetRepo,
+		authService: authService,
+		mailer:      m,
+		cfg:         cfg,
+		appURL:      strings.TrimRight(appURL, "/"),
+	}
+}
+
+// Request sends a reset link to the account with the email, if there is an
+// enabled one that was not sent one within the reset interval and has not
+// reached the daily limit. The work happens in the background and its outcome is only
+// logged, so that neither the response nor its timing tells whether the
+// account exists.
+func (s *PasswordResetService) Request(ctx context.Context, email string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), passwordResetSendTimeout)
+		defer cancel()
+
+		if err := s.send(ctx, email); err != nil {
+			log.Error().Err(err).Msg("Failed to send password reset email")
+		}
+	}()
+}
+
+func (s *PasswordResetService) send(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.DisabledAt != nil {
+		return nil
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return err
+	}
+	token := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashSecret(secret),
+		ExpiresAt: time.Now().Add(s.cfg.PasswordResetExpiration),
+	}
+	created, err := s.resetRepo.Create(ctx, token, s.cfg.PasswordResetDailyLimit, s.cfg.PasswordResetInterval)
+	if err != nil {
+		return err
+	}
+	if !created {
+		log.Info().Str("user_id", user.ID.String()).Msg("Password reset email not sent, rate limit reached")
+		return nil
+	}
+
+	data := passwordResetData{
+		Subject:  "Reset your Todogo password",
+		Name:     user.Name,
+		ResetURL: s.appURL + "/reset-password?token=" + url.QueryEscape(secret),
+		Expires:  token.ExpiresAt.Format("Mon, Jan 2 2006 15:04 MST"),
+	}
+
+	var html, text bytes.Buffer
+	if err := passwordResetHTML.Execute(&html, data); err != nil {
+		return err
+	}
+	if err := passwordResetText.Execute(&text, data); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: data.Subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	})
+}
+
+// Reset sets the new password with a token from a reset link and signs the
+// user out everywhere. The token cannot be used again.
+func (s *PasswordResetService) Reset(ctx context.Context, req models.ResetPasswordRequest) error {
+	token, err := s.resetRepo.Consume(ctx, hashSecret(req.Token))
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidResetToken
+	}
+	if user.DisabledAt != nil {
+		return ErrAccountDisabled
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	user.Password = string(hashedPassword)
+	user.PasswordResetRequired = false
+	user.PasswordChangedAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	if err := s.resetRepo.InvalidateForUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	return s.authService.LogoutEverywhere(ctx, user.ID)
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/config"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+)
+
+// auditLimit caps how many audit entries are listed.
+const auditLimit = 200
+
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrUserNotFound     = errors.New("user not found")
+)
+
+// PermissionError is returned when the caller's role lacks a permission. It
+// matches ErrPermissionDenied.
+type PermissionError struct {
+	Permission models.Permission
+}
+
+func (e *PermissionError) Error() string {
+	return "permission denied: " + string(e.Permission)
+}
+
+func (e *PermissionError) Is(target error) bool {
+	return target == ErrPermissionDenied
+}
+
+// roles lists every role with its permissions, most privileged first.
+var roles = []models.RoleInfo{
+	{Role: models.RoleAdmin, Permissions: []models.Permission{
+		models.PermTodoRead, models.PermTodoCreate, models.PermTodoUpdate, models.PermTodoDelete, models.PermTodoAssign,
+		models.PermTagManage, models.PermFeedManage, models.PermWebhookManage, models.PermWorkspaceManage,
+		models.PermUserManage, models.PermAuditRead,
+	}},
+	{Role: models.RoleMember, Permissions: []models.Permission{
+		models.PermTodoRead, models.PermTodoCreate, models.PermTodoUpdate, models.PermTodoDelete, models.PermTodoAssign,
+		models.PermTagManage, models.PermFeedManage, models.PermWebhookManage, models.PermWorkspaceManage,
+	}},
+	{Role: models.RoleGuest, Permissions: []models.Permission{
+		models.PermTodoRead, models.PermTodoCreate, models.PermTodoUpdate,
+	}},
+}
+
+// Can reports whether the role grants the permission. Unknown roles grant
+// nothing.
+func Can(role models.Role, perm models.Permission) bool {
+	for _, info := range roles {
+		if info.Role != role {
+			continue
+		}
+		for _, p := range info.Permissions {
+			if p == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PolicyService decides what users may do from the role stored for them and
+// audits what they were denied.
+type PolicyService struct {
+	userRepo  *repository.UserRepository
+	auditRepo *repository.AuditRepository
+	cfg       config.RBACConfig
+}
+
+func NewPolicyService(userRepo *repository.UserRepository, auditRepo *repository.AuditRepository, cfg config.RBACConfig) *PolicyService {
+	return &PolicyService{
+		userRepo:  userRepo,
+		auditRepo: auditRepo,
+		cfg:       cfg,
+	}
+}
+
+// Bootstrap makes the users with a configured admin email admins, so that a
+// fresh deployment has someone who can assign roles. Only verified addresses
+// count; otherwise anyone registering the address first would become admin.
+func (s *PolicyService) Bootstrap(ctx context.Context) error {
+	if len(s.cfg.AdminEmails) == 0 {
+		return nil
+	}
+	emails := make([]string, len(s.cfg.AdminEmails))
+	for i, email := range s.cfg.AdminEmails {
+		emails[i] = strings.ToLower(email)
+	}
+	return s.userRepo.PromoteAdmins(ctx, emails)
+}
+
+// RoleForNewUser returns the role a user registering with the email gets.
+// Admin emails only make admins once the address is verified.
+func (s *PolicyService) RoleForNewUser(email string, verified bool) models.Role {
+	if verified && s.isAdminEmail(email) {
+		return models.RoleAdmin
+	}
+	if models.Role(s.cfg.DefaultRole) == models.RoleGuest {
+		return models.RoleGuest
+	}
+	return models.RoleMember
+}
+
+// PromoteVerified makes the user an admin if they just verified a
+// configured admin email.
+func (s *PolicyService) PromoteVerified(ctx context.Context, user *models.User) error {
+	if user.EmailVerifiedAt == nil || user.Role == models.RoleAdmin || !s.isAdminEmail(user.Email) {
+		return nil
+	}
+	if _, err := s.userRepo.SetRole(ctx, user.ID, models.RoleAdmin); err != nil {
+		return err
+	}
+	user.Role = models.RoleAdmin
+	return nil
+}
+
+func (s *PolicyService) isAdminEmail(email string) bool {
+	for _, admin := range s.cfg.AdminEmails {
+		if strings.EqualFold(admin, email) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *PolicyService) Roles() []models.RoleInfo {
+	return roles
+}
+
+// Authorize returns a *PermissionError when the user's role lacks the
+// permission, and records the denial in the audit log. target names what
+// was attempted.
+func (s *PolicyService) Authorize(ctx context.Context, userID uuid.UUID, perm models.Permission, target string) error {
+	role, err := s.userRepo.GetRole(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if Can(role, perm) {
+		return nil
+	}
+
+	log.Warn().
+		Str("user_id", userID.String()).
+		Str("role", string(role)).
+		Str("permission", string(perm)).
+		Str("target", target).
+		Msg("Permission denied")
+	s.audit(ctx, userID, models.AuditAccessDenied, target, map[string]string{
+		"permission": string(perm),
+		"role":       string(role),
+	})
+
+	return &PermissionError{Permission: perm}
+}
+
+// Allows reports whether the user's role has the permission. Unlike
+// Authorize it records nothing, for choosing defaults rather than refusing.
+func (s *PolicyService) Allows(ctx context.Context, userID uuid.UUID, perm models.Permission) (bool, error) {
+	role, err := s.userRepo.GetRole(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return Can(role, perm), nil
+}
+
+// SetRole assigns a role to a user on behalf of an admin.
+func (s *PolicyService) SetRole(ctx context.Context, id uuid.UUID, role models.Role, actorID uuid.UUID) (*models.User, error) {
+	previous, err := s.userRepo.SetRole(ctx, id, role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if previous != role {
+		s.audit(ctx, actorID, models.AuditRoleChanged, "user:"+id.String(), map[string]string{
+			"from": string(previous),
+			"to":   string(role),
+		})
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// Audit returns the newest audit entries, optionally of one action.
+func (s *PolicyService) Audit(ctx context.Context, action *models.AuditAction) ([]*models.AuditEntry, error) {
+	return s.auditRepo.GetRecent(ctx, action, auditLimit)
+}
+
+// audit records an entry. A failure is logged and otherwise ignored, so that
+// it never changes the outcome of the request.
+func (s *PolicyService) audit(ctx context.Context, actorID uuid.UUID, action models.AuditAction, target string, details map[string]string) {
+	if details == nil {
+		details = map[string]string{}
+	}
+	raw, err := json.Marshal(details)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to encode audit details")
+		return
+	}
+
+	entry := &models.AuditEntry{
+		ActorID:   &actorID,
+		Action:    action,
+		Target:    target,
+		Details:   raw,
+		RequestID: chimw.GetReqID(ctx),
+	}
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		log.Warn().Err(err).Str("action", string(action)).Msg("Failed to write audit entry")
+	}
+}
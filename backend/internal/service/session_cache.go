@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+)
+
+// SessionCache keeps what ValidateSession checks on every request in memory
+// for a while: the account state of users and whether token IDs were
+// revoked. Changes made through this instance take effect at once; other
+// instances see them once their entries are older than the TTL.
+type SessionCache struct {
+	userRepo    *repository.UserRepository
+	revokedRepo *repository.RevokedTokenRepository
+	ttl         time.Duration
+
+	mu        sync.Mutex
+	users     map[uuid.UUID]cachedUser
+	revoked   map[uuid.UUID]cachedRevocation
+	lastSweep time.Time
+}
+
+type cachedUser struct {
+	// user is nil when the user does not exist.
+	user    *models.User
+	expires time.Time
+}
+
+type cachedRevocation struct {
+	revoked bool
+	expires time.Time
+}
+
+func NewSessionCache(userRepo *repository.UserRepository, revokedRepo *repository.RevokedTokenRepository, ttl time.Duration) *SessionCache {
+	return &SessionCache{
+		userRepo:    userRepo,
+		revokedRepo: revokedRepo,
+		ttl:         ttl,
+		users:       make(map[uuid.UUID]cachedUser),
+		revoked:     make(map[uuid.UUID]cachedRevocation),
+	}
+}
+
+// User returns the user with the ID, or nil when there is none. The result
+// is shared and must not be modified.
+func (c *SessionCache) User(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.users[id]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.user, nil
+	}
+
+	user, err := c.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.sweep(now)
+	c.users[id] = cachedUser{user: user, expires: now.Add(c.ttl)}
+	c.mu.Unlock()
+	return user, nil
+}
+
+// Forget drops the cached state of the user, so that the next request reads
+// it from the database. Call it after changing anything ValidateSession
+// checks.
+func (c *SessionCache) Forget(id uuid.UUID) {
+	c.mu.Lock()
+	delete(c.users, id)
+	c.mu.Unlock()
+}
+
+// Revoked reports whether the token ID was revoked. A revoked ID stays
+// revoked, so it is remembered until the token expires.
+func (c *SessionCache) Revoked(ctx context.Context, jti uuid.UUID, tokenExpires time.Time) (bool, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.revoked[jti]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := c.revokedRepo.IsRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+
+	entry = cachedRevocation{revoked: revoked, expires: now.Add(c.ttl)}
+	if revoked {
+		entry.expires = tokenExpires
+	}
+
+	c.mu.Lock()
+	c.sweep(now)
+	c.revoked[jti] = entry
+	c.mu.Unlock()
+	return revoked, nil
+}
+
+// Revoke revokes the token ID until the token expires.
+func (c *SessionCache) Revoke(ctx context.Context, jti uuid.UUID, userID uuid.UUID, tokenExpires time.Time) error {
+	if err := c.revokedRepo.Revoke(ctx, jti, userID, tokenExpires); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.revoked[jti] = cachedRevocation{revoked: true, expires: tokenExpires}
+	c.mu.Unlock()
+	return nil
+}
+
+// sweep drops expired entries, at most once per TTL. c.mu must be held.
+func (c *SessionCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	c.lastSweep = now
+
+	for id, entry := range c.users {
+		if !now.Before(entry.expires) {
+			delete(c.users, id)
+		}
+	}
+	for jti, entry := range c.revoked {
+		if !now.Before(entry.expires) {
+			delete(c.revoked, jti)
+		}
+	}
+}
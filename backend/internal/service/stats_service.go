@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+)
+
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 365
+)
+
+var ErrInvalidStatsWindow = errors.New("days must be between 1 and 365")
+
+type StatsService struct {
+	statsRepo *repository.StatsRepository
+}
+
+func NewStatsService(statsRepo *repository.StatsRepository) *StatsService {
+	return &StatsService{statsRepo: statsRepo}
+}
+
+// Get computes the user's stats. A zero Days selects the last 30 days and an
+// empty Timezone means UTC.
+func (s *StatsService) Get(ctx context.Context, userID uuid.UUID, opts models.StatsOptions) (*models.TodoStats, error) {
+	if opts.Days == 0 {
+		opts.Days = defaultStatsDays
+	}
+	if opts.Days < 1 || opts.Days > maxStatsDays {
+		return nil, ErrInvalidStatsWindow
+	}
+	if opts.Timezone == "" {
+		opts.Timezone = "UTC"
+	}
+
+	return s.statsRepo.Get(ctx, userID, opts)
+}
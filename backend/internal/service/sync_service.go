@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+)
+
+var ErrInvalidSyncToken = errors.New("invalid sync token")
+
+// maxSyncAttempts bounds how often a mutation is retried when the todo is
+// written concurrently between reading and updating it.
+const maxSyncAttempts = 3
+
+// SyncService implements delta sync for offline-first clients on top of the
+// todo sync position and per-field versions.
+type SyncService struct {
+	todoRepo    *repository.TodoRepository
+	todoService *TodoService
+}
+
+func NewSyncService(todoRepo *repository.TodoRepository, todoService *TodoService) *SyncService {
+	return &SyncService{
+		todoRepo:    todoRepo,
+		todoService: todoService,
+	}
+}
+
+// Pull returns everything that changed after the sync token. An empty token
+// returns a full snapshot without tombstones.
+func (s *SyncService) Pull(ctx context.Context, userID uuid.UUID, token string) (*models.SyncResponse, error) {
+	since, err := decodeSyncToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.todoService.Changes(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.SyncResponse{
+		Changed:   changes.Changed,
+		Deleted:   changes.Deleted,
+		SyncToken: encodeSyncToken(changes.Position),
+	}
+	if token == "" {
+		resp.Deleted = []*models.TodoTombstone{}
+	}
+	return resp, nil
+}
+
+// Push applies the client's mutations in order and returns their outcome
+// together with everything that changed after the request's sync token.
+func (s *SyncService) Push(ctx context.Context, userID uuid.UUID, req models.SyncRequest) (*models.SyncResponse, error) {
+	if _, err := decodeSyncToken(req.SyncToken); err != nil {
+		return nil, err
+	}
+
+	strategy := req.Strategy
+	if strategy == "" {
+		strategy = models.SyncFieldMerge
+	}
+
+	results := make([]models.SyncResult, 0, len(req.Mutations))
+	for _, m := range req.Mutations {
+		result, err := s.apply(ctx, userID, strategy, m)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	resp, err := s.Pull(ctx, userID, req.SyncToken)
+	if err != nil {
+		return nil, err
+	}
+	resp.Results = results
+	return resp, nil
+}
+
+func (s *SyncService) apply(ctx context.Context, userID uuid.UUID, strategy models.SyncStrategy, m models.SyncMutation) (models.SyncResult, error) {
+	result := models.SyncResult{ID: m.ID}
+
+	var setters map[string]func(*models.Todo)
+	if m.Op == models.SyncOpUpsert {
+		var err error
+		if setters, err = parseSyncFields(m); err != nil {
+			result.Status = models.SyncError
+			result.Error = err.Error()
+			return result, nil
+		}
+	}
+
+	if err := s.authorizeMutation(ctx, userID, m, setters); err != nil {
+		if !errors.Is(err, ErrPermissionDenied) {
+			return result, err
+		}
+		result.Status = models.SyncError
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	for attempt := 0; attempt < maxSyncAttempts; attempt++ {
+		current, err := s.todoRepo.GetByID(ctx, m.ID, userID)
+		if err != nil {
+			return result, err
+		}
+		if current == nil {
+			return s.applyToMissing(ctx, userID, m, setters)
+		}
+
+		// A client clock at or after the last server write wins conflicts.
+		clientWins := !m.UpdatedAt.Before(current.UpdatedAt)
+		upToDate := current.Version == m.BaseVersion
+
+		if m.Op == models.SyncOpDelete {
+			if !upToDate && !clientWins {
+				result.Status = models.SyncConflict
+				result.Todo = current
+				return result, nil
+			}
+			if err := s.todoRepo.DeleteIfVersion(ctx, m.ID, userID, current.Version); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					continue
+				}
+				return result, err
+			}
+			s.todoService.publish(ctx, EventTodoDeleted, current)
+			result.Status = models.SyncApplied
+			result.Deleted, err = s.todoRepo.GetTombstone(ctx, m.ID, userID)
+			return result, err
+		}
+
+		updated := *current
+		var discarded []string
+		for field, set := range setters {
+			accept := upToDate || clientWins
+			if strategy == models.SyncFieldMerge {
+				accept = accept || current.FieldVersion(field) <= m.BaseVersion
+			}
+			if accept {
+				set(&updated)
+			} else {
+				discarded = append(discarded, field)
+			}
+		}
+		sort.Strings(discarded)
+
+		if len(discarded) == len(setters) {
+			result.Status = models.SyncApplied
+			if len(setters) > 0 {
+				result.Status = models.SyncConflict
+				result.Discarded = discarded
+			}
+			result.Todo = current
+			return result, nil
+		}
+
+		if err := s.todoRepo.UpdateIfVersion(ctx, &updated, current.Version); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return result, err
+		}
+
+		saved, err := s.todoRepo.GetByID(ctx, m.ID, userID)
+		if err != nil {
+			return result, err
+		}
+
+		eventType := EventTodoUpdated
+		if saved != nil && saved.Completed && !current.Completed {
+			eventType = EventTodoCompleted
+		}
+		s.todoService.publish(ctx, eventType, saved)
+
+		result.Status = models.SyncApplied
+		if len(discarded) > 0 {
+			result.Status = models.SyncMerged
+			result.Discarded = discarded
+		}
+		result.Todo = saved
+		return result, nil
+	}
+
+	result.Status = models.SyncError
+	result.Error = "todo is being modified concurrently, retry later"
+	return result, nil
+}
+
+// authorizeMutation checks the permissions a mutation needs whether or not
+// the todo exists; creating one is checked separately.
+func (s *SyncService) authorizeMutation(ctx context.Context, userID uuid.UUID, m models.SyncMutation, setters map[string]func(*models.Todo)) error {
+	if m.Op == models.SyncOpDelete {
+		return s.todoService.authorize(ctx, userID, models.PermTodoDelete, m.ID)
+	}
+	if err := s.todoService.authorize(ctx, userID, models.PermTodoUpdate, m.ID); err != nil {
+		return err
+	}
+	if _, ok := setters["tags"]; ok {
+		return s.todoService.authorize(ctx, userID, models.PermTagManage, m.ID)
+	}
+	return nil
+}
+
+// applyToMissing handles a mutation of a todo the server does not have: it
+// was either deleted or created offline.
+func (s *SyncService) applyToMissing(ctx context.Context, userID uuid.UUID, m models.SyncMutation, setters map[string]func(*models.Todo)) (models.SyncResult, error) {
+	result := models.SyncResult{ID: m.ID}
+
+	tombstone, err := s.todoRepo.GetTombstone(ctx, m.ID, userID)
+	if err != nil {
+		return result, err
+	}
+
+	if m.Op == models.SyncOpDelete {
+		result.Status = models.SyncApplied
+		result.Deleted = tombstone
+		return result, nil
+	}
+
+	// Edits older than the deletion lose; newer ones bring the todo back.
+	if tombstone != nil && m.UpdatedAt.Before(tombstone.DeletedAt) {
+		result.Status = models.SyncConflict
+		result.Deleted = tombstone
+		return result, nil
+	}
+
+	if _, ok := setters["title"]; !ok {
+		result.Status = models.SyncError
+		result.Error = "title is required to create a todo"
+		return result, nil
+	}
+
+	if err := s.todoService.authorize(ctx, userID, models.PermTodoCreate, m.ID); err != nil {
+		if !errors.Is(err, ErrPermissionDenied) {
+			return result, err
+		}
+		result.Status = models.SyncError
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	todo := &models.Todo{
+		ID:       m.ID,
+		UserID:   userID,
+		Priority: models.PriorityMedium,
+	}
+	for _, set := range setters {
+		set(todo)
+	}
+	completed, completedAt := todo.Completed, todo.CompletedAt
+	todo.CompletedAt = nil
+
+	if err := s.todoRepo.Create(ctx, todo); err != nil {
+		if errors.Is(err, repository.ErrTodoIDTaken) {
+			result.Status = models.SyncError
+			result.Error = err.Error()
+			return result, nil
+		}
+		return result, err
+	}
+
+	if completed {
+		if err := s.todoRepo.SetCompletedAt(ctx, todo.ID, userID, completedAt); err != nil {
+			return result, err
+		}
+		if todo, err = s.todoRepo.GetByID(ctx, todo.ID, userID); err != nil {
+			return result, err
+		}
+	}
+
+	s.todoService.publish(ctx, EventTodoCreated, todo)
+	result.Status = models.SyncApplied
+	result.Todo = todo
+	return result, nil
+}
+
+// parseSyncFields validates the changed fields of an upsert and returns a
+// setter per field.
+func parseSyncFields(m models.SyncMutation) (map[string]func(*models.Todo), error) {
+	setters := make(map[string]func(*models.Todo), len(m.Fields))
+
+	for field, raw := range m.Fields {
+		var err error
+		switch field {
+		case "title":
+			var title string
+			if err = json.Unmarshal(raw, &title); err == nil {
+				if n := len([]rune(title)); n < 1 || n > 200 {
+					return nil, errors.New("title must be between 1 and 200 characters")
+				}
+				setters[field] = func(t *models.Todo) { t.Title = title }
+			}
+		case "description":
+			var description *string
+			if err = json.Unmarshal(raw, &description); err == nil {
+				if description != nil && len([]rune(*description)) > 1000 {
+					return nil, errors.New("description must be at most 1000 characters")
+				}
+				setters[field] = func(t *models.Todo) { t.Description = description }
+			}
+		case "priority":
+			var priority models.TodoPriority
+			if err = json.Unmarshal(raw, &priority); err == nil {
+				switch priority {
+				case models.PriorityLow, models.PriorityMedium, models.PriorityHigh:
+				default:
+					return nil, errors.New("priority must be one of low, medium, high")
+				}
+				setters[field] = func(t *models.Todo) { t.Priority = priority }
+			}
+		case "due_date":
+			var dueDate *time.Time
+			if err = json.Unmarshal(raw, &dueDate); err == nil {
+				setters[field] = func(t *models.Todo) { t.DueDate = dueDate }
+			}
+		case "tags":
+			var tags []string
+			if err = json.Unmarshal(raw, &tags); err == nil {
+				if tags == nil {
+					tags = []string{}
+				}
+				setters[field] = func(t *models.Todo) { t.Tags = tags }
+			}
+		case "completed":
+			var completed bool
+			if err = json.Unmarshal(raw, &completed); err == nil {
+				completedAt := m.UpdatedAt
+				setters[field] = func(t *models.Todo) {
+					if completed && !t.Completed {
+						t.CompletedAt = &completedAt
+					}
+					t.Completed = completed
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s", field)
+		}
+	}
+
+	return setters, nil
+}
+
+// syncTokenPrefix marks sync positions; tokens from when they were sequence
+// numbers ("seq:") are refused, so that clients start over.
+const syncTokenPrefix = "pos:"
+
+func encodeSyncToken(pos int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(syncTokenPrefix + strconv.FormatInt(pos, 10)))
+}
+
+func decodeSyncToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(raw), syncTokenPrefix) {
+		return 0, ErrInvalidSyncToken
+	}
+	pos, err := strconv.ParseInt(strings.TrimPrefix(string(raw), syncTokenPrefix), 10, 64)
+	if err != nil || pos < 0 {
+		return 0, ErrInvalidSyncToken
+	}
+	return pos, nil
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+func TestDecodeSyncToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		want    int64
+		wantErr bool
+	}{
+		{name: "empty starts from the beginning", token: "", want: 0},
+		{name: "valid", token: "cG9zOjQy", want: 42},
+		{name: "padded base64", token: "cG9zOjE=", wantErr: true},
+		{name: "not base64", token: "pos:42", wantErr: true},
+		{name: "wrong prefix", token: "Zm9vOjE", wantErr: true},
+		{name: "sequence number token", token: "c2VxOjQy", wantErr: true},
+		{name: "negative", token: "cG9zOi0x", wantErr: true},
+		{name: "not a number", token: "cG9zOmFiYw", wantErr: true},
+		{name: "no number", token: "cG9zOg", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeSyncToken(tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSyncToken) {
+					t.Errorf("decodeSyncToken(%q) error = %v, want %v", tt.token, err, ErrInvalidSyncToken)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("decodeSyncToken(%q) = %d, %v, want %d", tt.token, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncTokenRoundTrip(t *testing.T) {
+	for _, seq := range []int64{0, 1, 42, 1 << 40, math.MaxInt64} {
+		got, err := decodeSyncToken(encodeSyncToken(seq))
+		if err != nil || got != seq {
+			t.Errorf("decodeSyncToken(encodeSyncToken(%d)) = %d, %v", seq, got, err)
+		}
+	}
+}
+
+func TestParseSyncFields(t *testing.T) {
+	updatedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	due := time.Date(2024, 3, 5, 9, 30, 0, 0, time.UTC)
+	earlier := time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC)
+	description := "Notes"
+
+	tests := []struct {
+		name    string
+		fields  string
+		before  models.Todo
+		want    models.Todo
+		wantErr string
+	}{
+		{
+			name:   "no fields",
+			fields: `{}`,
+		},
+		{
+			name:   "title and description",
+			fields: `{"title": "Ship release", "description": "Notes"}`,
+			want:   models.Todo{Title: "Ship release", Description: &description},
+		},
+		{
+			name:   "null description clears it",
+			fields: `{"description": null}`,
+			before: models.Todo{Description: &description},
+			want:   models.Todo{},
+		},
+		{
+			name:   "priority, due date and tags",
+			fields: `{"priority": "high", "due_date": "2024-03-05T09:30:00Z", "tags": ["work", "release"]}`,
+			want:   models.Todo{Priority: models.PriorityHigh, DueDate: &due, Tags: []string{"work", "release"}},
+		},
+		{
+			name:   "null tags become empty",
+			fields: `{"tags": null}`,
+			before: models.Todo{Tags: []string{"work"}},
+			want:   models.Todo{Tags: []string{}},
+		},
+		{
+			name:   "completing sets completed_at from updated_at",
+			fields: `{"completed": true}`,
+			want:   models.Todo{Completed: true, CompletedAt: &updatedAt},
+		},
+		{
+			name:   "completing again keeps completed_at",
+			fields: `{"completed": true}`,
+			before: models.Todo{Completed: true, CompletedAt: &earlier},
+			want:   models.Todo{Completed: true, CompletedAt: &earlier},
+		},
+		{
+			name:    "empty title",
+			fields:  `{"title": ""}`,
+			wantErr: "title must be between 1 and 200 characters",
+		},
+		{
+			name:    "title too long",
+			fields:  `{"title": "` + strings.Repeat("é", 201) + `"}`,
+			wantErr: "title must be between 1 and 200 characters",
+		},
+		{
+			name:    "description too long",
+			fields:  `{"description": "` + strings.Repeat("a", 1001) + `"}`,
+			wantErr: "description must be at most 1000 characters",
+		},
+		{
+			name:    "unknown priority",
+			fields:  `{"priority": "urgent"}`,
+			wantErr: "priority must be one of low, medium, high",
+		},
+		{
+			name:    "wrong type",
+			fields:  `{"completed": "yes"}`,
+			wantErr: "invalid value for completed",
+		},
+		{
+			name:    "malformed date",
+			fields:  `{"due_date": "tomorrow"}`,
+			wantErr: "invalid value for due_date",
+		},
+		{
+			name:    "unknown field",
+			fields:  `{"user_id": "00000000-0000-0000-0000-000000000000"}`,
+			wantErr: `unknown field "user_id"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := models.SyncMutation{Op: models.SyncOpUpsert, UpdatedAt: updatedAt}
+			if err := json.Unmarshal([]byte(tt.fields), &m.Fields); err != nil {
+				t.Fatal(err)
+			}
+
+			setters, err := parseSyncFields(m)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(setters) != len(m.Fields) {
+				t.Errorf("got %d setters for %d fields", len(setters), len(m.Fields))
+			}
+
+			todo := tt.before
+			for _, set := range setters {
+				set(&todo)
+			}
+			if !reflect.DeepEqual(todo, tt.want) {
+				t.Errorf("todo = %+v, want %+v", todo, tt.want)
+			}
+		})
+	}
+}
@@ -2,24 +2,97 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/yourusername/todogo-backend/internal/models"
 	"github.com/yourusername/todogo-backend/internal/repository"
 )
 
+var (
+	ErrNotTodoOwner      = errors.New("only the todo's owner can do this")
+	ErrAssigneeNotFound  = errors.New("no user with that email")
+	ErrAssigneeNotMember = errors.New("assignee is not a member of the todo's workspace")
+)
+
 type TodoService struct {
-	todoRepo *repository.TodoRepository
+	todoRepo      *repository.TodoRepository
+	userRepo      *repository.UserRepository
+	workspaceRepo *repository.WorkspaceRepository
+	policy        *PolicyService
+	listeners     []TodoEventListener
 }
 
-func NewTodoService(todoRepo *repository.TodoRepository) *TodoService {
+func NewTodoService(todoRepo *repository.TodoRepository, userRepo *repository.UserRepository, workspaceRepo *repository.WorkspaceRepository, policy *PolicyService) *TodoService {
 	return &TodoService{
-		todoRepo: todoRepo,
+		todoRepo:      todoRepo,
+		userRepo:      userRepo,
+		workspaceRepo: workspaceRepo,
+		policy:        policy,
+	}
+}
+
+// authorize checks a permission for an operation on one todo, or on the
+// user's todos when id is uuid.Nil.
+func (s *TodoService) authorize(ctx context.Context, userID uuid.UUID, perm models.Permission, id uuid.UUID) error {
+	target := "todos"
+	if id != uuid.Nil {
+		target = "todo:" + id.String()
+	}
+	return s.policy.Authorize(ctx, userID, perm, target)
+}
+
+// AddListener registers a listener for todo changes. It must be called
+// before the service starts handling requests.
+func (s *TodoService) AddListener(l TodoEventListener) {
+	s.listeners = append(s.listeners, l)
+}
+
+func (s *TodoService) publish(ctx context.Context, eventType TodoEventType, todo *models.Todo) {
+	if todo == nil {
+		return
+	}
+	s.dispatch(ctx, TodoEvent{
+		ID:         uuid.New(),
+		Type:       eventType,
+		UserID:     todo.UserID,
+		Todo:       todo,
+		OccurredAt: time.Now(),
+	})
+}
+
+func (s *TodoService) dispatch(ctx context.Context, event TodoEvent) {
+	for _, l := range s.listeners {
+		l.OnTodoEvent(ctx, event)
+	}
+}
+
+// notOwned explains why the user cannot change a todo they do not own:
+// ErrNotTodoOwner when it is assigned to them, not found otherwise.
+func (s *TodoService) notOwned(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	todo, err := s.todoRepo.GetAccessible(ctx, id, userID)
+	if err != nil {
+		return err
 	}
+	if todo != nil {
+		return ErrNotTodoOwner
+	}
+	return errors.New("todo not found")
 }
 
 func (s *TodoService) Create(ctx context.Context, req models.CreateTodoRequest, userID uuid.UUID) (*models.Todo, error) {
+	if err := s.authorize(ctx, userID, models.PermTodoCreate, uuid.Nil); err != nil {
+		return nil, err
+	}
+	if len(req.Tags) > 0 {
+		if err := s.authorize(ctx, userID, models.PermTagManage, uuid.Nil); err != nil {
+			return nil, err
+		}
+	}
+
 	priority := models.PriorityMedium
 	if req.Priority != nil {
 		priority = *req.Priority
@@ -32,17 +105,57 @@ func (s *TodoService) Create(ctx context.Context, req models.CreateTodoRequest,
 		UserID:      userID,
 		DueDate:     req.DueDate,
 		Tags:        req.Tags,
+		ICalUID:     req.ICalUID,
 	}
 
 	if err := s.todoRepo.Create(ctx, todo); err != nil {
 		return nil, err
 	}
 
+	recordUndo(ctx, EventTodoCreated, todo.ID, nil, todo.Version)
+	s.publish(ctx, EventTodoCreated, todo)
 	return todo, nil
 }
 
+// Duplicate creates a new todo from an existing one. Copying tags takes
+// PermTagManage like setting them on Create; without it they are left out
+// unless asked for.
+func (s *TodoService) Duplicate(ctx context.Context, id uuid.UUID, req models.DuplicateTodoRequest, userID uuid.UUID) (*models.Todo, error) {
+	if err := s.authorize(ctx, userID, models.PermTodoCreate, id); err != nil {
+		return nil, err
+	}
+	if req.KeepTags == nil {
+		keepTags, err := s.policy.Allows(ctx, userID, models.PermTagManage)
+		if err != nil {
+			return nil, err
+		}
+		req.KeepTags = &keepTags
+	} else if *req.KeepTags {
+		if err := s.authorize(ctx, userID, models.PermTagManage, id); err != nil {
+			return nil, err
+		}
+	}
+
+	todo, err := s.todoRepo.Duplicate(ctx, id, userID, uuid.New(), req)
+	if err != nil {
+		return nil, err
+	}
+	if todo == nil {
+		return nil, s.notOwned(ctx, id, userID)
+	}
+
+	recordUndo(ctx, EventTodoCreated, todo.ID, nil, todo.Version)
+	s.publish(ctx, EventTodoCreated, todo)
+	return todo, nil
+}
+
+// GetByID returns a todo the user owns or is assigned to.
 func (s *TodoService) GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
-	todo, err := s.todoRepo.GetByID(ctx, id, userID)
+	if err := s.authorize(ctx, userID, models.PermTodoRead, id); err != nil {
+		return nil, err
+	}
+
+	todo, err := s.todoRepo.GetAccessible(ctx, id, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -52,17 +165,38 @@ func (s *TodoService) GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUI
 	return todo, nil
 }
 
+// GetByIDs returns the user's todos with the given IDs; unknown IDs are left
+// out.
+func (s *TodoService) GetByIDs(ctx context.Context, ids []uuid.UUID, userID uuid.UUID) ([]*models.Todo, error) {
+	if err := s.authorize(ctx, userID, models.PermTodoRead, uuid.Nil); err != nil {
+		return nil, err
+	}
+	return s.todoRepo.GetByIDs(ctx, ids, userID)
+}
+
 func (s *TodoService) GetAll(ctx context.Context, userID uuid.UUID, filters models.TodoFilters) ([]*models.Todo, error) {
+	if err := s.authorize(ctx, userID, models.PermTodoRead, uuid.Nil); err != nil {
+		return nil, err
+	}
 	return s.todoRepo.GetAll(ctx, userID, filters)
 }
 
 func (s *TodoService) Update(ctx context.Context, id uuid.UUID, req models.UpdateTodoRequest, userID uuid.UUID) (*models.Todo, error) {
+	if err := s.authorize(ctx, userID, models.PermTodoUpdate, id); err != nil {
+		return nil, err
+	}
+	if req.Tags != nil {
+		if err := s.authorize(ctx, userID, models.PermTagManage, id); err != nil {
+			return nil, err
+		}
+	}
+
 	todo, err := s.todoRepo.GetByID(ctx, id, userID)
 	if err != nil {
 		return nil, err
 	}
 	if todo == nil {
-		return nil, errors.New("todo not found")
+		return nil, s.notOwned(ctx, id, userID)
 	}
 
 	// Update fields
@@ -82,29 +216,418 @@ func (s *TodoService) Update(ctx context.Context, id uuid.UUID, req models.Updat
 		todo.Tags = req.Tags
 	}
 
+	before, err := s.snapshotForUndo(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := s.todoRepo.Update(ctx, todo); err != nil {
 		return nil, err
 	}
 
+	recordUndo(ctx, EventTodoUpdated, id, before, todo.Version)
+	s.publish(ctx, EventTodoUpdated, todo)
 	return todo, nil
 }
 
 func (s *TodoService) MarkAsCompleted(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
-	if err := s.todoRepo.UpdateStatus(ctx, id, userID, true); err != nil {
+	if err := s.authorize(ctx, userID, models.PermTodoUpdate, id); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := s.ownerFor(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.snapshotForUndo(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.todoRepo.UpdateStatus(ctx, id, ownerID, true); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
+
+	todo, err := s.todoRepo.GetByID(ctx, id, ownerID)
+	if err != nil {
 		return nil, err
 	}
+	if todo == nil {
+		return nil, errors.New("todo not found")
+	}
 
-	return s.todoRepo.GetByID(ctx, id, userID)
+	recordUndo(ctx, EventTodoCompleted, id, before, todo.Version)
+	s.publish(ctx, EventTodoCompleted, todo)
+	return todo, nil
 }
 
 func (s *TodoService) MarkAsIncomplete(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
-	if err := s.todoRepo.UpdateStatus(ctx, id, userID, false); err != nil {
+	if err := s.authorize(ctx, userID, models.PermTodoUpdate, id); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := s.ownerFor(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.snapshotForUndo(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.todoRepo.UpdateStatus(ctx, id, ownerID, false); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
+
+	todo, err := s.todoRepo.GetByID(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if todo == nil {
+		return nil, errors.New("todo not found")
+	}
+
+	recordUndo(ctx, EventTodoUpdated, id, before, todo.Version)
+	s.publish(ctx, EventTodoUpdated, todo)
+	return todo, nil
+}
+
+// ownerFor returns the owner of a todo the user owns or is assigned to, so
+// that assignees can complete and reopen it.
+func (s *TodoService) ownerFor(ctx context.Context, id uuid.UUID, userID uuid.UUID) (uuid.UUID, error) {
+	todo, err := s.todoRepo.GetAccessible(ctx, id, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if todo == nil {
+		return uuid.Nil, errors.New("todo not found")
+	}
+	return todo.UserID, nil
+}
+
+// Assign hands a todo to the user with the given email, or takes it back
+// when email is nil. The assignee and any previous assignee are notified
+// through the todo.assigned event.
+func (s *TodoService) Assign(ctx context.Context, id uuid.UUID, email *string, userID uuid.UUID) (*models.Todo, error) {
+	if err := s.authorize(ctx, userID, models.PermTodoAssign, id); err != nil {
+		return nil, err
+	}
+
+	todo, err := s.todoRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if todo == nil {
+		return nil, s.notOwned(ctx, id, userID)
+	}
+
+	var assigneeID *uuid.UUID
+	if email != nil {
+		user, err := s.userRepo.GetByEmail(ctx, *email)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrAssigneeNotFound
+		}
+		// Assigning a todo to its owner is the same as unassigning it
+		if user.ID != userID {
+			member, err := s.workspaceRepo.IsMember(ctx, todo.WorkspaceID, user.ID)
+			if err != nil {
+				return nil, err
+			}
+			if !member {
+				return nil, ErrAssigneeNotMember
+			}
+			assigneeID = &user.ID
+		}
+	}
+
+	previous := todo.AssigneeID
+	if sameAssignee(previous, assigneeID) {
+		return todo, nil
+	}
+
+	before, err := s.snapshotForUndo(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.todoRepo.SetAssignee(ctx, id, userID, assigneeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
+
+	todo, err = s.todoRepo.GetByID(ctx, id, userID)
+	if err != nil {
 		return nil, err
 	}
 
-	return s.todoRepo.GetByID(ctx, id, userID)
+	recordUndo(ctx, EventTodoAssigned, id, before, todo.Version)
+	s.dispatch(ctx, TodoEvent{
+		ID:                 uuid.New(),
+		Type:               EventTodoAssigned,
+		UserID:             todo.UserID,
+		Todo:               todo,
+		PreviousAssigneeID: previous,
+		OccurredAt:         time.Now(),
+	})
+	return todo, nil
+}
+
+func sameAssignee(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// Archive hides the todo from default queries; archiving an archived todo
+// keeps its original archive time.
+func (s *TodoService) Archive(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	return s.setArchived(ctx, id, userID, true)
+}
+
+func (s *TodoService) Unarchive(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Todo, error) {
+	return s.setArchived(ctx, id, userID, false)
+}
+
+func (s *TodoService) setArchived(ctx context.Context, id uuid.UUID, userID uuid.UUID, archived bool) (*models.Todo, error) {
+	if err := s.authorize(ctx, userID, models.PermTodoUpdate, id); err != nil {
+		return nil, err
+	}
+
+	todo, err := s.todoRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if todo == nil {
+		return nil, s.notOwned(ctx, id, userID)
+	}
+	if (todo.ArchivedAt != nil) == archived {
+		return todo, nil
+	}
+
+	before, err := s.snapshotForUndo(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var archivedAt *time.Time
+	if archived {
+		now := time.Now()
+		archivedAt = &now
+	}
+	if err := s.todoRepo.SetArchived(ctx, id, userID, archivedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
+
+	todo, err = s.todoRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	recordUndo(ctx, EventTodoUpdated, id, before, todo.Version)
+	s.publish(ctx, EventTodoUpdated, todo)
+	return todo, nil
+}
+
+// ArchiveByRules applies the users' archive rules to up to limit todos and
+// reports how many were archived.
+func (s *TodoService) ArchiveByRules(ctx context.Context, limit int) (int, error) {
+	todos, err := s.todoRepo.ArchiveByRules(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, todo := range todos {
+		s.publish(ctx, EventTodoUpdated, todo)
+	}
+	return len(todos), nil
 }
 
 func (s *TodoService) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
-	return s.todoRepo.Delete(ctx, id, userID)
+	if err := s.authorize(ctx, userID, models.PermTodoDelete, id); err != nil {
+		return err
+	}
+
+	todo, err := s.todoRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if todo == nil {
+		return s.notOwned(ctx, id, userID)
+	}
+
+	before, err := s.snapshotForUndo(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.todoRepo.Delete(ctx, id, userID); err != nil {
+		return err
+	}
+
+	recordUndo(ctx, EventTodoDeleted, id, before, 0)
+	s.publish(ctx, EventTodoDeleted, todo)
+	return nil
+}
+
+// GetByICalUID resolves an iCalendar UID, either one Todogo generated
+// ("<id>@todogo") or one stored when the todo was imported.
+func (s *TodoService) GetByICalUID(ctx context.Context, uid string, userID uuid.UUID) (*models.Todo, error) {
+	if err := s.authorize(ctx, userID, models.PermTodoRead, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	if id, ok := parseTodoUID(uid); ok {
+		todo, err := s.todoRepo.GetByID(ctx, id, userID)
+		if err != nil || todo != nil {
+			return todo, err
+		}
+	}
+	return s.todoRepo.GetByICalUID(ctx, uid, userID)
+}
+
+// SetCompletedAt marks the todo completed at the given time, or incomplete
+// when completedAt is nil. Used by sync clients that carry their own
+// completion timestamps.
+func (s *TodoService) SetCompletedAt(ctx context.Context, id uuid.UUID, userID uuid.UUID, completedAt *time.Time) (*models.Todo, error) {
+	if err := s.authorize(ctx, userID, models.PermTodoUpdate, id); err != nil {
+		return nil, err
+	}
+
+	before, err := s.snapshotForUndo(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.todoRepo.SetCompletedAt(ctx, id, userID, completedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
+
+	todo, err := s.todoRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	eventType := EventTodoUpdated
+	if completedAt != nil {
+		eventType = EventTodoCompleted
+	}
+	recordUndo(ctx, eventType, id, before, todo.Version)
+	s.publish(ctx, eventType, todo)
+	return todo, nil
+}
+
+// Replace overwrites every editable field of an existing todo, including
+// clearing optional ones, and reconciles its completion state. Unlike Update
+// it is meant for clients that always send the full object.
+func (s *TodoService) Replace(ctx context.Context, todo *models.Todo) (*models.Todo, error) {
+	if err := s.authorize(ctx, todo.UserID, models.PermTodoUpdate, todo.ID); err != nil {
+		return nil, err
+	}
+
+	current, err := s.todoRepo.GetByID(ctx, todo.ID, todo.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, errors.New("todo not found")
+	}
+	if !slices.Equal(todo.Tags, current.Tags) {
+		if err := s.authorize(ctx, todo.UserID, models.PermTagManage, todo.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	before, err := s.snapshotForUndo(ctx, todo.ID, todo.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.todoRepo.Update(ctx, todo); err != nil {
+		return nil, err
+	}
+
+	eventType := EventTodoUpdated
+	if todo.Completed != current.Completed {
+		var completedAt *time.Time
+		if todo.Completed {
+			completedAt = todo.CompletedAt
+			if completedAt == nil {
+				now := time.Now()
+				completedAt = &now
+			}
+			eventType = EventTodoCompleted
+		}
+		if err := s.todoRepo.SetCompletedAt(ctx, todo.ID, todo.UserID, completedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	saved, err := s.todoRepo.GetByID(ctx, todo.ID, todo.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	recordUndo(ctx, eventType, saved.ID, before, saved.Version)
+	s.publish(ctx, eventType, saved)
+	return saved, nil
+}
+
+// TodoChanges is the set of writes after a sync position.
+type TodoChanges struct {
+	Changed  []*models.Todo
+	Deleted  []*models.TodoTombstone
+	Position int64
+}
+
+// SyncPosition returns the sync position of the user's todos, which changes
+// whenever any of them is written or deleted.
+func (s *TodoService) SyncPosition(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.todoRepo.SyncPosition(ctx, userID)
+}
+
+// Changes returns todos written and deleted at or after the given sync
+// position, together with the position to resume from next time.
+func (s *TodoService) Changes(ctx context.Context, userID uuid.UUID, since int64) (*TodoChanges, error) {
+	if err := s.authorize(ctx, userID, models.PermTodoRead, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	// Read the position first so that writes racing with this call are
+	// reported again next time rather than skipped.
+	pos, err := s.todoRepo.SyncPosition(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := s.todoRepo.GetChangedSince(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.todoRepo.GetDeletedSince(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TodoChanges{Changed: changed, Deleted: deleted, Position: pos}, nil
 }
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/config"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+)
+
+// actionBulk labels undo tokens that cover different kinds of changes.
+const actionBulk = "bulk"
+
+var ErrUndoNotFound = errors.New("undo token is invalid or expired")
+
+type undoScopeKey struct{}
+
+// UndoScope collects the changes TodoService makes under one context so that
+// they can be undone together.
+type UndoScope struct {
+	mu      sync.Mutex
+	action  string
+	entries []models.UndoEntry
+}
+
+// WithUndo returns a context in which TodoService records its changes into
+// the returned scope. Changes made without a scope cannot be undone.
+func WithUndo(ctx context.Context) (context.Context, *UndoScope) {
+	scope := &UndoScope{}
+	return context.WithValue(ctx, undoScopeKey{}, scope), scope
+}
+
+func undoScopeFrom(ctx context.Context) *UndoScope {
+	scope, _ := ctx.Value(undoScopeKey{}).(*UndoScope)
+	return scope
+}
+
+func (s *UndoScope) add(eventType TodoEventType, entry models.UndoEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch s.action {
+	case "":
+		s.action = string(eventType)
+	case string(eventType):
+	default:
+		s.action = actionBulk
+	}
+	s.entries = append(s.entries, entry)
+}
+
+type UndoService struct {
+	undoRepo    *repository.UndoRepository
+	todoService *TodoService
+	window      time.Duration
+}
+
+func NewUndoService(undoRepo *repository.UndoRepository, todoService *TodoService, cfg config.UndoConfig) *UndoService {
+	return &UndoService{
+		undoRepo:    undoRepo,
+		todoService: todoService,
+		window:      cfg.Window,
+	}
+}
+
+// Issue stores the changes recorded in scope and returns a token that undoes
+// them until it expires. It returns an empty token when nothing changed.
+func (s *UndoService) Issue(ctx context.Context, scope *UndoScope, userID uuid.UUID) (string, time.Time, error) {
+	scope.mu.Lock()
+	action := &models.UndoAction{
+		UserID:    userID,
+		Action:    scope.action,
+		Entries:   append([]models.UndoEntry(nil), scope.entries...),
+		ExpiresAt: time.Now().Add(s.window),
+	}
+	scope.mu.Unlock()
+
+	if len(action.Entries) == 0 {
+		return "", time.Time{}, nil
+	}
+
+	token, err := generateSecret()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if err := s.undoRepo.Create(ctx, hashSecret(token), action); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, action.ExpiresAt, nil
+}
+
+// Undo reverts the changes behind a token. A token works once, and only
+// while the user's role still allows what reverting each change does.
+func (s *UndoService) Undo(ctx context.Context, token string, userID uuid.UUID) (*models.UndoResult, error) {
+	granted := map[models.Permission]bool{}
+	check := func(entry models.UndoEntry) error {
+		perm := undoPermission(entry)
+		if granted[perm] {
+			return nil
+		}
+		if err := s.todoService.authorize(ctx, userID, perm, entry.TodoID); err != nil {
+			return err
+		}
+		granted[perm] = true
+		return nil
+	}
+
+	result, err := s.undoRepo.Undo(ctx, hashSecret(token), userID, check)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, ErrUndoNotFound
+	}
+
+	for _, todo := range result.Restored {
+		s.todoService.publish(ctx, EventTodoCreated, todo)
+	}
+	for _, todo := range result.Reverted {
+		s.todoService.publish(ctx, EventTodoUpdated, todo)
+	}
+	for _, todo := range result.Removed {
+		s.todoService.publish(ctx, EventTodoDeleted, todo)
+	}
+	return result, nil
+}
+
+// undoPermission is the permission reverting the entry takes: undoing a
+// create deletes the todo, undoing a delete creates it again.
+func undoPermission(entry models.UndoEntry) models.Permission {
+	switch {
+	case len(entry.Before) == 0:
+		return models.PermTodoDelete
+	case entry.Version == 0:
+		return models.PermTodoCreate
+	default:
+		return models.PermTodoUpdate
+	}
+}
+
+// snapshotForUndo returns the todo's stored row when ctx carries an undo
+// scope, and nil otherwise.
+func (s *TodoService) snapshotForUndo(ctx context.Context, id uuid.UUID, userID uuid.UUID) (json.RawMessage, error) {
+	if undoScopeFrom(ctx) == nil {
+		return nil, nil
+	}
+	return s.todoRepo.Snapshot(ctx, id, userID)
+}
+
+// recordUndo adds a change to the context's undo scope, if any. before is
+// nil for created todos and version is 0 for deleted ones.
+func recordUndo(ctx context.Context, eventType TodoEventType, id uuid.UUID, before json.RawMessage, version int) {
+	if scope := undoScopeFrom(ctx); scope != nil {
+		scope.add(eventType, models.UndoEntry{TodoID: id, Before: before, Version: version})
+	}
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+)
+
+var (
+	ErrViewNotFound = errors.New("view not found")
+	ErrBuiltInView  = errors.New("built-in views cannot be changed")
+)
+
+// builtInViews returns the views every user has, in display order.
+func builtInViews() []*models.View {
+	pending := models.StatusPending
+	days := func(n int) *int { return &n }
+
+	return []*models.View{
+		{
+			ID:        "today",
+			Name:      "Today",
+			Filter:    models.ViewFilter{Status: &pending, Due: &models.DueRange{FromDays: days(0), ToDays: days(1)}},
+			Sort:      models.SortDueDate,
+			Direction: models.SortAsc,
+			GroupBy:   models.GroupNone,
+		},
+		{
+			ID:        "upcoming",
+			Name:      "Upcoming",
+			Filter:    models.ViewFilter{Status: &pending, Due: &models.DueRange{FromDays: days(1), ToDays: days(8)}},
+			Sort:      models.SortDueDate,
+			Direction: models.SortAsc,
+			GroupBy:   models.GroupDueDate,
+		},
+		{
+			ID:        "overdue",
+			Name:      "Overdue",
+			Filter:    models.ViewFilter{Status: &pending, Due: &models.DueRange{ToDays: days(0)}},
+			Sort:      models.SortDueDate,
+			Direction: models.SortAsc,
+			GroupBy:   models.GroupNone,
+		},
+		{
+			ID:        "no-due-date",
+			Name:      "No Due Date",
+			Filter:    models.ViewFilter{Status: &pending, Due: &models.DueRange{None: true}},
+			Sort:      models.SortCreatedAt,
+			Direction: models.SortDesc,
+			GroupBy:   models.GroupNone,
+		},
+	}
+}
+
+func builtInView(id string) *models.View {
+	for _, view := range builtInViews() {
+		if view.ID == id {
+			view.BuiltIn = true
+			return view
+		}
+	}
+	return nil
+}
+
+type ViewService struct {
+	viewRepo *repository.ViewRepository
+	todoRepo *repository.TodoRepository
+}
+
+func NewViewService(viewRepo *repository.ViewRepository, todoRepo *repository.TodoRepository) *ViewService {
+	return &ViewService{
+		viewRepo: viewRepo,
+		todoRepo: todoRepo,
+	}
+}
+
+func (s *ViewService) Create(ctx context.Context, req models.CreateViewRequest, userID uuid.UUID) (*models.View, error) {
+	view := &models.View{
+		Name:      req.Name,
+		Filter:    req.Filter,
+		Sort:      req.Sort,
+		Direction: req.Direction,
+		GroupBy:   req.GroupBy,
+	}
+	applyViewDefaults(view)
+
+	if err := s.viewRepo.Create(ctx, view, userID); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// GetAll lists the built-in views followed by the user's own.
+func (s *ViewService) GetAll(ctx context.Context, userID uuid.UUID) ([]*models.View, error) {
+	saved, err := s.viewRepo.GetAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := builtInViews()
+	for _, view := range views {
+		view.BuiltIn = true
+	}
+	return append(views, saved...), nil
+}
+
+// GetByID accepts a built-in view's slug or the UUID of a saved view.
+func (s *ViewService) GetByID(ctx context.Context, id string, userID uuid.UUID) (*models.View, error) {
+	if view := builtInView(id); view != nil {
+		return view, nil
+	}
+
+	viewID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrViewNotFound
+	}
+	view, err := s.viewRepo.GetByID(ctx, viewID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if view == nil {
+		return nil, ErrViewNotFound
+	}
+	return view, nil
+}
+
+func (s *ViewService) Update(ctx context.Context, id string, req models.UpdateViewRequest, userID uuid.UUID) (*models.View, error) {
+	view, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if view.BuiltIn {
+		return nil, ErrBuiltInView
+	}
+
+	if req.Name != nil {
+		view.Name = *req.Name
+	}
+	if req.Filter != nil {
+		view.Filter = *req.Filter
+	}
+	if req.Sort != nil {
+		view.Sort = *req.Sort
+	}
+	if req.Direction != nil {
+		view.Direction = *req.Direction
+	}
+	if req.GroupBy != nil {
+		view.GroupBy = *req.GroupBy
+	}
+	applyViewDefaults(view)
+
+	if err := s.viewRepo.Update(ctx, view, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrViewNotFound
+		}
+		return nil, err
+	}
+	return view, nil
+}
+
+func (s *ViewService) Delete(ctx context.Context, id string, userID uuid.UUID) error {
+	if builtInView(id) != nil {
+		return ErrBuiltInView
+	}
+
+	viewID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrViewNotFound
+	}
+	if err := s.viewRepo.Delete(ctx, viewID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrViewNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Todos evaluates a view. Relative due ranges and due date groups use
+// calendar days in the given IANA timezone; an empty timezone means UTC.
+func (s *ViewService) Todos(ctx context.Context, id string, timezone string, userID uuid.UUID) (*models.ViewResult, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, repository.ErrInvalidTimezone
+	}
+
+	view, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, err := s.todoRepo.GetAll(ctx, userID, viewFilters(view, time.Now().In(loc)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ViewResult{
+		View:     view,
+		Timezone: timezone,
+		Total:    len(todos),
+		Groups:   groupTodos(todos, view.GroupBy, loc),
+	}, nil
+}
+
+func applyViewDefaults(view *models.View) {
+	if view.Sort == "" {
+		view.Sort = models.SortCreatedAt
+		if view.Direction == "" {
+			view.Direction = models.SortDesc
+		}
+	}
+	if view.Direction == "" {
+		view.Direction = models.SortAsc
+	}
+	if view.GroupBy == "" {
+		view.GroupBy = models.GroupNone
+	}
+}
+
+// viewFilters turns a view into repository filters, resolving relative due
+// ranges against the start of the local day of now.
+func viewFilters(view *models.View, now time.Time) models.TodoFilters {
+	filters := models.TodoFilters{
+		Status:    view.Filter.Status,
+		Priority:  view.Filter.Priority,
+		Search:    view.Filter.Search,
+		Tags:      view.Filter.Tags,
+		Sort:      view.Sort,
+		Direction: view.Direction,
+	}
+
+	due := view.Filter.Due
+	if due == nil {
+		return filters
+	}
+	if due.None {
+		filters.NoDueDate = true
+		return filters
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	filters.DueFrom = due.From
+	if due.FromDays != nil {
+		from := today.AddDate(0, 0, *due.FromDays)
+		filters.DueFrom = &from
+	}
+	filters.DueBefore = due.To
+	if due.ToDays != nil {
+		to := today.AddDate(0, 0, *due.ToDays)
+		filters.DueBefore = &to
+	}
+	return filters
+}
+
+// groupTodos splits sorted todos into groups in order of first appearance.
+// Todos without a due date or tags fall into a group with an empty key, and
+// a todo with several tags appears in each of their groups.
+func groupTodos(todos []*models.Todo, groupBy models.ViewGroupBy, loc *time.Location) []models.TodoGroup {
+	if groupBy == "" || groupBy == models.GroupNone {
+		return []models.TodoGroup{{Key: "", Todos: todos}}
+	}
+
+	groups := []models.TodoGroup{}
+	index := map[string]int{}
+	add := func(key string, todo *models.Todo) {
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, models.TodoGroup{Key: key, Todos: []*models.Todo{}})
+		}
+		groups[i].Todos = append(groups[i].Todos, todo)
+	}
+
+	for _, todo := range todos {
+		switch groupBy {
+		case models.GroupStatus:
+			add(string(todo.Status), todo)
+		case models.GroupPriority:
+			add(string(todo.Priority), todo)
+		case models.GroupDueDate:
+			key := ""
+			if todo.DueDate != nil {
+				key = todo.DueDate.In(loc).Format("2006-01-02")
+			}
+			add(key, todo)
+		case models.GroupTag:
+			if len(todo.Tags) == 0 {
+				add("", todo)
+			}
+			for _, tag := range todo.Tags {
+				add(tag, todo)
+			}
+		}
+	}
+	return groups
+}
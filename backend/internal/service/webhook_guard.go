@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var ErrWebhookURLNotAllowed = errors.New("webhook URL must be a public http or https address")
+
+// reservedNetworks are blocked on top of what net.IP classifies as
+// loopback, private, link-local, multicast or unspecified.
+var reservedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // "this" network
+	"100.64.0.0/10", // carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"240.0.0.0/4",   // reserved, includes broadcast
+	"64:ff9b::/96",  // NAT64, can embed any IPv4 address
+)
+
+// webhookGuard keeps webhooks from reaching the server's own network:
+// loopback, private and link-local addresses such as cloud metadata
+// endpoints. Hosts and networks on the allowlist are exempt, so that a local
+// receiver can be used in development.
+type webhookGuard struct {
+	hosts map[string]bool
+	nets  []*net.IPNet
+}
+
+// newWebhookGuard builds a guard from allowlist entries, each a host name,
+// an IP address or a CIDR network.
+func newWebhookGuard(allowed []string) *webhookGuard {
+	g := &webhookGuard{hosts: make(map[string]bool)}
+	for _, entry := range allowed {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			g.nets = append(g.nets, network)
+			continue
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			g.nets = append(g.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		g.hosts[strings.TrimSuffix(entry, ".")] = true
+	}
+	return g
+}
+
+// CheckURL rejects URLs that are not http or https or whose host resolves
+// to a blocked address. The dialer checks again on every delivery, since
+// DNS answers can change.
+func (g *webhookGuard) CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrWebhookURLNotAllowed
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if g.hosts[host] {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !g.ipAllowed(ip) {
+			return ErrWebhookURLNotAllowed
+		}
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrWebhookURLNotAllowed
+	}
+
+	// Unresolvable hosts are accepted; deliveries fail until they resolve
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !g.ipAllowed(addr.IP) {
+			return ErrWebhookURLNotAllowed
+		}
+	}
+	return nil
+}
+
+// ipAllowed reports whether the address is public or on the allowlist.
+func (g *webhookGuard) ipAllowed(ip net.IP) bool {
+	for _, network := range g.nets {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return !isBlockedIP(ip)
+}
+
+func isBlockedIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Transport returns an HTTP transport that refuses to connect to blocked
+// addresses. The check runs on the address actually dialed, after DNS
+// resolution, which also covers DNS rebinding. Proxies are not used, as they
+// would hide the destination from the check.
+func (g *webhookGuard) Transport(timeout time.Duration) *http.Transport {
+	open := &net.Dialer{Timeout: timeout}
+	guarded := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !g.ipAllowed(ip) {
+				return fmt.Errorf("%w: %s", ErrWebhookURLNotAllowed, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err == nil && g.hosts[strings.TrimSuffix(strings.ToLower(host), ".")] {
+			return open.DialContext(ctx, network, address)
+		}
+		return guarded.DialContext(ctx, network, address)
+	}
+	return transport
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWebhookGuardCheckURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		url     string
+		wantErr bool
+	}{
+		{name: "public IPv4", url: "https://93.184.216.34/hook"},
+		{name: "public IPv6", url: "https://[2606:4700::1111]/hook"},
+		{name: "public address with port", url: "http://93.184.216.34:8080/hook"},
+		{name: "unsupported scheme", url: "ftp://93.184.216.34/hook", wantErr: true},
+		{name: "no scheme", url: "93.184.216.34/hook", wantErr: true},
+		{name: "no host", url: "https:///hook", wantErr: true},
+		{name: "loopback", url: "http://127.0.0.1:8080/hook", wantErr: true},
+		{name: "IPv6 loopback", url: "http://[::1]/hook", wantErr: true},
+		{name: "localhost", url: "http://localhost/hook", wantErr: true},
+		{name: "localhost subdomain", url: "http://api.localhost/hook", wantErr: true},
+		{name: "private network", url: "http://10.1.2.3/hook", wantErr: true},
+		{name: "private network 192.168", url: "http://192.168.0.10/hook", wantErr: true},
+		{name: "cloud metadata", url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{name: "unspecified", url: "http://0.0.0.0/hook", wantErr: true},
+		{name: "carrier-grade NAT", url: "http://100.64.0.1/hook", wantErr: true},
+		{name: "IPv4-mapped loopback", url: "http://[::ffff:127.0.0.1]/hook", wantErr: true},
+		{name: "NAT64 loopback", url: "http://[64:ff9b::7f00:1]/hook", wantErr: true},
+		{name: "unique local IPv6", url: "http://[fd00::1]/hook", wantErr: true},
+		{name: "allowlisted address", allowed: []string{"127.0.0.1"}, url: "http://127.0.0.1:8080/hook"},
+		{name: "allowlisted network", allowed: []string{"10.0.0.0/8"}, url: "http://10.1.2.3/hook"},
+		{name: "allowlisted host", allowed: []string{"Receiver.Localhost"}, url: "http://receiver.localhost/hook"},
+		{name: "address outside the allowlist", allowed: []string{"127.0.0.1"}, url: "http://127.0.0.2/hook", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newWebhookGuard(tt.allowed).CheckURL(context.Background(), tt.url)
+			if tt.wantErr && !errors.Is(err, ErrWebhookURLNotAllowed) {
+				t.Errorf("CheckURL(%q) = %v, want %v", tt.url, err, ErrWebhookURLNotAllowed)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("CheckURL(%q) = %v, want nil", tt.url, err)
+			}
+		})
+	}
+}
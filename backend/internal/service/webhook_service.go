@@ -0,0 +1,454 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/config"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+	"github.com/yourusername/todogo-backend/internal/tenant"
+)
+
+const (
+	// SignatureHeader carries "t=<unix seconds>,v1=<hex HMAC-SHA256>" where
+	// the MAC covers "<t>.<raw body>" keyed with the webhook secret.
+	SignatureHeader = "X-Todogo-Signature"
+	EventHeader     = "X-Todogo-Event"
+	DeliveryHeader  = "X-Todogo-Delivery"
+
+	eventPing = "ping"
+
+	deliveryBatchSize = 20
+	deliveryLease     = 2 * time.Minute
+	baseBackoff       = 30 * time.Second
+	maxBackoff        = 6 * time.Hour
+	// maxErrorBody bounds how much of a failing response is kept in the log.
+	maxErrorBody = 1024
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("delivery not found")
+)
+
+// deliveryStore is the part of the webhook repository the delivery worker
+// needs, so that it can run against a fake in tests.
+type deliveryStore interface {
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	GetWebhookForDelivery(ctx context.Context, webhookID uuid.UUID) (*models.Webhook, error)
+	SaveAttempt(ctx context.Context, d *models.WebhookDelivery) error
+	RecordSuccess(ctx context.Context, id uuid.UUID) error
+	RecordFailure(ctx context.Context, id uuid.UUID, disableAfter int) (bool, error)
+}
+
+type WebhookService struct {
+	webhookRepo *repository.WebhookRepository
+	deliveries  deliveryStore
+	client      *http.Client
+	guard       *webhookGuard
+	cfg         config.WebhookConfig
+	wake        chan struct{}
+}
+
+// webhookPayload is the JSON body POSTed to endpoints.
+type webhookPayload struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+func NewWebhookService(webhookRepo *repository.WebhookRepository, cfg config.WebhookConfig) *WebhookService {
+	guard := newWebhookGuard(cfg.AllowedHosts)
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		deliveries:  webhookRepo,
+		guard:       guard,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: guard.Transport(cfg.Timeout),
+			// A redirect is treated as a failed delivery rather than followed
+			// with the signed payload to an unknown host.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg:  cfg,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+func (s *WebhookService) Create(ctx context.Context, req models.CreateWebhookRequest, userID uuid.UUID) (*models.Webhook, error) {
+	if err := s.guard.CheckURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	hook := &models.Webhook{
+		UserID: userID,
+		URL:    req.URL,
+		Events: req.Events,
+		Secret: "whsec_" + secret,
+	}
+
+	if err := s.webhookRepo.Create(ctx, hook); err != nil {
+		return nil, err
+	}
+
+	return hook, nil
+}
+
+func (s *WebhookService) GetAll(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	hooks, err := s.webhookRepo.GetAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		hook.Secret = ""
+	}
+	return hooks, nil
+}
+
+func (s *WebhookService) GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Webhook, error) {
+	hook, err := s.webhookRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if hook == nil {
+		return nil, ErrWebhookNotFound
+	}
+	hook.Secret = ""
+	return hook, nil
+}
+
+func (s *WebhookService) Update(ctx context.Context, id uuid.UUID, req models.UpdateWebhookRequest, userID uuid.UUID) (*models.Webhook, error) {
+	hook, err := s.webhookRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if hook == nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	if req.URL != nil {
+		if err := s.guard.CheckURL(ctx, *req.URL); err != nil {
+			return nil, err
+		}
+		hook.URL = *req.URL
+	}
+	if req.Events != nil {
+		hook.Events = req.Events
+	}
+	if req.Active != nil {
+		hook.Active = *req.Active
+		if hook.Active {
+			hook.FailureCount = 0
+			hook.DisabledAt = nil
+		}
+	}
+
+	if err := s.webhookRepo.Update(ctx, hook); err != nil {
+		return nil, err
+	}
+
+	hook.Secret = ""
+	return hook, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	if err := s.webhookRepo.Delete(ctx, id, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrWebhookNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *WebhookService) Deliveries(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]*models.WebhookDelivery, error) {
+	if _, err := s.GetByID(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.GetDeliveries(ctx, id, userID, 100)
+}
+
+// Replay queues a new delivery of a previously sent event. The event ID is
+// kept so receivers can deduplicate.
+func (s *WebhookService) Replay(ctx context.Context, id uuid.UUID, deliveryID uuid.UUID, userID uuid.UUID) (*models.WebhookDelivery, error) {
+	original, err := s.webhookRepo.GetDelivery(ctx, deliveryID, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil {
+		return nil, ErrDeliveryNotFound
+	}
+
+	d := &models.WebhookDelivery{
+		WebhookID: original.WebhookID,
+		EventID:   original.EventID,
+		EventType: original.EventType,
+		Payload:   original.Payload,
+	}
+	if err := s.webhookRepo.CreateDelivery(ctx, d); err != nil {
+		return nil, err
+	}
+
+	s.notify()
+	return d, nil
+}
+
+// Ping queues a "ping" event so users can check their endpoint.
+func (s *WebhookService) Ping(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.WebhookDelivery, error) {
+	hook, err := s.webhookRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if hook == nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	payload := webhookPayload{
+		ID:        uuid.New(),
+		Type:      eventPing,
+		CreatedAt: time.Now(),
+		Data:      map[string]interface{}{"webhook_id": hook.ID},
+	}
+
+	d, err := s.enqueue(ctx, hook, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify()
+	return d, nil
+}
+
+// OnTodoEvent queues a delivery for every active endpoint subscribed to the
+// event. Delivery itself happens in Run.
+func (s *WebhookService) OnTodoEvent(ctx context.Context, event TodoEvent) {
+	// The request may finish before the queries do.
+	ctx = context.WithoutCancel(ctx)
+
+	hooks, err := s.webhookRepo.GetSubscribed(ctx, event.UserID, string(event.Type))
+	if err != nil {
+		log.Error().Err(err).Str("event", string(event.Type)).Msg("Failed to load webhooks")
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	data := map[string]interface{}{"todo": event.Todo}
+	if event.PreviousAssigneeID != nil {
+		data["previous_assignee_id"] = event.PreviousAssigneeID
+	}
+	payload := webhookPayload{
+		ID:        event.ID,
+		Type:      string(event.Type),
+		CreatedAt: event.OccurredAt,
+		Data:      data,
+	}
+
+	for _, hook := range hooks {
+		if _, err := s.enqueue(ctx, hook, payload); err != nil {
+			log.Error().Err(err).Str("webhook_id", hook.ID.String()).Msg("Failed to queue webhook delivery")
+		}
+	}
+
+	s.notify()
+}
+
+func (s *WebhookService) enqueue(ctx context.Context, hook *models.Webhook, payload webhookPayload) (*models.WebhookDelivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &models.WebhookDelivery{
+		WebhookID: hook.ID,
+		EventID:   payload.ID,
+		EventType: payload.Type,
+		Payload:   body,
+	}
+	if err := s.webhookRepo.CreateDelivery(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (s *WebhookService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers queued webhooks until ctx is cancelled. It polls so that
+// retries become due and deliveries queued by other instances are picked up.
+func (s *WebhookService) Run(ctx context.Context) {
+	ctx = tenant.AllWorkspaces(ctx)
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.deliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *WebhookService) deliverDue(ctx context.Context) {
+	for {
+		deliveries, err := s.deliveries.ClaimDueDeliveries(ctx, deliveryBatchSize, deliveryLease)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error().Err(err).Msg("Failed to claim webhook deliveries")
+			}
+			return
+		}
+
+		for _, d := range deliveries {
+			s.attempt(ctx, d)
+		}
+
+		if len(deliveries) < deliveryBatchSize {
+			return
+		}
+	}
+}
+
+func (s *WebhookService) attempt(ctx context.Context, d *models.WebhookDelivery) {
+	hook, err := s.deliveries.GetWebhookForDelivery(ctx, d.WebhookID)
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", d.ID.String()).Msg("Failed to load webhook")
+		return
+	}
+
+	d.Attempts++
+
+	if hook == nil || !hook.Active {
+		msg := "endpoint disabled"
+		d.Status = models.DeliveryFailed
+		d.LastError = &msg
+		d.NextAttemptAt = nil
+		s.saveAttempt(ctx, d)
+		return
+	}
+
+	statusCode, sendErr := s.send(ctx, hook, d)
+	if statusCode != 0 {
+		d.LastStatusCode = &statusCode
+	}
+
+	if sendErr == nil {
+		now := time.Now()
+		d.Status = models.DeliverySucceeded
+		d.DeliveredAt = &now
+		d.NextAttemptAt = nil
+		d.LastError = nil
+		s.saveAttempt(ctx, d)
+		if hook.FailureCount > 0 {
+			if err := s.deliveries.RecordSuccess(ctx, hook.ID); err != nil {
+				log.Error().Err(err).Str("webhook_id", hook.ID.String()).Msg("Failed to reset webhook failures")
+			}
+		}
+		return
+	}
+
+	msg := sendErr.Error()
+	d.LastError = &msg
+	if d.Attempts >= s.cfg.MaxAttempts {
+		d.Status = models.DeliveryFailed
+		d.NextAttemptAt = nil
+	} else {
+		next := time.Now().Add(backoff(d.Attempts))
+		d.NextAttemptAt = &next
+	}
+	s.saveAttempt(ctx, d)
+
+	disabled, err := s.deliveries.RecordFailure(ctx, hook.ID, s.cfg.DisableAfter)
+	if err != nil {
+		log.Error().Err(err).Str("webhook_id", hook.ID.String()).Msg("Failed to record webhook failure")
+	} else if disabled {
+		log.Warn().Str("webhook_id", hook.ID.String()).Str("url", hook.URL).Msg("Webhook disabled after repeated failures")
+	}
+}
+
+func (s *WebhookService) saveAttempt(ctx context.Context, d *models.WebhookDelivery) {
+	if err := s.deliveries.SaveAttempt(ctx, d); err != nil {
+		log.Error().Err(err).Str("delivery_id", d.ID.String()).Msg("Failed to save webhook delivery")
+	}
+}
+
+// send POSTs the signed payload and returns the response status code.
+func (s *WebhookService) send(ctx context.Context, hook *models.Webhook, d *models.WebhookDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Todogo-Webhooks/1.0")
+	req.Header.Set(EventHeader, d.EventType)
+	req.Header.Set(DeliveryHeader, d.ID.String())
+	req.Header.Set(SignatureHeader, fmt.Sprintf("t=%d,v1=%s", timestamp, SignPayload(hook.Secret, timestamp, d.Payload)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, fmt.Errorf("endpoint responded with %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+	return resp.StatusCode, nil
+}
+
+// SignPayload computes the v1 signature receivers compare against.
+func SignPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff doubles the retry delay with every failed attempt.
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/todogo-backend/internal/config"
+	"github.com/yourusername/todogo-backend/internal/models"
+)
+
+func TestSignPayload(t *testing.T) {
+	// Expected values from: printf '<t>.<body>' | openssl dgst -sha256 -hmac <secret>
+	tests := []struct {
+		secret    string
+		timestamp int64
+		body      string
+		want      string
+	}{
+		{"whsec_test", 1700000000, `{"type":"ping"}`, "bc08c591847b765241711bcbe7067e3869a219e424d3fdd9d00b3b6f915baf97"},
+		{"s3cret", 0, ``, "f8f098a84b2e4238206d750bcb900ff9df6f839db0660fcc115201787a3bb832"},
+		{"another-secret", 1712345678, `{"id":"b4c1","data":{"title":"Ship"}}`, "7e460f7a8a5da2799dff05eea66aa79a482bea4eb203e41039536466c221e6ca"},
+	}
+
+	for _, tt := range tests {
+		if got := SignPayload(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+			t.Errorf("SignPayload(%q, %d, %q) = %s, want %s", tt.secret, tt.timestamp, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, maxBackoff},
+		{50, maxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// fakeDeliveryStore keeps webhooks and deliveries in memory and mirrors the
+// repository's claiming and failure counting.
+type fakeDeliveryStore struct {
+	hooks      map[uuid.UUID]*models.Webhook
+	deliveries []*models.WebhookDelivery
+}
+
+func (f *fakeDeliveryStore) ClaimDueDeliveries(_ context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	now := time.Now()
+	claimed := []*models.WebhookDelivery{}
+	for _, d := range f.deliveries {
+		if len(claimed) == limit {
+			break
+		}
+		if d.Status == models.DeliveryPending && d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+			next := now.Add(lease)
+			d.NextAttemptAt = &next
+			claimed = append(claimed, d)
+		}
+	}
+	return claimed, nil
+}
+
+func (f *fakeDeliveryStore) GetWebhookForDelivery(_ context.Context, webhookID uuid.UUID) (*models.Webhook, error) {
+	hook, ok := f.hooks[webhookID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *hook
+	return &copied, nil
+}
+
+func (f *fakeDeliveryStore) SaveAttempt(context.Context, *models.WebhookDelivery) error {
+	return nil
+}
+
+func (f *fakeDeliveryStore) RecordSuccess(_ context.Context, id uuid.UUID) error {
+	f.hooks[id].FailureCount = 0
+	return nil
+}
+
+func (f *fakeDeliveryStore) RecordFailure(_ context.Context, id uuid.UUID, disableAfter int) (bool, error) {
+	hook := f.hooks[id]
+	hook.FailureCount++
+	if hook.FailureCount >= disableAfter && hook.Active {
+		hook.Active = false
+		return true, nil
+	}
+	return false, nil
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	tests := []struct {
+		name         string
+		responses    []int // the last one repeats
+		maxAttempts  int
+		disableAfter int
+		rounds       int
+
+		wantStatus   models.WebhookDeliveryStatus
+		wantAttempts int
+		wantRequests int
+		wantCode     int
+		wantError    string
+		wantActive   bool
+		wantFailures int
+	}{
+		{
+			name:      "delivered on the first attempt",
+			responses: []int{http.StatusNoContent}, maxAttempts: 3, disableAfter: 5, rounds: 1,
+			wantStatus: models.DeliverySucceeded, wantAttempts: 1, wantRequests: 1, wantCode: http.StatusNoContent,
+			wantActive: true,
+		},
+		{
+			name:      "retried after a server error",
+			responses: []int{http.StatusInternalServerError, http.StatusOK}, maxAttempts: 3, disableAfter: 5, rounds: 2,
+			wantStatus: models.DeliverySucceeded, wantAttempts: 2, wantRequests: 2, wantCode: http.StatusOK,
+			wantActive: true, wantFailures: 0,
+		},
+		{
+			name:      "failed after the last attempt",
+			responses: []int{http.StatusServiceUnavailable}, maxAttempts: 3, disableAfter: 10, rounds: 4,
+			wantStatus: models.DeliveryFailed, wantAttempts: 3, wantRequests: 3, wantCode: http.StatusServiceUnavailable,
+			wantError: "endpoint responded with 503", wantActive: true, wantFailures: 3,
+		},
+		{
+			name:      "redirect is not followed",
+			responses: []int{http.StatusFound}, maxAttempts: 1, disableAfter: 5, rounds: 1,
+			wantStatus: models.DeliveryFailed, wantAttempts: 1, wantRequests: 1, wantCode: http.StatusFound,
+			wantError: "endpoint responded with 302", wantActive: true, wantFailures: 1,
+		},
+		{
+			name:      "endpoint disabled after repeated failures",
+			responses: []int{http.StatusInternalServerError}, maxAttempts: 5, disableAfter: 2, rounds: 3,
+			wantStatus: models.DeliveryFailed, wantAttempts: 3, wantRequests: 2, wantCode: http.StatusInternalServerError,
+			wantError: "endpoint disabled", wantActive: false, wantFailures: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := &models.Webhook{ID: uuid.New(), Secret: "whsec_test", Active: true}
+			payload := []byte(`{"type":"todo.created"}`)
+
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := checkSignature(r, hook.Secret, payload); err != nil {
+					t.Errorf("request %d: %v", requests+1, err)
+				}
+				code := tt.responses[min(requests, len(tt.responses)-1)]
+				requests++
+				if code == http.StatusFound {
+					w.Header().Set("Location", "http://example.com/")
+				}
+				w.WriteHeader(code)
+			}))
+			defer server.Close()
+			hook.URL = server.URL + "/hook"
+
+			now := time.Now()
+			d := &models.WebhookDelivery{
+				ID:            uuid.New(),
+				WebhookID:     hook.ID,
+				EventType:     "todo.created",
+				Payload:       payload,
+				Status:        models.DeliveryPending,
+				NextAttemptAt: &now,
+			}
+			store := &fakeDeliveryStore{hooks: map[uuid.UUID]*models.Webhook{hook.ID: hook}, deliveries: []*models.WebhookDelivery{d}}
+
+			s := NewWebhookService(nil, config.WebhookConfig{
+				MaxAttempts:  tt.maxAttempts,
+				DisableAfter: tt.disableAfter,
+				Timeout:      5 * time.Second,
+				AllowedHosts: []string{"127.0.0.1"},
+			})
+			s.deliveries = store
+
+			for round := 1; round <= tt.rounds; round++ {
+				s.deliverDue(context.Background())
+
+				if d.Status != models.DeliveryPending {
+					continue
+				}
+				// A retry waits for the backoff of the attempts so far
+				wait := time.Until(*d.NextAttemptAt)
+				if want := backoff(d.Attempts); wait > want || wait < want-5*time.Second {
+					t.Errorf("round %d: next attempt in %v, want %v", round, wait, want)
+				}
+				past := time.Now().Add(-time.Second)
+				d.NextAttemptAt = &past
+			}
+
+			if d.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", d.Status, tt.wantStatus)
+			}
+			if d.Attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", d.Attempts, tt.wantAttempts)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+			if d.LastStatusCode == nil || *d.LastStatusCode != tt.wantCode {
+				t.Errorf("last status code = %v, want %d", d.LastStatusCode, tt.wantCode)
+			}
+			switch {
+			case tt.wantError == "" && d.LastError != nil:
+				t.Errorf("last error = %q, want none", *d.LastError)
+			case tt.wantError != "" && (d.LastError == nil || !strings.Contains(*d.LastError, tt.wantError)):
+				t.Errorf("last error = %v, want it to contain %q", d.LastError, tt.wantError)
+			}
+			if d.Status != models.DeliveryPending && d.NextAttemptAt != nil {
+				t.Errorf("next attempt = %v, want none once settled", d.NextAttemptAt)
+			}
+			if (d.Status == models.DeliverySucceeded) != (d.DeliveredAt != nil) {
+				t.Errorf("delivered at = %v for status %s", d.DeliveredAt, d.Status)
+			}
+			if hook.Active != tt.wantActive {
+				t.Errorf("active = %t, want %t", hook.Active, tt.wantActive)
+			}
+			if hook.FailureCount != tt.wantFailures {
+				t.Errorf("failure count = %d, want %d", hook.FailureCount, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestWebhookDeliveryBlocksLoopback(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	s := NewWebhookService(nil, config.WebhookConfig{Timeout: 5 * time.Second})
+	hook := &models.Webhook{ID: uuid.New(), URL: server.URL, Secret: "whsec_test", Active: true}
+	d := &models.WebhookDelivery{ID: uuid.New(), WebhookID: hook.ID, EventType: eventPing, Payload: []byte(`{}`)}
+
+	if _, err := s.send(context.Background(), hook, d); !errors.Is(err, ErrWebhookURLNotAllowed) {
+		t.Errorf("send to %s: err = %v, want %v", server.URL, err, ErrWebhookURLNotAllowed)
+	}
+	if requests != 0 {
+		t.Errorf("requests = %d, want 0", requests)
+	}
+}
+
+// checkSignature verifies a delivery the way a receiver would.
+func checkSignature(r *http.Request, secret string, want []byte) error {
+	var timestamp int64
+	var signature string
+	if _, err := fmt.Sscanf(r.Header.Get(SignatureHeader), "t=%d,v1=%s", &timestamp, &signature); err != nil {
+		return fmt.Errorf("malformed %s %q: %v", SignatureHeader, r.Header.Get(SignatureHeader), err)
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if string(body) != string(want) {
+		return fmt.Errorf("body = %q, want %q", body, want)
+	}
+	if signature != SignPayload(secret, timestamp, want) {
+		return fmt.Errorf("signature %s does not match the body", signature)
+	}
+	if r.Header.Get(EventHeader) == "" || r.Header.Get(DeliveryHeader) == "" {
+		return errors.New("event or delivery header missing")
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"net/url"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/yourusername/todogo-backend/internal/mailer"
+	"github.com/yourusername/todogo-backend/internal/models"
+	"github.com/yourusername/todogo-backend/internal/repository"
+)
+
+// invitationTTL is how long an invitation can be accepted.
+const invitationTTL = 7 * 24 * time.Hour
+
+var (
+	ErrWorkspaceNotFound     = errors.New("workspace not found")
+	ErrNotWorkspaceOwner     = errors.New("only workspace owners can do this")
+	ErrPersonalWorkspace     = errors.New("personal workspaces cannot be changed or shared")
+	ErrMemberNotFound        = errors.New("member not found")
+	ErrOwnerCannotLeave      = errors.New("owners cannot leave their workspace")
+	ErrInvitationNotFound    = errors.New("invitation is invalid or expired")
+	ErrInvalidWorkspaceScope = errors.New("you are not a member of the requested workspace")
+)
+
+//go:embed templates/invitation.html templates/invitation.txt
+var invitationTemplates embed.FS
+
+var (
+	invitationHTML = htmltemplate.Must(htmltemplate.ParseFS(invitationTemplates, "templates/invitation.html"))
+	invitationText = texttemplate.Must(texttemplate.ParseFS(invitationTemplates, "templates/invitation.txt"))
+)
+
+type invitationData struct {
+	Subject   string
+	Inviter   string
+	Workspace string
+	AcceptURL string
+	Expires   string
+}
+
+type WorkspaceService struct {
+	workspaceRepo *repository.WorkspaceRepository
+	userRepo      *repository.UserRepository
+	mailer        mailer.Mailer
+	appURL        string
+}
+
+func NewWorkspaceService(workspaceRepo *repository.WorkspaceRepository, userRepo *repository.UserRepository, m mailer.Mailer, appURL string) *WorkspaceService {
+	return &WorkspaceService{
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		mailer:        m,
+		appURL:        strings.TrimRight(appURL, "/"),
+	}
+}
+
+// Resolve returns the workspace a request of the user works in: the
+// requested one, which the user must be a member of, or else their personal
+// workspace.
+func (s *WorkspaceService) Resolve(ctx context.Context, userID uuid.UUID, requested *uuid.UUID) (uuid.UUID, error) {
+	if requested != nil {
+		member, err := s.workspaceRepo.IsMember(ctx, *requested, userID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if !member {
+			return uuid.Nil, ErrInvalidWorkspaceScope
+		}
+		return *requested, nil
+	}
+
+	ws, err := s.workspaceRepo.GetPersonal(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if ws == nil {
+		return uuid.Nil, ErrWorkspaceNotFound
+	}
+	return ws.ID, nil
+}
+
+// IsMember reports whether the user belongs to the workspace.
+func (s *WorkspaceService) IsMember(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	return s.workspaceRepo.IsMember(ctx, id, userID)
+}
+
+func (s *WorkspaceService) Create(ctx context.Context, req models.CreateWorkspaceRequest, userID uuid.UUID) (*models.Workspace, error) {
+	ws := &models.Workspace{Name: req.Name}
+	if err := s.workspaceRepo.Create(ctx, ws, userID); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+func (s *WorkspaceService) GetAll(ctx context.Context, userID uuid.UUID) ([]*models.Workspace, error) {
+	return s.workspaceRepo.ListForUser(ctx, userID)
+}
+
+func (s *WorkspaceService) GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Workspace, error) {
+	ws, err := s.workspaceRepo.GetForUser(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if ws == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+	return ws, nil
+}
+
+// owned returns the shared workspace if the user owns it.
+func (s *WorkspaceService) owned(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Workspace, error) {
+	ws, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if ws.Personal {
+		return nil, ErrPersonalWorkspace
+	}
+	if ws.Role != models.WorkspaceRoleOwner {
+		return nil, ErrNotWorkspaceOwner
+	}
+	return ws, nil
+}
+
+func (s *WorkspaceService) Update(ctx context.Context, id uuid.UUID, req models.UpdateWorkspaceRequest, userID uuid.UUID) (*models.Workspace, error) {
+	if _, err := s.owned(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	if err := s.workspaceRepo.Rename(ctx, id, req.Name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+	return s.GetByID(ctx, id, userID)
+}
+
+// Delete removes a shared workspace with all of its todos.
+func (s *WorkspaceService) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.owned(ctx, id, userID); err != nil {
+		return err
+	}
+	if err := s.workspaceRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrWorkspaceNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *WorkspaceService) Members(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]*models.WorkspaceMember, error) {
+	if _, err := s.GetByID(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	return s.workspaceRepo.Members(ctx, id)
+}
+
+// RemoveMember lets owners remove members and members leave. Owners cannot
+// be removed, so a shared workspace always keeps its owner.
+func (s *WorkspaceService) RemoveMember(ctx context.Context, id uuid.UUID, memberID uuid.UUID, userID uuid.UUID) error {
+	ws, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if ws.Personal {
+		return ErrPersonalWorkspace
+	}
+	if memberID != userID && ws.Role != models.WorkspaceRoleOwner {
+		return ErrNotWorkspaceOwner
+	}
+
+	member, err := s.workspaceRepo.GetForUser(ctx, id, memberID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return ErrMemberNotFound
+	}
+	if member.Role == models.WorkspaceRoleOwner {
+		return ErrOwnerCannotLeave
+	}
+
+	if err := s.workspaceRepo.RemoveMember(ctx, id, memberID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMemberNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Invite creates an invitation and emails its link. The token is returned
+// once so that it can be shared another way when mail is not delivered.
+func (s *WorkspaceService) Invite(ctx context.Context, id uuid.UUID, req models.CreateInvitationRequest, userID uuid.UUID) (*models.WorkspaceInvitation, error) {
+	ws, err := s.owned(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &models.WorkspaceInvitation{
+		WorkspaceID: id,
+		Email:       strings.TrimSpace(req.Email),
+		TokenHash:   hashSecret(token),
+		InvitedBy:   &userID,
+		ExpiresAt:   time.Now().Add(invitationTTL).UTC(),
+	}
+	if err := s.workspaceRepo.CreateInvitation(ctx, inv); err != nil {
+		return nil, err
+	}
+	inv.Token = token
+
+	// The invitation stands even when the email cannot be sent
+	if err := s.sendInvitation(ctx, ws, inv, userID); err != nil {
+		log.Warn().Err(err).Str("workspace_id", id.String()).Msg("Failed to send workspace invitation")
+	}
+
+	return inv, nil
+}
+
+func (s *WorkspaceService) Invitations(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]*models.WorkspaceInvitation, error) {
+	if _, err := s.owned(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	return s.workspaceRepo.GetInvitations(ctx, id)
+}
+
+func (s *WorkspaceService) RevokeInvitation(ctx context.Context, id uuid.UUID, invitationID uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.owned(ctx, id, userID); err != nil {
+		return err
+	}
+	if err := s.workspaceRepo.DeleteInvitation(ctx, invitationID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvitationNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Accept adds the user to the workspace of the invitation.
+func (s *WorkspaceService) Accept(ctx context.Context, token string, userID uuid.UUID) (*models.Workspace, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	inv, err := s.workspaceRepo.AcceptInvitation(ctx, hashSecret(token), user)
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil {
+		return nil, ErrInvitationNotFound
+	}
+
+	return s.GetByID(ctx, inv.WorkspaceID, userID)
+}
+
+func (s *WorkspaceService) sendInvitation(ctx context.Context, ws *models.Workspace, inv *models.WorkspaceInvitation, inviterID uuid.UUID) error {
+	inviter, err := s.userRepo.GetByID(ctx, inviterID)
+	if err != nil {
+		return err
+	}
+
+	data := invitationData{
+		Subject:   fmt.Sprintf("You are invited to %s on Todogo", ws.Name),
+		Inviter:   "Someone",
+		Workspace: ws.Name,
+		AcceptURL: s.appURL + "/invitations/" + url.PathEscape(inv.Token),
+		Expires:   inv.ExpiresAt.Format("Mon, Jan 2 2006 15:04 MST"),
+	}
+	if inviter != nil {
+		data.Inviter = inviter.Name
+	}
+
+	var html, text bytes.Buffer
+	if err := invitationHTML.Execute(&html, data); err != nil {
+		return err
+	}
+	if err := invitationText.Execute(&text, data); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      inv.Email,
+		Subject: data.Subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	})
+}
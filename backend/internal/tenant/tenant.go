@@ -0,0 +1,38 @@
+// Package tenant carries the workspace a request works in, so that
+// repositories can scope their queries without every caller passing it on.
+// A context without a workspace sees no workspace data at all, unless it was
+// marked with AllWorkspaces.
+package tenant
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type workspaceKey struct{}
+
+type allWorkspacesKey struct{}
+
+// WithWorkspace returns a context scoped to the workspace.
+func WithWorkspace(ctx context.Context, workspaceID uuid.UUID) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspaceID)
+}
+
+// AllWorkspaces returns a context that sees the data of all workspaces, for
+// background jobs and the few features that deliberately span workspaces.
+func AllWorkspaces(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allWorkspacesKey{}, true)
+}
+
+// WorkspaceID returns the workspace of ctx.
+func WorkspaceID(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(workspaceKey{}).(uuid.UUID)
+	return id, ok
+}
+
+// IsAllWorkspaces reports whether ctx was marked with AllWorkspaces.
+func IsAllWorkspaces(ctx context.Context) bool {
+	all, _ := ctx.Value(allWorkspacesKey{}).(bool)
+	return all
+}
@@ -0,0 +1,3 @@
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS ical_uid VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_todos_user_ical_uid ON todos(user_id, ical_uid) WHERE ical_uid IS NOT NULL;
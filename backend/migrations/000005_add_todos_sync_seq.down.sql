@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS todos_record_tombstone ON todos;
+DROP FUNCTION IF EXISTS todos_record_tombstone();
+DROP TRIGGER IF EXISTS todos_bump_sync_seq ON todos;
+DROP FUNCTION IF EXISTS todos_bump_sync_seq();
+DROP TABLE IF EXISTS todo_tombstones;
+DROP INDEX IF EXISTS idx_todos_user_sync_seq;
+ALTER TABLE todos DROP COLUMN IF EXISTS sync_seq;
+DROP SEQUENCE IF EXISTS todo_sync_seq;
@@ -0,0 +1,43 @@
+-- Every insert, update and delete of a todo draws a number from one global
+-- sequence so that sync clients (CalDAV sync-collection, offline clients)
+-- can ask for everything that changed after a given point.
+CREATE SEQUENCE IF NOT EXISTS todo_sync_seq;
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS sync_seq BIGINT NOT NULL DEFAULT nextval('todo_sync_seq');
+
+CREATE INDEX idx_todos_user_sync_seq ON todos(user_id, sync_seq);
+
+CREATE TABLE IF NOT EXISTS todo_tombstones (
+    todo_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    ical_uid VARCHAR(255),
+    deleted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    sync_seq BIGINT NOT NULL DEFAULT nextval('todo_sync_seq')
+);
+
+CREATE INDEX idx_todo_tombstones_user_sync_seq ON todo_tombstones(user_id, sync_seq);
+
+CREATE OR REPLACE FUNCTION todos_bump_sync_seq() RETURNS trigger AS $$
+BEGIN
+    NEW.sync_seq := nextval('todo_sync_seq');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todos_bump_sync_seq
+    BEFORE UPDATE ON todos
+    FOR EACH ROW EXECUTE FUNCTION todos_bump_sync_seq();
+
+CREATE OR REPLACE FUNCTION todos_record_tombstone() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO todo_tombstones (todo_id, user_id, ical_uid)
+    VALUES (OLD.id, OLD.user_id, OLD.ical_uid)
+    ON CONFLICT (todo_id) DO UPDATE
+        SET deleted_at = NOW(), sync_seq = nextval('todo_sync_seq');
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todos_record_tombstone
+    AFTER DELETE ON todos
+    FOR EACH ROW EXECUTE FUNCTION todos_record_tombstone();
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS todos_clear_tombstone ON todos;
+DROP FUNCTION IF EXISTS todos_clear_tombstone();
+DROP TRIGGER IF EXISTS todos_track_versions ON todos;
+DROP FUNCTION IF EXISTS todos_track_versions();
+ALTER TABLE todos DROP COLUMN IF EXISTS field_versions;
+ALTER TABLE todos DROP COLUMN IF EXISTS version;
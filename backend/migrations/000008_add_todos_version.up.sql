@@ -0,0 +1,38 @@
+-- version counts the writes of a todo; field_versions records, per editable
+-- field, the version that last changed it. Offline clients send the version
+-- their edit was based on, which lets the server merge concurrent edits of
+-- different fields.
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS field_versions JSONB NOT NULL DEFAULT '{}';
+
+CREATE OR REPLACE FUNCTION todos_track_versions() RETURNS trigger AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    NEW.field_versions := OLD.field_versions || jsonb_strip_nulls(jsonb_build_object(
+        'title', CASE WHEN NEW.title IS DISTINCT FROM OLD.title THEN NEW.version END,
+        'description', CASE WHEN NEW.description IS DISTINCT FROM OLD.description THEN NEW.version END,
+        'priority', CASE WHEN NEW.priority IS DISTINCT FROM OLD.priority THEN NEW.version END,
+        'due_date', CASE WHEN NEW.due_date IS DISTINCT FROM OLD.due_date THEN NEW.version END,
+        'tags', CASE WHEN NEW.tags IS DISTINCT FROM OLD.tags THEN NEW.version END,
+        'completed', CASE WHEN NEW.completed IS DISTINCT FROM OLD.completed THEN NEW.version END
+    ));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todos_track_versions
+    BEFORE UPDATE ON todos
+    FOR EACH ROW EXECUTE FUNCTION todos_track_versions();
+
+-- A todo recreated under its old ID (e.g. by an offline client) is no longer
+-- deleted.
+CREATE OR REPLACE FUNCTION todos_clear_tombstone() RETURNS trigger AS $$
+BEGIN
+    DELETE FROM todo_tombstones WHERE todo_id = NEW.id AND user_id = NEW.user_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todos_clear_tombstone
+    AFTER INSERT ON todos
+    FOR EACH ROW EXECUTE FUNCTION todos_clear_tombstone();
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS views (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    filter JSONB NOT NULL DEFAULT '{}',
+    sort VARCHAR(20) NOT NULL DEFAULT 'created_at',
+    direction VARCHAR(4) NOT NULL DEFAULT 'desc',
+    group_by VARCHAR(20) NOT NULL DEFAULT 'none',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_views_user_id ON views(user_id);
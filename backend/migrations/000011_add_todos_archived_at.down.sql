@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS archive_rules;
+
+DROP INDEX IF EXISTS idx_todos_archivable;
+DROP INDEX IF EXISTS idx_todos_user_archived;
+DROP INDEX IF EXISTS idx_todos_user_active;
+
+ALTER TABLE todos DROP COLUMN IF EXISTS archived_at;
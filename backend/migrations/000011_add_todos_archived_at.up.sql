@@ -0,0 +1,16 @@
+-- Archived todos stay in the todos table so that their IDs, sync sequence
+-- and events keep working; partial indexes keep the lookups of active todos
+-- independent of how many archived ones pile up.
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+CREATE INDEX idx_todos_user_active ON todos(user_id, created_at DESC) WHERE archived_at IS NULL;
+CREATE INDEX idx_todos_user_archived ON todos(user_id, archived_at DESC) WHERE archived_at IS NOT NULL;
+CREATE INDEX idx_todos_archivable ON todos(user_id, completed_at) WHERE completed AND archived_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS archive_rules (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    completed_after_days INTEGER NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
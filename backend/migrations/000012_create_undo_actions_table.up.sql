@@ -0,0 +1,13 @@
+-- Short-lived undo tokens. entries holds, per changed todo, the row as it
+-- was before the action (to_jsonb of the todos row) and the version the
+-- action left it at, so that an undo can refuse to clobber later writes.
+CREATE TABLE IF NOT EXISTS undo_actions (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action VARCHAR(50) NOT NULL,
+    entries JSONB NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_undo_actions_expires_at ON undo_actions(expires_at);
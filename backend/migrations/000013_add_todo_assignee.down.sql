@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS inbox_items;
+
+DROP INDEX IF EXISTS idx_todos_assignee;
+
+ALTER TABLE todos DROP COLUMN IF EXISTS assignee_id;
@@ -0,0 +1,18 @@
+-- The assignee works on a todo that stays owned by its creator. Removing the
+-- assignee's account unassigns their todos.
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS assignee_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_todos_assignee ON todos(assignee_id, created_at DESC) WHERE assignee_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS inbox_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_inbox_items_user_created ON inbox_items(user_id, created_at DESC);
+CREATE INDEX idx_inbox_items_user_unread ON inbox_items(user_id) WHERE read_at IS NULL;
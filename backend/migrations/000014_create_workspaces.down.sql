@@ -0,0 +1,20 @@
+ALTER TABLE calendar_feeds DROP COLUMN IF EXISTS workspace_id;
+
+CREATE OR REPLACE FUNCTION todos_record_tombstone() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO todo_tombstones (todo_id, user_id, ical_uid)
+    VALUES (OLD.id, OLD.user_id, OLD.ical_uid)
+    ON CONFLICT (todo_id) DO UPDATE
+        SET deleted_at = NOW(), sync_seq = nextval('todo_sync_seq');
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE todo_tombstones DROP COLUMN IF EXISTS workspace_id;
+
+DROP INDEX IF EXISTS idx_todos_workspace_user;
+ALTER TABLE todos DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_invitations;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
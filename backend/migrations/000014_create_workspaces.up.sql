@@ -0,0 +1,71 @@
+-- Workspaces partition todos between teams sharing a deployment. Every user
+-- has a personal workspace, removed together with the user.
+CREATE TABLE IF NOT EXISTS workspaces (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    personal_user_id UUID UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    joined_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX idx_workspace_members_user ON workspace_members(user_id);
+
+CREATE TABLE IF NOT EXISTS workspace_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_workspace_invitations_workspace ON workspace_invitations(workspace_id);
+
+-- Move existing users and their todos into personal workspaces
+INSERT INTO workspaces (name, personal_user_id, created_by)
+SELECT 'Personal', id, id FROM users
+ON CONFLICT (personal_user_id) DO NOTHING;
+
+INSERT INTO workspace_members (workspace_id, user_id, role)
+SELECT id, personal_user_id, 'owner' FROM workspaces WHERE personal_user_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+UPDATE todos t SET workspace_id = w.id
+FROM workspaces w
+WHERE w.personal_user_id = t.user_id AND t.workspace_id IS NULL;
+ALTER TABLE todos ALTER COLUMN workspace_id SET NOT NULL;
+
+CREATE INDEX idx_todos_workspace_user ON todos(workspace_id, user_id, created_at DESC);
+
+ALTER TABLE todo_tombstones ADD COLUMN IF NOT EXISTS workspace_id UUID;
+UPDATE todo_tombstones tt SET workspace_id = w.id
+FROM workspaces w
+WHERE w.personal_user_id = tt.user_id AND tt.workspace_id IS NULL;
+
+CREATE OR REPLACE FUNCTION todos_record_tombstone() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO todo_tombstones (todo_id, user_id, ical_uid, workspace_id)
+    VALUES (OLD.id, OLD.user_id, OLD.ical_uid, OLD.workspace_id)
+    ON CONFLICT (todo_id) DO UPDATE
+        SET deleted_at = NOW(), sync_seq = nextval('todo_sync_seq'), workspace_id = EXCLUDED.workspace_id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE calendar_feeds ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+UPDATE calendar_feeds f SET workspace_id = w.id
+FROM workspaces w
+WHERE w.personal_user_id = f.user_id AND f.workspace_id IS NULL;
+ALTER TABLE calendar_feeds ALTER COLUMN workspace_id SET NOT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE undo_actions DROP COLUMN IF EXISTS workspace_id;
+
+DROP INDEX IF EXISTS idx_views_workspace_user;
+ALTER TABLE views DROP COLUMN IF EXISTS workspace_id;
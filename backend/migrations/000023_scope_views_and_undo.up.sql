@@ -0,0 +1,14 @@
+-- Saved views and undo tokens belong to the workspace they were created in.
+-- Existing views move into their owner's personal workspace; pending undo
+-- tokens expire within minutes and are dropped.
+ALTER TABLE views ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+UPDATE views v SET workspace_id = w.id
+FROM workspaces w
+WHERE w.personal_user_id = v.user_id AND v.workspace_id IS NULL;
+ALTER TABLE views ALTER COLUMN workspace_id SET NOT NULL;
+
+CREATE INDEX idx_views_workspace_user ON views(workspace_id, user_id);
+
+ALTER TABLE undo_actions ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+DELETE FROM undo_actions WHERE workspace_id IS NULL;
+ALTER TABLE undo_actions ALTER COLUMN workspace_id SET NOT NULL;
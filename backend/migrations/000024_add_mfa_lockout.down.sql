@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_locked_until;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_failures;
@@ -0,0 +1,4 @@
+-- Wrong two-factor codes counted per user across challenges. Reaching the
+-- limit sets mfa_locked_until; a success clears both.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_locked_until TIMESTAMP;
@@ -0,0 +1,25 @@
+CREATE OR REPLACE FUNCTION todos_record_tombstone() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO todo_tombstones (todo_id, user_id, ical_uid, workspace_id)
+    VALUES (OLD.id, OLD.user_id, OLD.ical_uid, OLD.workspace_id)
+    ON CONFLICT (todo_id) DO UPDATE
+        SET deleted_at = NOW(), sync_seq = nextval('todo_sync_seq'), workspace_id = EXCLUDED.workspace_id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS todos_bump_sync_seq ON todos;
+CREATE OR REPLACE FUNCTION todos_bump_sync_seq() RETURNS trigger AS $$
+BEGIN
+    NEW.sync_seq := nextval('todo_sync_seq');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER todos_bump_sync_seq
+    BEFORE UPDATE ON todos
+    FOR EACH ROW EXECUTE FUNCTION todos_bump_sync_seq();
+
+DROP INDEX IF EXISTS idx_todo_tombstones_user_sync_xid;
+ALTER TABLE todo_tombstones DROP COLUMN IF EXISTS sync_xid;
+DROP INDEX IF EXISTS idx_todos_user_sync_xid;
+ALTER TABLE todos DROP COLUMN IF EXISTS sync_xid;
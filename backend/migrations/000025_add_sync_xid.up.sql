@@ -0,0 +1,34 @@
+-- The transaction that last wrote each todo and tombstone. Sequence numbers
+-- are drawn before commit, so a writer can commit after one with a higher
+-- number; sync positions are therefore transaction IDs below which every
+-- writer has finished.
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS sync_xid xid8 NOT NULL DEFAULT pg_current_xact_id();
+CREATE INDEX idx_todos_user_sync_xid ON todos(user_id, sync_xid);
+
+ALTER TABLE todo_tombstones ADD COLUMN IF NOT EXISTS sync_xid xid8 NOT NULL DEFAULT pg_current_xact_id();
+CREATE INDEX idx_todo_tombstones_user_sync_xid ON todo_tombstones(user_id, sync_xid);
+
+CREATE OR REPLACE FUNCTION todos_bump_sync_seq() RETURNS trigger AS $$
+BEGIN
+    NEW.sync_seq := nextval('todo_sync_seq');
+    NEW.sync_xid := pg_current_xact_id();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Undo reinserts deleted rows with their old columns
+DROP TRIGGER IF EXISTS todos_bump_sync_seq ON todos;
+CREATE TRIGGER todos_bump_sync_seq
+    BEFORE INSERT OR UPDATE ON todos
+    FOR EACH ROW EXECUTE FUNCTION todos_bump_sync_seq();
+
+CREATE OR REPLACE FUNCTION todos_record_tombstone() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO todo_tombstones (todo_id, user_id, ical_uid, workspace_id)
+    VALUES (OLD.id, OLD.user_id, OLD.ical_uid, OLD.workspace_id)
+    ON CONFLICT (todo_id) DO UPDATE
+        SET deleted_at = NOW(), sync_seq = nextval('todo_sync_seq'), sync_xid = pg_current_xact_id(),
+            workspace_id = EXCLUDED.workspace_id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
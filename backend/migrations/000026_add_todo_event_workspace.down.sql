@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_todo_events_user_workspace;
+ALTER TABLE todo_events DROP COLUMN IF EXISTS workspace_id;
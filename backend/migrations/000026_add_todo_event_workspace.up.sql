@@ -0,0 +1,11 @@
+ALTER TABLE todo_events ADD COLUMN IF NOT EXISTS workspace_id UUID;
+
+UPDATE todo_events SET workspace_id = (payload->'todo'->>'workspace_id')::uuid
+WHERE workspace_id IS NULL AND payload->'todo'->>'workspace_id' IS NOT NULL;
+
+-- Events are only kept for a day; ones without a todo cannot be placed.
+DELETE FROM todo_events WHERE workspace_id IS NULL;
+
+ALTER TABLE todo_events ALTER COLUMN workspace_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_todo_events_user_workspace ON todo_events(user_id, workspace_id, id);
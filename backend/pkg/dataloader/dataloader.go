@@ -0,0 +1,100 @@
+// Package dataloader batches lookups made while resolving one request, so
+// that resolving a field for N parents costs one query instead of N.
+package dataloader
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchFunc loads values for several keys at once. Keys missing from the
+// returned map resolve to the zero value.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects keys until one of the returned thunks is called, then
+// fetches all pending keys in a single batch. Results are cached for the
+// lifetime of the loader, which should be one request.
+type Loader[K comparable, V any] struct {
+	batch BatchFunc[K, V]
+
+	mu      sync.Mutex
+	pending []K
+	cache   map[K]*result[V]
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func New[K comparable, V any](batch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		batch: batch,
+		cache: make(map[K]*result[V]),
+	}
+}
+
+// Load queues key and returns a thunk resolving it.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	res, ok := l.cache[key]
+	if !ok {
+		res = &result[V]{done: make(chan struct{})}
+		l.cache[key] = res
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.dispatch(ctx)
+		<-res.done
+		return res.value, res.err
+	}
+}
+
+// Prime stores a value that was loaded by other means, unless the key is
+// already known.
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.cache[key]; ok {
+		return
+	}
+	res := &result[V]{done: make(chan struct{}), value: value}
+	close(res.done)
+	l.cache[key] = res
+}
+
+// dispatch fetches every pending key; it is a no-op when a previous thunk
+// already did so.
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	l.mu.Lock()
+	keys := l.pending
+	l.pending = nil
+	results := make([]*result[V], len(keys))
+	for i, key := range keys {
+		results[i] = l.cache[key]
+	}
+	l.mu.Unlock()
+
+	if len(keys) == 0 {
+		return
+	}
+
+	values, err := l.batch(ctx, keys)
+	for i, key := range keys {
+		results[i].value = values[key]
+		results[i].err = err
+		close(results[i].done)
+	}
+
+	if err != nil {
+		// Do not cache failures; a later load may succeed.
+		l.mu.Lock()
+		for _, key := range keys {
+			delete(l.cache, key)
+		}
+		l.mu.Unlock()
+	}
+}
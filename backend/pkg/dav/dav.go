@@ -0,0 +1,246 @@
+package dav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// XML namespaces used by WebDAV and its calendaring extensions.
+const (
+	NSDAV            = "DAV:"
+	NSCalDAV         = "urn:ietf:params:xml:ns:caldav"
+	NSCalendarServer = "http://calendarserver.org/ns/"
+)
+
+var prefixes = map[string]string{
+	NSDAV:            "d",
+	NSCalDAV:         "c",
+	NSCalendarServer: "cs",
+}
+
+// Node is a generic XML element used to inspect request bodies without
+// declaring a struct for every WebDAV method.
+type Node struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []*Node    `xml:",any"`
+	Content  string     `xml:",chardata"`
+}
+
+// Child returns the first direct child with the given name.
+func (n *Node) Child(space, local string) *Node {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Children {
+		if c.XMLName.Space == space && c.XMLName.Local == local {
+			return c
+		}
+	}
+	return nil
+}
+
+// ChildrenNamed returns all direct children with the given name.
+func (n *Node) ChildrenNamed(space, local string) []*Node {
+	if n == nil {
+		return nil
+	}
+	var found []*Node
+	for _, c := range n.Children {
+		if c.XMLName.Space == space && c.XMLName.Local == local {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// Text returns the trimmed character data of the node, or "" for nil.
+func (n *Node) Text() string {
+	if n == nil {
+		return ""
+	}
+	return strings.TrimSpace(n.Content)
+}
+
+// Attr returns the value of an unqualified attribute.
+func (n *Node) Attr(local string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Decode parses a request body. An empty body yields a nil node.
+func Decode(r io.Reader) (*Node, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	var n Node
+	if err := xml.Unmarshal(data, &n); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// PropRequest describes which properties a PROPFIND or REPORT asks for.
+type PropRequest struct {
+	AllProp bool
+	Names   []xml.Name
+}
+
+// ParsePropRequest reads the allprop/prop children of a PROPFIND or REPORT
+// element. A missing element means allprop per RFC 4918.
+func ParsePropRequest(root *Node) PropRequest {
+	if root == nil || root.Child(NSDAV, "allprop") != nil {
+		return PropRequest{AllProp: true}
+	}
+	prop := root.Child(NSDAV, "prop")
+	if prop == nil {
+		return PropRequest{AllProp: true}
+	}
+	req := PropRequest{}
+	for _, c := range prop.Children {
+		req.Names = append(req.Names, c.XMLName)
+	}
+	return req
+}
+
+// Response is one <d:response> of a multistatus body.
+type Response struct {
+	Href string
+	// Status is set for responses without properties, e.g. deleted members
+	// in a sync-collection report.
+	Status int
+	Props  map[xml.Name]string
+	// Missing lists requested properties that the resource does not have.
+	Missing []xml.Name
+}
+
+// NewResponse resolves a property request against the properties a resource
+// can provide. Values are pre-rendered inner XML.
+func NewResponse(href string, req PropRequest, available map[xml.Name]string) Response {
+	resp := Response{Href: href, Props: map[xml.Name]string{}}
+	if req.AllProp {
+		for name, value := range available {
+			resp.Props[name] = value
+		}
+		return resp
+	}
+	for _, name := range req.Names {
+		if value, ok := available[name]; ok {
+			resp.Props[name] = value
+		} else {
+			resp.Missing = append(resp.Missing, name)
+		}
+	}
+	return resp
+}
+
+// Multistatus is a 207 response body.
+type Multistatus struct {
+	Responses []Response
+	SyncToken string
+}
+
+// WriteTo renders the multistatus document as a 207 response.
+func (m *Multistatus) WriteTo(w http.ResponseWriter) {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/">`)
+	for _, r := range m.Responses {
+		b.WriteString("<d:response><d:href>")
+		b.WriteString(Escape(r.Href))
+		b.WriteString("</d:href>")
+		if r.Status != 0 {
+			writeStatus(&b, r.Status)
+		}
+		if len(r.Props) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for name, value := range r.Props {
+				writeElement(&b, name, value)
+			}
+			b.WriteString("</d:prop>")
+			writeStatus(&b, http.StatusOK)
+			b.WriteString("</d:propstat>")
+		}
+		if len(r.Missing) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, name := range r.Missing {
+				writeElement(&b, name, "")
+			}
+			b.WriteString("</d:prop>")
+			writeStatus(&b, http.StatusNotFound)
+			b.WriteString("</d:propstat>")
+		}
+		b.WriteString("</d:response>")
+	}
+	if m.SyncToken != "" {
+		b.WriteString("<d:sync-token>" + Escape(m.SyncToken) + "</d:sync-token>")
+	}
+	b.WriteString("</d:multistatus>")
+
+	w.Header().Set("Content-Type", `application/xml; charset=utf-8`)
+	w.WriteHeader(http.StatusMultiStatus)
+	io.WriteString(w, b.String())
+}
+
+func writeStatus(b *strings.Builder, code int) {
+	fmt.Fprintf(b, "<d:status>HTTP/1.1 %d %s</d:status>", code, http.StatusText(code))
+}
+
+func writeElement(b *strings.Builder, name xml.Name, inner string) {
+	tag := name.Local
+	nsAttr := ""
+	if prefix, ok := prefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag = "x:" + name.Local
+		nsAttr = ` xmlns:x="` + Escape(name.Space) + `"`
+	}
+	if inner == "" {
+		b.WriteString("<" + tag + nsAttr + "/>")
+		return
+	}
+	b.WriteString("<" + tag + nsAttr + ">" + inner + "</" + tag + ">")
+}
+
+// Escape returns s with XML special characters escaped.
+func Escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// Href renders an <d:href> element, as used by several property values.
+func Href(href string) string {
+	return "<d:href>" + Escape(href) + "</d:href>"
+}
+
+// WriteError renders a DAV:error body carrying a precondition element.
+func WriteError(w http.ResponseWriter, status int, space, local string) {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<d:error xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">`)
+	writeElement(&b, xml.Name{Space: space, Local: local}, "")
+	b.WriteString("</d:error>")
+
+	w.Header().Set("Content-Type", `application/xml; charset=utf-8`)
+	w.WriteHeader(status)
+	io.WriteString(w, b.String())
+}
+
+// Name is a shorthand for building property names.
+func Name(space, local string) xml.Name {
+	return xml.Name{Space: space, Local: local}
+}
@@ -0,0 +1,333 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateTimeUTCFormat = "20060102T150405Z"
+	dateTimeFormat    = "20060102T150405"
+	dateFormat        = "20060102"
+
+	// maxLineOctets is the folding limit from RFC 5545 section 3.1.
+	maxLineOctets = 75
+)
+
+var ErrMalformed = errors.New("malformed iCalendar data")
+
+// Property is a single content line such as "DUE;VALUE=DATE:20240131".
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Component is a BEGIN/END block, e.g. VCALENDAR, VTODO or VEVENT.
+type Component struct {
+	Name       string
+	Properties []Property
+	Children   []*Component
+}
+
+func NewComponent(name string) *Component {
+	return &Component{Name: name}
+}
+
+// Add appends a property with a raw (already escaped) value.
+func (c *Component) Add(name, value string) {
+	c.Properties = append(c.Properties, Property{Name: name, Value: value})
+}
+
+// AddText appends a TEXT property, escaping the value.
+func (c *Component) AddText(name, value string) {
+	c.Add(name, EscapeText(value))
+}
+
+// AddTime appends a DATE-TIME property in UTC form.
+func (c *Component) AddTime(name string, t time.Time) {
+	c.Add(name, t.UTC().Format(dateTimeUTCFormat))
+}
+
+// AddList appends a multi-valued TEXT property such as CATEGORIES.
+func (c *Component) AddList(name string, values []string) {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = EscapeText(v)
+	}
+	c.Add(name, strings.Join(escaped, ","))
+}
+
+func (c *Component) AddChild(child *Component) {
+	c.Children = append(c.Children, child)
+}
+
+// Get returns the first property with the given name.
+func (c *Component) Get(name string) *Property {
+	for i := range c.Properties {
+		if c.Properties[i].Name == name {
+			return &c.Properties[i]
+		}
+	}
+	return nil
+}
+
+// Text returns the unescaped value of the first property with the given name.
+func (c *Component) Text(name string) (string, bool) {
+	p := c.Get(name)
+	if p == nil {
+		return "", false
+	}
+	return UnescapeText(p.Value), true
+}
+
+// Time parses the first property with the given name as DATE or DATE-TIME.
+func (c *Component) Time(name string) (*time.Time, error) {
+	p := c.Get(name)
+	if p == nil {
+		return nil, nil
+	}
+	t, err := p.Time()
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// List collects the values of every property with the given name, splitting
+// multi-valued properties on unescaped commas.
+func (c *Component) List(name string) []string {
+	var values []string
+	for _, p := range c.Properties {
+		if p.Name != name {
+			continue
+		}
+		for _, v := range splitList(p.Value) {
+			if v = strings.TrimSpace(UnescapeText(v)); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// Find returns every direct child with the given component name.
+func (c *Component) Find(name string) []*Component {
+	var found []*Component
+	for _, child := range c.Children {
+		if child.Name == name {
+			found = append(found, child)
+		}
+	}
+	return found
+}
+
+// Time parses the property value honouring VALUE=DATE and TZID parameters.
+func (p *Property) Time() (time.Time, error) {
+	value := strings.TrimSpace(p.Value)
+
+	if p.Params["VALUE"] == "DATE" || len(value) == len(dateFormat) {
+		return time.ParseInLocation(dateFormat, value, time.UTC)
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(dateTimeUTCFormat, value)
+	}
+
+	loc := time.UTC
+	if tzid := p.Params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(strings.Trim(tzid, `"`)); err == nil {
+			loc = l
+		}
+	}
+	return time.ParseInLocation(dateTimeFormat, value, loc)
+}
+
+// Encode writes the component tree using CRLF line endings and folding.
+func Encode(w io.Writer, c *Component) error {
+	bw := bufio.NewWriter(w)
+	encodeComponent(bw, c)
+	return bw.Flush()
+}
+
+func encodeComponent(w *bufio.Writer, c *Component) {
+	writeLine(w, "BEGIN:"+c.Name)
+	for _, p := range c.Properties {
+		var b strings.Builder
+		b.WriteString(p.Name)
+		for k, v := range p.Params {
+			b.WriteString(";" + k + "=" + v)
+		}
+		b.WriteString(":" + p.Value)
+		writeLine(w, b.String())
+	}
+	for _, child := range c.Children {
+		encodeComponent(w, child)
+	}
+	writeLine(w, "END:"+c.Name)
+}
+
+// writeLine folds lines longer than 75 octets without splitting UTF-8
+// sequences.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines lose one octet to the leading space.
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// Decode parses a stream into its top-level components (normally a single
+// VCALENDAR).
+func Decode(r io.Reader) ([]*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []*Component
+	var stack []*Component
+
+	for _, line := range lines {
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			c := NewComponent(strings.ToUpper(prop.Value))
+			if len(stack) > 0 {
+				stack[len(stack)-1].AddChild(c)
+			} else {
+				roots = append(roots, c)
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("%w: unexpected END:%s", ErrMalformed, prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("%w: property %s outside component", ErrMalformed, prop.Name)
+			}
+			top := stack[len(stack)-1]
+			top.Properties = append(top.Properties, prop)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("%w: missing END:%s", ErrMalformed, stack[len(stack)-1].Name)
+	}
+
+	return roots, nil
+}
+
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+func parseLine(line string) (Property, error) {
+	// The name and parameters end at the first colon outside a quoted
+	// parameter value.
+	inQuotes := false
+	colon := -1
+	for i, ch := range line {
+		if ch == '"' {
+			inQuotes = !inQuotes
+		} else if ch == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return Property{}, fmt.Errorf("%w: %q", ErrMalformed, line)
+	}
+
+	head := strings.Split(line[:colon], ";")
+	prop := Property{
+		Name:  strings.ToUpper(head[0]),
+		Value: line[colon+1:],
+	}
+	for _, param := range head[1:] {
+		k, v, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		if prop.Params == nil {
+			prop.Params = map[string]string{}
+		}
+		prop.Params[strings.ToUpper(k)] = v
+	}
+
+	return prop, nil
+}
+
+func splitList(value string) []string {
+	var parts []string
+	var b strings.Builder
+	escaped := false
+	for _, ch := range value {
+		switch {
+		case escaped:
+			b.WriteRune('\\')
+			b.WriteRune(ch)
+			escaped = false
+		case ch == '\\':
+			escaped = true
+		case ch == ',':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteRune(ch)
+		}
+	}
+	return append(parts, b.String())
+}
+
+var (
+	textEscaper   = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+func EscapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func UnescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
@@ -0,0 +1,58 @@
+package openapi
+
+import "strings"
+
+// FindOperation returns the operation for a request together with its path
+// parameters. Literal segments win over templated ones, so
+// /todos/export.ics is not mistaken for /todos/{id}.
+func (d *Document) FindOperation(method, path string) (*Operation, map[string]string) {
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+
+	var (
+		best       *Operation
+		bestParams map[string]string
+		bestScore  = -1
+	)
+	for template, item := range d.Paths {
+		op, ok := (*item)[strings.ToLower(method)]
+		if !ok {
+			continue
+		}
+		params, score, ok := matchPath(strings.Split(template, "/"), segments)
+		if ok && score > bestScore {
+			best, bestParams, bestScore = op, params, score
+		}
+	}
+	return best, bestParams
+}
+
+func matchPath(template, segments []string) (map[string]string, int, bool) {
+	if len(template) != len(segments) {
+		return nil, 0, false
+	}
+
+	params := map[string]string{}
+	literals := 0
+	for i, part := range template {
+		open, close := strings.Index(part, "{"), strings.Index(part, "}")
+		if open < 0 || close < open {
+			if part != segments[i] {
+				return nil, 0, false
+			}
+			literals++
+			continue
+		}
+
+		prefix, suffix := part[:open], part[close+1:]
+		value, ok := strings.CutPrefix(segments[i], prefix)
+		if !ok {
+			return nil, 0, false
+		}
+		value, ok = strings.CutSuffix(value, suffix)
+		if !ok || value == "" {
+			return nil, 0, false
+		}
+		params[part[open+1:close]] = value
+	}
+	return params, literals, true
+}
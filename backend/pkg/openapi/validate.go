@@ -0,0 +1,164 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Validate checks a value decoded by encoding/json into interface{} against
+// the schema. It returns one message per violation, prefixed with the
+// location of the offending value.
+func (d *Document) Validate(s *Schema, value interface{}, path string) []string {
+	if s == nil {
+		return nil
+	}
+
+	if name := s.RefName(); name != "" {
+		component, ok := d.Components.Schemas[name]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown schema %q", path, name)}
+		}
+		return d.Validate(component, value, path)
+	}
+
+	if len(s.AnyOf) > 0 {
+		var first []string
+		for _, option := range s.AnyOf {
+			errs := d.Validate(option, value, path)
+			if len(errs) == 0 {
+				return nil
+			}
+			if first == nil {
+				first = errs
+			}
+		}
+		return first
+	}
+
+	if value == nil {
+		if s.Type == "" || s.Type == "null" || s.Nullable {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: must not be null", path)}
+	}
+
+	var errs []string
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		errs = append(errs, fmt.Sprintf("%s: must be one of %v", path, s.Enum))
+	}
+
+	switch s.Type {
+	case "":
+		return errs
+	case "null":
+		return append(errs, fmt.Sprintf("%s: must be null", path))
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: must be a string", path))
+		}
+		errs = append(errs, checkString(s, str, path)...)
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: must be a %s", path, s.Type))
+		}
+		if s.Type == "integer" && n != math.Trunc(n) {
+			errs = append(errs, fmt.Sprintf("%s: must be an integer", path))
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			errs = append(errs, fmt.Sprintf("%s: must be at least %v", path, *s.Minimum))
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			errs = append(errs, fmt.Sprintf("%s: must be at most %v", path, *s.Maximum))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs = append(errs, fmt.Sprintf("%s: must be a boolean", path))
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: must be an array", path))
+		}
+		if s.MinItems != nil && len(items) < *s.MinItems {
+			errs = append(errs, fmt.Sprintf("%s: must have at least %d items", path, *s.MinItems))
+		}
+		if s.MaxItems != nil && len(items) > *s.MaxItems {
+			errs = append(errs, fmt.Sprintf("%s: must have at most %d items", path, *s.MaxItems))
+		}
+		for i, item := range items {
+			errs = append(errs, d.Validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: must be an object", path))
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				errs = append(errs, fmt.Sprintf("%s.%s: is required", path, name))
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			prop, ok := s.Properties[key]
+			if !ok {
+				// Unknown properties are ignored by the handlers.
+				prop = s.AdditionalProperties
+			}
+			errs = append(errs, d.Validate(prop, obj[key], path+"."+key)...)
+		}
+	}
+	return errs
+}
+
+func checkString(s *Schema, str, path string) []string {
+	var errs []string
+	length := utf8.RuneCountInString(str)
+	if s.MinLength != nil && length < *s.MinLength {
+		errs = append(errs, fmt.Sprintf("%s: must be at least %d characters", path, *s.MinLength))
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		errs = append(errs, fmt.Sprintf("%s: must be at most %d characters", path, *s.MaxLength))
+	}
+
+	var err error
+	switch s.Format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, str)
+	case "uuid":
+		_, err = uuid.Parse(str)
+	case "email":
+		_, err = mail.ParseAddress(str)
+	case "uri":
+		var u *url.URL
+		if u, err = url.ParseRequestURI(str); err == nil && u.Scheme == "" {
+			err = fmt.Errorf("missing scheme")
+		}
+	}
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("%s: must be a valid %s", path, s.Format))
+	}
+	return errs
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, v := range enum {
+		if fmt.Sprint(v) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
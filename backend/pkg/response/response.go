@@ -50,3 +50,16 @@ func ValidationError(w http.ResponseWriter, err error) {
 		Errors:  errors,
 	})
 }
+
+// ErrorWithDetails writes an error response that lists the individual
+// problems, like ValidationError does for validator errors.
+func ErrorWithDetails(w http.ResponseWriter, statusCode int, message string, details []string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	json.NewEncoder(w).Encode(Response{
+		Success: false,
+		Message: message,
+		Errors:  details,
+	})
+}